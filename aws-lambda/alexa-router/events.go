@@ -0,0 +1,82 @@
+package alexarouter
+
+type Event struct {
+	Version string  `json:"version"`
+	Session Session `json:"session"`
+	Context Context `json:"context"`
+	Request Request `json:"request"`
+}
+
+type Session struct {
+	New         bool                   `json:"new"`
+	SessionID   string                 `json:"sessionId"`
+	Application Application            `json:"application"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	User        User                   `json:"user"`
+}
+
+type Application struct {
+	ApplicationID string `json:"applicationId"`
+}
+
+type User struct {
+	UserID      string `json:"userId"`
+	AccessToken string `json:"accessToken,omitempty"`
+}
+
+type Context struct {
+	System struct {
+		Application    Application `json:"application"`
+		User           User        `json:"user"`
+		APIEndpoint    string      `json:"apiEndpoint"`
+		APIAccessToken string      `json:"apiAccessToken"`
+		Device         struct {
+			DeviceID            string                 `json:"deviceId"`
+			SupportedInterfaces map[string]interface{} `json:"supportedInterfaces"`
+		} `json:"device"`
+	} `json:"System"`
+}
+
+type Request struct {
+	Type        string `json:"type"`
+	RequestID   string `json:"requestId"`
+	Timestamp   string `json:"timestamp"`
+	Locale      string `json:"locale"`
+	DialogState string `json:"dialogState,omitempty"`
+	Intent      Intent `json:"intent,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	Error       *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type Intent struct {
+	Name               string          `json:"name"`
+	ConfirmationStatus string          `json:"confirmationStatus,omitempty"`
+	Slots              map[string]Slot `json:"slots,omitempty"`
+}
+
+type Slot struct {
+	Name               string                 `json:"name"`
+	Value              string                 `json:"value,omitempty"`
+	ConfirmationStatus string                 `json:"confirmationStatus,omitempty"`
+	Resolutions        map[string]interface{} `json:"resolutions,omitempty"`
+}
+
+// Slot returns the spoken value of an intent slot, or false when it is empty.
+func (e *Event) Slot(name string) (string, bool) {
+	slot, ok := e.Request.Intent.Slots[name]
+	if !ok || slot.Value == "" {
+		return "", false
+	}
+	return slot.Value, true
+}
+
+// ApplicationID returns the skill ID the request was sent to.
+func (e *Event) ApplicationID() string {
+	if id := e.Context.System.Application.ApplicationID; id != "" {
+		return id
+	}
+	return e.Session.Application.ApplicationID
+}
@@ -0,0 +1,106 @@
+package alexarouter
+
+type Response struct {
+	Version           string                 `json:"version"`
+	SessionAttributes map[string]interface{} `json:"sessionAttributes,omitempty"`
+	Response          ResponseBody           `json:"response"`
+}
+
+type ResponseBody struct {
+	OutputSpeech     *OutputSpeech `json:"outputSpeech,omitempty"`
+	Card             *Card         `json:"card,omitempty"`
+	Reprompt         *Reprompt     `json:"reprompt,omitempty"`
+	ShouldEndSession *bool         `json:"shouldEndSession,omitempty"`
+	Directives       []interface{} `json:"directives,omitempty"`
+}
+
+type OutputSpeech struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	SSML string `json:"ssml,omitempty"`
+}
+
+type Reprompt struct {
+	OutputSpeech *OutputSpeech `json:"outputSpeech"`
+}
+
+type Card struct {
+	Type    string     `json:"type"`
+	Title   string     `json:"title,omitempty"`
+	Content string     `json:"content,omitempty"`
+	Text    string     `json:"text,omitempty"`
+	Image   *CardImage `json:"image,omitempty"`
+}
+
+type CardImage struct {
+	SmallImageURL string `json:"smallImageUrl,omitempty"`
+	LargeImageURL string `json:"largeImageUrl,omitempty"`
+}
+
+type ResponseBuilder struct {
+	resp Response
+}
+
+func NewResponse() *ResponseBuilder {
+	return &ResponseBuilder{resp: Response{Version: "1.0"}}
+}
+
+func (b *ResponseBuilder) Speak(text string) *ResponseBuilder {
+	b.resp.Response.OutputSpeech = &OutputSpeech{Type: "PlainText", Text: text}
+	return b
+}
+
+func (b *ResponseBuilder) SpeakSSML(ssml string) *ResponseBuilder {
+	b.resp.Response.OutputSpeech = &OutputSpeech{Type: "SSML", SSML: ssml}
+	return b
+}
+
+// Reprompt sets the speech used when the user does not answer, and keeps the
+// session open.
+func (b *ResponseBuilder) Reprompt(text string) *ResponseBuilder {
+	b.resp.Response.Reprompt = &Reprompt{OutputSpeech: &OutputSpeech{Type: "PlainText", Text: text}}
+	return b.EndSession(false)
+}
+
+func (b *ResponseBuilder) SimpleCard(title, content string) *ResponseBuilder {
+	b.resp.Response.Card = &Card{Type: "Simple", Title: title, Content: content}
+	return b
+}
+
+func (b *ResponseBuilder) StandardCard(title, text, smallImageURL, largeImageURL string) *ResponseBuilder {
+	b.resp.Response.Card = &Card{
+		Type:  "Standard",
+		Title: title,
+		Text:  text,
+		Image: &CardImage{SmallImageURL: smallImageURL, LargeImageURL: largeImageURL},
+	}
+	return b
+}
+
+func (b *ResponseBuilder) LinkAccountCard() *ResponseBuilder {
+	b.resp.Response.Card = &Card{Type: "LinkAccount"}
+	return b
+}
+
+func (b *ResponseBuilder) Directive(directive interface{}) *ResponseBuilder {
+	b.resp.Response.Directives = append(b.resp.Response.Directives, directive)
+	return b
+}
+
+func (b *ResponseBuilder) SessionAttribute(key string, value interface{}) *ResponseBuilder {
+	if b.resp.SessionAttributes == nil {
+		b.resp.SessionAttributes = make(map[string]interface{})
+	}
+	b.resp.SessionAttributes[key] = value
+	return b
+}
+
+func (b *ResponseBuilder) EndSession(end bool) *ResponseBuilder {
+	b.resp.Response.ShouldEndSession = &end
+	return b
+}
+
+func (b *ResponseBuilder) Build() *Response {
+	resp := b.resp
+	return &resp
+}
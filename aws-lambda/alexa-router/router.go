@@ -0,0 +1,143 @@
+package alexarouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	LaunchRequest       = "LaunchRequest"
+	IntentRequest       = "IntentRequest"
+	SessionEndedRequest = "SessionEndedRequest"
+)
+
+var (
+	ErrNoRoute      = errors.New("alexarouter: no route for request")
+	ErrWrongSkillID = errors.New("alexarouter: request is for a different skill")
+)
+
+type Handler interface {
+	HandleRequest(context.Context, *Event) (*Response, error)
+}
+
+type HandlerFunc func(context.Context, *Event) (*Response, error)
+
+func (f HandlerFunc) HandleRequest(ctx context.Context, e *Event) (*Response, error) {
+	return f(ctx, e)
+}
+
+type MiddlewareFunc func(Handler) Handler
+
+type Router struct {
+	requests       map[string]Handler
+	intents        map[string]Handler
+	defaultHandler Handler
+	middleware     []MiddlewareFunc
+	skillID        string
+	logger         *log.Logger
+}
+
+func NewRouter(logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.New(os.Stdout, "ALEXAROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	return &Router{
+		requests: make(map[string]Handler),
+		intents:  make(map[string]Handler),
+		logger:   logger,
+	}
+}
+
+// SetSkillID rejects requests addressed to any other skill.
+func (r *Router) SetSkillID(id string) {
+	r.skillID = id
+}
+
+func (r *Router) Launch(handler Handler) {
+	r.requests[LaunchRequest] = handler
+}
+
+// Intent routes an IntentRequest by intent name, including built-ins such as
+// AMAZON.HelpIntent.
+func (r *Router) Intent(name string, handler Handler) {
+	r.intents[name] = handler
+}
+
+func (r *Router) SessionEnded(handler Handler) {
+	r.requests[SessionEndedRequest] = handler
+}
+
+// Request routes any other request type, e.g. CanFulfillIntentRequest.
+func (r *Router) Request(requestType string, handler Handler) {
+	r.requests[requestType] = handler
+}
+
+func (r *Router) Default(handler Handler) {
+	r.defaultHandler = handler
+}
+
+func (r *Router) Use(mw MiddlewareFunc) {
+	r.middleware = append(r.middleware, mw)
+}
+
+func (r *Router) LambdaHandler() func(context.Context, *Event) (*Response, error) {
+	return r.HandleEvent
+}
+
+func (r *Router) HandleEvent(ctx context.Context, e *Event) (resp *Response, err error) {
+	startTime := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+		r.logRequestCompletion(e, time.Since(startTime), err)
+	}()
+
+	if r.skillID != "" && e.ApplicationID() != r.skillID {
+		return nil, ErrWrongSkillID
+	}
+	handler := r.match(e)
+	if handler == nil {
+		return nil, ErrNoRoute
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	resp, err = handler.HandleRequest(ctx, e)
+	if err == nil && resp == nil {
+		// SessionEndedRequest handlers may return nothing, but Alexa still
+		// expects a well-formed response.
+		resp = NewResponse().Build()
+	}
+	return resp, err
+}
+
+func (r *Router) match(e *Event) Handler {
+	if e.Request.Type == IntentRequest {
+		if h, ok := r.intents[e.Request.Intent.Name]; ok {
+			return h
+		}
+	} else if h, ok := r.requests[e.Request.Type]; ok {
+		return h
+	}
+	return r.defaultHandler
+}
+
+func (r *Router) logRequestCompletion(e *Event, duration time.Duration, err error) {
+	logEntry := fmt.Sprintf(
+		"Request completed: type=%s intent=%s duration=%v",
+		e.Request.Type,
+		e.Request.Intent.Name,
+		duration,
+	)
+
+	if err != nil {
+		logEntry += fmt.Sprintf(" error=%v", err)
+	}
+
+	r.logger.Println(logEntry)
+}
@@ -0,0 +1,146 @@
+package ebrouter
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Pattern is an EventBridge event pattern. Leaf values are arrays of
+// alternatives, each either a literal or one of the content filters
+// prefix, suffix, anything-but, exists, numeric, wildcard and
+// equals-ignore-case.
+type Pattern map[string]interface{}
+
+func ParsePattern(data []byte) (Pattern, error) {
+	var p Pattern
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p Pattern) Matches(event map[string]interface{}) bool {
+	for key, want := range p {
+		value, present := event[key]
+		switch w := want.(type) {
+		case map[string]interface{}:
+			nested, ok := value.(map[string]interface{})
+			if !ok || !Pattern(w).Matches(nested) {
+				return false
+			}
+		case []interface{}:
+			if !matchAny(w, value, present) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func matchAny(alternatives []interface{}, value interface{}, present bool) bool {
+	// Array values in the event match if any element matches.
+	if values, ok := value.([]interface{}); ok {
+		for _, v := range values {
+			if matchAny(alternatives, v, true) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, alt := range alternatives {
+		if filter, ok := alt.(map[string]interface{}); ok {
+			if matchFilter(filter, value, present) {
+				return true
+			}
+			continue
+		}
+		if present && literalEqual(alt, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchFilter(filter map[string]interface{}, value interface{}, present bool) bool {
+	for op, arg := range filter {
+		switch op {
+		case "exists":
+			want, _ := arg.(bool)
+			return present == want
+		case "prefix":
+			s, ok := value.(string)
+			return present && ok && strings.HasPrefix(s, fmt.Sprint(arg))
+		case "suffix":
+			s, ok := value.(string)
+			return present && ok && strings.HasSuffix(s, fmt.Sprint(arg))
+		case "equals-ignore-case":
+			s, ok := value.(string)
+			return present && ok && strings.EqualFold(s, fmt.Sprint(arg))
+		case "wildcard":
+			s, ok := value.(string)
+			if !present || !ok {
+				return false
+			}
+			matched, err := path.Match(fmt.Sprint(arg), s)
+			return err == nil && matched
+		case "anything-but":
+			if !present {
+				return false
+			}
+			switch a := arg.(type) {
+			case []interface{}:
+				return !matchAny(a, value, true)
+			case map[string]interface{}:
+				return !matchFilter(a, value, true)
+			default:
+				return !literalEqual(a, value)
+			}
+		case "numeric":
+			n, ok := value.(float64)
+			conditions, _ := arg.([]interface{})
+			return present && ok && matchNumeric(conditions, n)
+		}
+	}
+	return false
+}
+
+func matchNumeric(conditions []interface{}, n float64) bool {
+	if len(conditions)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(conditions); i += 2 {
+		op, _ := conditions[i].(string)
+		bound, ok := conditions[i+1].(float64)
+		if !ok {
+			return false
+		}
+		var pass bool
+		switch op {
+		case "=":
+			pass = n == bound
+		case "<":
+			pass = n < bound
+		case "<=":
+			pass = n <= bound
+		case ">":
+			pass = n > bound
+		case ">=":
+			pass = n >= bound
+		}
+		if !pass {
+			return false
+		}
+	}
+	return true
+}
+
+func literalEqual(want, value interface{}) bool {
+	if want == nil {
+		return value == nil
+	}
+	return want == value
+}
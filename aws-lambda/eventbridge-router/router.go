@@ -0,0 +1,159 @@
+package ebrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var ErrNoRoute = errors.New("ebrouter: no route for event")
+
+type Handler interface {
+	HandleEvent(context.Context, events.EventBridgeEvent) error
+}
+
+type HandlerFunc func(context.Context, events.EventBridgeEvent) error
+
+func (f HandlerFunc) HandleEvent(ctx context.Context, event events.EventBridgeEvent) error {
+	return f(ctx, event)
+}
+
+type MiddlewareFunc func(Handler) Handler
+
+type route struct {
+	pattern Pattern
+	handler Handler
+}
+
+type Router struct {
+	routes         []route
+	defaultHandler Handler
+	middleware     []MiddlewareFunc
+	logger         *log.Logger
+}
+
+func NewRouter(logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.New(os.Stdout, "EBROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	return &Router{logger: logger}
+}
+
+// On routes events by exact source and detail-type. An empty detailType
+// matches every event from source.
+func (r *Router) On(source, detailType string, handler Handler) {
+	pattern := Pattern{"source": []interface{}{source}}
+	if detailType != "" {
+		pattern["detail-type"] = []interface{}{detailType}
+	}
+	r.Match(pattern, handler)
+}
+
+// Match routes events matching an EventBridge-style pattern. Routes are
+// tried in registration order.
+func (r *Router) Match(pattern Pattern, handler Handler) {
+	r.routes = append(r.routes, route{pattern: pattern, handler: handler})
+}
+
+// MatchJSON is Match with the pattern given in EventBridge rule JSON syntax.
+func (r *Router) MatchJSON(pattern string, handler Handler) error {
+	p, err := ParsePattern([]byte(pattern))
+	if err != nil {
+		return err
+	}
+	r.Match(p, handler)
+	return nil
+}
+
+func (r *Router) Default(handler Handler) {
+	r.defaultHandler = handler
+}
+
+func (r *Router) Use(mw MiddlewareFunc) {
+	r.middleware = append(r.middleware, mw)
+}
+
+func (r *Router) LambdaHandler() func(context.Context, events.EventBridgeEvent) error {
+	return r.HandleEvent
+}
+
+func (r *Router) HandleEvent(ctx context.Context, event events.EventBridgeEvent) (err error) {
+	startTime := time.Now()
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+		r.logEventCompletion(event, time.Since(startTime), err)
+	}()
+
+	handler, err := r.match(event)
+	if err != nil {
+		return err
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	return handler.HandleEvent(ctx, event)
+}
+
+func (r *Router) match(event events.EventBridgeEvent) (Handler, error) {
+	if len(r.routes) > 0 {
+		raw, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		var doc map[string]interface{}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, err
+		}
+		for _, route := range r.routes {
+			if route.pattern.Matches(doc) {
+				return route.handler, nil
+			}
+		}
+	}
+	if r.defaultHandler == nil {
+		return nil, ErrNoRoute
+	}
+	return r.defaultHandler, nil
+}
+
+func (r *Router) logEventCompletion(event events.EventBridgeEvent, duration time.Duration, err error) {
+	logEntry := fmt.Sprintf(
+		"Event completed: id=%s source=%s detail-type=%q duration=%v",
+		event.ID,
+		event.Source,
+		event.DetailType,
+		duration,
+	)
+
+	if err != nil {
+		logEntry += fmt.Sprintf(" error=%v", err)
+	}
+
+	r.logger.Println(logEntry)
+}
+
+// Detail unmarshals the event detail into T.
+func Detail[T any](event events.EventBridgeEvent) (T, error) {
+	var detail T
+	err := json.Unmarshal(event.Detail, &detail)
+	return detail, err
+}
+
+// Typed adapts a handler that takes the decoded detail.
+func Typed[T any](fn func(context.Context, events.EventBridgeEvent, T) error) Handler {
+	return HandlerFunc(func(ctx context.Context, event events.EventBridgeEvent) error {
+		detail, err := Detail[T](event)
+		if err != nil {
+			return fmt.Errorf("ebrouter: decoding %s detail: %w", event.DetailType, err)
+		}
+		return fn(ctx, event, detail)
+	})
+}
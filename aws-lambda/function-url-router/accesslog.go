@@ -0,0 +1,215 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type AccessLogFormat int
+
+const (
+	// AccessLogDefault logs completions through the router's Logger, or as a
+	// text line on the router's log.Logger when none is set.
+	AccessLogDefault AccessLogFormat = iota
+	// AccessLogJSON writes one AccessLogEntry as a JSON object per line.
+	AccessLogJSON
+	// AccessLogCommon writes NCSA Common Log Format lines.
+	AccessLogCommon
+	// AccessLogOff disables completion logging.
+	AccessLogOff
+)
+
+type AccessLogConfig struct {
+	Format AccessLogFormat
+	// Template, when set, is executed with an AccessLogEntry for every
+	// request and takes precedence over Format.
+	Template *template.Template
+	// Writer receives JSON, Common and Template lines. Defaults to os.Stdout.
+	Writer io.Writer
+	// Skip disables the access log for request paths matching one of these
+	// path.Match patterns.
+	Skip []string
+	// HealthChecks logs requests to the EnableHealthChecks endpoints,
+	// which are skipped by default.
+	HealthChecks bool
+	// NumericDurations writes the duration and remaining time of Default
+	// lines as duration_ms and remaining_ms numbers, like structured logs,
+	// instead of formatting them as time.Duration strings, which saves
+	// the formatting cost and a possible allocation per duration.
+	NumericDurations bool
+}
+
+// AccessLogEntry is what JSON lines contain and templates are executed
+// with. Route is the registered path that handled the request, empty when
+// none matched. Bytes is -1 for streamed bodies.
+type AccessLogEntry struct {
+	Time       time.Time     `json:"time"`
+	Level      string        `json:"level"`
+	Method     string        `json:"method"`
+	Path       string        `json:"path"`
+	Query      string        `json:"query,omitempty"`
+	Route      string        `json:"route,omitempty"`
+	Protocol   string        `json:"protocol,omitempty"`
+	Status     int           `json:"status"`
+	Bytes      int           `json:"bytes"`
+	Duration   time.Duration `json:"-"`
+	DurationMS float64       `json:"duration_ms"`
+	UserAgent  string        `json:"user_agent,omitempty"`
+	SourceIP   string        `json:"source_ip,omitempty"`
+	RequestID  string        `json:"request_id,omitempty"`
+	TraceID    string        `json:"trace_id,omitempty"`
+	// Lambda context, set when running in Lambda. RemainingMS is the time
+	// left before the function times out when the request completed.
+	RemainingMS     float64 `json:"remaining_ms,omitempty"`
+	FunctionVersion string  `json:"function_version,omitempty"`
+	MemoryLimitMB   int     `json:"memory_limit_mb,omitempty"`
+	LogStream       string  `json:"log_stream,omitempty"`
+	ColdStart       bool    `json:"cold_start"`
+	// InitDurationMS is set on cold starts.
+	InitDurationMS float64 `json:"init_duration_ms,omitempty"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// SetAccessLog changes how request completions are logged. For example,
+//
+//	r.SetAccessLog(router.AccessLogConfig{
+//		Template: template.Must(template.New("").Parse(`{{.Method}} {{.Route}} {{.Status}} {{.Bytes}} {{.UserAgent}}`)),
+//		Skip:     []string{"/health"},
+//	})
+func (r *Router) SetAccessLog(config AccessLogConfig) {
+	if config.Writer == nil {
+		config.Writer = os.Stdout
+	}
+	r.accessLog = &config
+}
+
+func (c *AccessLogConfig) skip(p string) bool {
+	if c.Format == AccessLogOff && c.Template == nil {
+		return true
+	}
+	return matchesAny(c.Skip, p)
+}
+
+func (r *Router) accessLogEntry(ctx context.Context, req events.LambdaFunctionURLRequest, resp Response, duration time.Duration, level slog.Level, requestID string, coldStart bool, err error) AccessLogEntry {
+	entry := AccessLogEntry{
+		Time:       time.Now().Add(-duration),
+		Level:      level.String(),
+		Method:     req.RequestContext.HTTP.Method,
+		Path:       req.RequestContext.HTTP.Path,
+		Query:      req.RawQueryString,
+		Protocol:   req.RequestContext.HTTP.Protocol,
+		Status:     resp.StatusCode,
+		Bytes:      r.responseSize(resp),
+		Duration:   duration,
+		DurationMS: float64(duration) / float64(time.Millisecond),
+		UserAgent:  req.RequestContext.HTTP.UserAgent,
+		SourceIP:   req.RequestContext.HTTP.SourceIP,
+		RequestID:  requestID,
+		ColdStart:  coldStart,
+	}
+	if coldStart {
+		entry.InitDurationMS = float64(InitDuration()) / float64(time.Millisecond)
+	}
+	if trace, ok := Trace(ctx); ok {
+		entry.TraceID = trace.TraceID
+	}
+	diag := Diagnostics(ctx)
+	entry.RemainingMS = float64(diag.Remaining) / float64(time.Millisecond)
+	entry.FunctionVersion = diag.FunctionVersion
+	entry.MemoryLimitMB = diag.MemoryLimitMB
+	entry.LogStream = diag.LogStream
+	if r.redaction != nil {
+		entry.Query = r.redaction.redactQuery(entry.Query)
+	}
+	if r.hasHandler(entry.Method, entry.Path) {
+		entry.Route = entry.Path
+		if r.stripTrailingSlash {
+			entry.Route = strings.TrimRight(entry.Route, "/")
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+func (r *Router) responseSize(resp Response) int {
+	switch body := resp.Body.(type) {
+	case nil:
+		return 0
+	case io.Reader:
+		return -1
+	case string:
+		if resp.IsBase64Encoded {
+			return base64.StdEncoding.DecodedLen(len(body)) - strings.Count(body, "=")
+		}
+		return len(body)
+	case []byte:
+		return len(body)
+	}
+	// encodeForLog leaves only bodies that failed to encode structured.
+	return 0
+}
+
+// encodeForLog replaces a structured body with its encoding, so the entry
+// records the size actually sent and the router writes these bytes rather
+// than marshalling the body a second time.
+func (r *Router) encodeForLog(resp *Response) {
+	switch resp.Body.(type) {
+	case nil, string, []byte, io.Reader:
+		return
+	}
+	if body, err := r.outputCodec().Marshal(resp.Body); err == nil {
+		*resp = encodedResponse(*resp, body)
+	}
+}
+
+func (r *Router) writeAccessLog(ctx context.Context, entry AccessLogEntry) {
+	config := r.accessLog
+	buf := getBuffer()
+	defer putBuffer(buf)
+	switch {
+	case config.Template != nil:
+		if err := config.Template.Execute(buf, entry); err != nil {
+			r.logError(ctx, "Error executing access log template", err)
+			return
+		}
+		buf.WriteByte('\n')
+	case config.Format == AccessLogJSON:
+		if err := json.NewEncoder(buf).Encode(entry); err != nil {
+			r.logError(ctx, "Error encoding access log entry", err)
+			return
+		}
+	case config.Format == AccessLogCommon:
+		writeCommonLog(buf, entry)
+	}
+	config.Writer.Write(buf.Bytes())
+}
+
+func writeCommonLog(buf *bytes.Buffer, entry AccessLogEntry) {
+	target := entry.Path
+	if entry.Query != "" {
+		target += "?" + entry.Query
+	}
+	size := "-"
+	if entry.Bytes >= 0 {
+		size = fmt.Sprint(entry.Bytes)
+	}
+	fmt.Fprintf(buf, "%s - - [%s] \"%s %s %s\" %d %s\n",
+		orDefault(entry.SourceIP, "-"),
+		entry.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		entry.Method, target, orDefault(entry.Protocol, "-"),
+		entry.Status, size,
+	)
+}
@@ -0,0 +1,39 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// TestAccessLogBytesEncodesOnce checks that a structured body is marshalled
+// once for both the access log's Bytes and the response.
+func TestAccessLogBytesEncodesOnce(t *testing.T) {
+	var marshals int
+	var logs bytes.Buffer
+	r := newTestRouter()
+	r.SetJSONCodec(countingCodec{marshals: &marshals})
+	r.SetAccessLog(AccessLogConfig{Format: AccessLogJSON, Writer: &logs})
+	r.AddRoute(http.MethodGet, "/json", HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		return Response{StatusCode: http.StatusOK, Body: map[string]string{"a": "b"}}
+	}))
+
+	resp, _ := r.LambdaHandler()(context.Background(), benchRequest(http.MethodGet, "/json"))
+	if marshals != 1 {
+		t.Errorf("body marshalled %d times, want 1", marshals)
+	}
+	if resp.Body != `{"a":"b"}` || resp.Headers["Content-Type"] != "application/json" {
+		t.Errorf("response = %v %q", resp.Headers, resp.Body)
+	}
+	var entry AccessLogEntry
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Bytes != len(resp.Body) {
+		t.Errorf("Bytes = %d, want %d", entry.Bytes, len(resp.Body))
+	}
+}
@@ -1,20 +1,40 @@
 package router
 
 import (
-	"encoding/json"
+	"context"
+	"encoding/base64"
+	"errors"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aws/aws-lambda-go/events"
 )
 
 type LambdaAdapter struct {
-	router *Router
-	logger *log.Logger
+	router         *Router
+	logger         *log.Logger
+	requestContext LocalRequestContext
+	fallback       Handler
+}
+
+// LocalRequestContext is the request context the adapter reports for every
+// local request. Empty fields keep the built-in dummy values; an empty
+// SourceIP uses the connection's remote address.
+type LocalRequestContext struct {
+	AccountID    string
+	APIID        string
+	DomainName   string
+	DomainPrefix string
+	SourceIP     string
+	IAM          *events.LambdaFunctionURLRequestContextAuthorizerIAMDescription
+	// Claims is returned by Authorizer(ctx), standing in for the context of
+	// an API Gateway authorizer.
+	Claims map[string]interface{}
 }
 
 func NewLambdaAdapter(router *Router, logger *log.Logger) *LambdaAdapter {
@@ -24,21 +44,78 @@ func NewLambdaAdapter(router *Router, logger *log.Logger) *LambdaAdapter {
 	return &LambdaAdapter{router: router, logger: logger}
 }
 
-func (la *LambdaAdapter) ServeHTTP(r *http.Request) Response {
-	lambdaReq := la.httpToLambdaRequest(r)
-	return la.router.HandleRequest(r.Context(), lambdaReq)
+func (la *LambdaAdapter) SetRequestContext(rc LocalRequestContext) {
+	la.requestContext = rc
 }
 
-func (la *LambdaAdapter) httpToLambdaRequest(r *http.Request) events.LambdaFunctionURLRequest {
-	body, err := io.ReadAll(r.Body)
+// SetFallback sets the handler for requests with no matching route and
+// method. It is called outside the router's middleware chain.
+func (la *LambdaAdapter) SetFallback(h Handler) {
+	la.fallback = h
+}
+
+func (la *LambdaAdapter) ServeHTTP(r *http.Request) Response {
+	ctx := r.Context()
+	body, spilled, err := readBody(r, la.router.bodyConfig)
+	if errors.Is(err, errBodyTooLarge) {
+		return Response{
+			StatusCode: http.StatusRequestEntityTooLarge,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       map[string]string{"error": "Request Entity Too Large"},
+		}
+	}
 	if err != nil {
-		la.logger.Printf("Error reading request body: %v", err)
+		la.logError(ctx, "Error reading request body", err)
+	}
+	if spilled != "" {
+		defer os.Remove(spilled)
+		ctx = context.WithValue(ctx, spilledBodyKey, spilled)
+	}
+	lambdaReq := functionURLRequest(r, body, la.requestContext)
+	if la.requestContext.Claims != nil {
+		ctx = context.WithValue(ctx, localAuthorizerKey, AuthorizerContext(la.requestContext.Claims))
+	}
+	if la.fallback != nil && !la.router.hasHandler(lambdaReq.RequestContext.HTTP.Method, lambdaReq.RequestContext.HTTP.Path) {
+		return la.fallback.ServeHTTP(ctx, lambdaReq)
 	}
-	defer r.Body.Close()
+	return la.router.HandleRequest(ctx, lambdaReq)
+}
+
+// NewFunctionURLRequest converts r into the event a Function URL would
+// deliver for it, the inverse of NewHTTPRequest.
+func NewFunctionURLRequest(r *http.Request) (events.LambdaFunctionURLRequest, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return events.LambdaFunctionURLRequest{}, err
+		}
+		r.Body.Close()
+	}
+	return functionURLRequest(r, body, LocalRequestContext{}), nil
+}
 
+func functionURLRequest(r *http.Request, body []byte, rc LocalRequestContext) events.LambdaFunctionURLRequest {
+	// Function URLs join repeated headers with commas and move the Cookie
+	// header into Cookies, one entry per cookie.
 	headers := make(map[string]string)
+	cookies := make([]string, 0)
 	for k, v := range r.Header {
-		headers[strings.ToLower(k)] = strings.Join(v, ",")
+		name := strings.ToLower(k)
+		if name == "cookie" {
+			for _, line := range v {
+				for _, cookie := range strings.Split(line, ";") {
+					if cookie = strings.TrimSpace(cookie); cookie != "" {
+						cookies = append(cookies, cookie)
+					}
+				}
+			}
+			continue
+		}
+		headers[name] = strings.Join(v, ",")
+	}
+	if r.Host != "" {
+		headers["host"] = r.Host
 	}
 
 	queryParams := make(map[string]string)
@@ -46,12 +123,11 @@ func (la *LambdaAdapter) httpToLambdaRequest(r *http.Request) events.LambdaFunct
 		queryParams[k] = strings.Join(v, ",")
 	}
 
-	cookies := make([]string, 0)
-	for _, cookie := range r.Cookies() {
-		cookies = append(cookies, cookie.String())
-	}
-
 	now := time.Now()
+	var authorizer *events.LambdaFunctionURLRequestContextAuthorizerDescription
+	if rc.IAM != nil {
+		authorizer = &events.LambdaFunctionURLRequestContextAuthorizerDescription{IAM: rc.IAM}
+	}
 
 	return events.LambdaFunctionURLRequest{
 		Version:               "2.0",
@@ -61,41 +137,111 @@ func (la *LambdaAdapter) httpToLambdaRequest(r *http.Request) events.LambdaFunct
 		Headers:               headers,
 		QueryStringParameters: queryParams,
 		RequestContext: events.LambdaFunctionURLRequestContext{
-			AccountID:    "123456789012",
+			AccountID:    orDefault(rc.AccountID, "123456789012"),
 			RequestID:    "dummy-request-id",
-			Authorizer:   nil,
-			APIID:        "dummy-api-id",
-			DomainName:   "dummy.lambda-url.us-east-1.on.aws",
-			DomainPrefix: "dummy",
+			Authorizer:   authorizer,
+			APIID:        orDefault(rc.APIID, "dummy-api-id"),
+			DomainName:   orDefault(rc.DomainName, "dummy.lambda-url.us-east-1.on.aws"),
+			DomainPrefix: orDefault(rc.DomainPrefix, "dummy"),
 			Time:         now.Format(time.RFC3339),
 			TimeEpoch:    now.UnixNano() / int64(time.Millisecond),
 			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
 				Method:    r.Method,
 				Path:      r.URL.Path,
 				Protocol:  r.Proto,
-				SourceIP:  r.RemoteAddr,
+				SourceIP:  orDefault(rc.SourceIP, r.RemoteAddr),
 				UserAgent: r.UserAgent(),
 			},
 		},
-		Body:            string(body),
-		IsBase64Encoded: false,
+		Body:            requestBodyString(body, r.Header),
+		IsBase64Encoded: isBinaryBody(body, r.Header),
 	}
 }
 
-func RunLocalServer(router *Router, addr string, logger *log.Logger) error {
-	if logger == nil {
-		logger = log.New(os.Stdout, "SERVER: ", log.Ldate|log.Ltime|log.Lshortfile)
+// isBinaryBody mirrors how Function URLs decide to base64-encode a request
+// body: anything compressed, with a non-text content type or that is not
+// valid UTF-8 is delivered encoded.
+func isBinaryBody(body []byte, header http.Header) bool {
+	if len(body) == 0 {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" || !utf8.Valid(body) {
+		return true
+	}
+	contentType := header.Get("Content-Type")
+	return contentType != "" && !isTextContentType(contentType)
+}
+
+func requestBodyString(body []byte, header http.Header) string {
+	if isBinaryBody(body, header) {
+		return base64.StdEncoding.EncodeToString(body)
+	}
+	return string(body)
+}
+
+// HTTPHandler exposes the router as an http.Handler so the same route table
+// can be mounted in an existing net/http server or run in a container.
+func (r *Router) HTTPHandler() http.Handler {
+	return newHTTPHandler(NewLambdaAdapter(r, r.logger))
+}
+
+// logError uses the router's Logger when one is set, so local request
+// errors end up next to the application's own logs.
+func (la *LambdaAdapter) logError(ctx context.Context, msg string, err error) {
+	if la.router.slogger != nil {
+		la.router.logError(ctx, msg, err)
+		return
 	}
-	adapter := NewLambdaAdapter(router, logger)
-	logger.Printf("Starting local server on %s", addr)
-	return http.ListenAndServe(addr, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		resp := adapter.ServeHTTP(r)
-		for k, v := range resp.Headers {
+	la.logger.Printf("%s: %v", msg, err)
+}
+
+func newHTTPHandler(adapter *LambdaAdapter) http.Handler {
+	router := adapter.router
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := stripBodyIfNotAllowed(adapter.ServeHTTP(r))
+		// Match the deployed response: repeated headers arrive joined, while
+		// every cookie gets its own Set-Cookie line.
+		headers, cookies := lambdaHeaders(resp)
+		for k, v := range headers {
 			w.Header().Set(k, v)
 		}
+		putHeaders(headers)
+		for _, cookie := range cookies {
+			w.Header().Add("Set-Cookie", cookie)
+		}
+		if stream, ok := resp.Body.(io.Reader); ok {
+			w.WriteHeader(resp.StatusCode)
+			if err := copyFlush(w, stream); err != nil {
+				adapter.logError(r.Context(), "Error streaming response body", err)
+			}
+			return
+		}
+		buf := getBuffer()
+		defer putBuffer(buf)
+		err := writeBody(buf, router.outputCodec(), resp.Body)
+		body := buf.Bytes()
+		if err == nil && resp.IsBase64Encoded {
+			body, err = base64.StdEncoding.DecodeString(buf.String())
+		}
+		if err != nil {
+			adapter.logError(r.Context(), "Error encoding response body", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
 		w.WriteHeader(resp.StatusCode)
-		if err := json.NewEncoder(w).Encode(resp.Body); err != nil {
-			logger.Printf("Error encoding response body: %v", err)
+		if _, err := w.Write(body); err != nil {
+			adapter.logError(r.Context(), "Error writing response body", err)
 		}
-	}))
+	})
+	if router.metrics != nil {
+		handler = router.metrics.handler(router, handler)
+	}
+	return handler
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
 }
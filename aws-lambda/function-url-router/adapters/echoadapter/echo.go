@@ -0,0 +1,15 @@
+package echoadapter
+
+import (
+	"github.com/labstack/echo/v4"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// Handler adapts an echo handler, and optionally echo middleware, to
+// router.Handler. Errors returned by h go through echo's HTTPErrorHandler.
+func Handler(h echo.HandlerFunc, middleware ...echo.MiddlewareFunc) router.Handler {
+	e := echo.New()
+	e.Any("/*", h, middleware...)
+	e.Any("/", h, middleware...)
+	return router.FromHTTPHandler(e)
+}
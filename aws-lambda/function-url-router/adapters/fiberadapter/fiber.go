@@ -0,0 +1,13 @@
+package fiberadapter
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// Handler adapts a fiber handler to router.Handler by way of fiber's
+// net/http adaptor.
+func Handler(h fiber.Handler) router.Handler {
+	return router.FromHTTPHandler(adaptor.FiberHandler(h))
+}
@@ -0,0 +1,16 @@
+package ginadapter
+
+import (
+	"github.com/gin-gonic/gin"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// Handler adapts gin handlers to router.Handler. The handlers run in order
+// inside a gin engine that routes every request to them, so c.Next, c.Abort
+// and gin's response helpers behave as they do under gin.
+func Handler(handlers ...gin.HandlerFunc) router.Handler {
+	engine := gin.New()
+	engine.NoRoute(handlers...)
+	engine.NoMethod(handlers...)
+	return router.FromHTTPHandler(engine)
+}
@@ -0,0 +1,74 @@
+package zapadapter
+
+import (
+	"context"
+	"log/slog"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type logger struct {
+	l *zap.Logger
+}
+
+// New adapts l to router.Logger. slog levels map to the nearest zap level
+// and attributes become zap fields.
+func New(l *zap.Logger) router.Logger {
+	return logger{l: l.WithOptions(zap.AddCallerSkip(1))}
+}
+
+func (z logger) LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	ce := z.l.Check(zapLevel(level), msg)
+	if ce == nil {
+		return
+	}
+	fields := make([]zap.Field, 0, len(attrs))
+	for _, a := range attrs {
+		fields = append(fields, field(a))
+	}
+	ce.Write(fields...)
+}
+
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+func field(a slog.Attr) zap.Field {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return zap.String(a.Key, v.String())
+	case slog.KindInt64:
+		return zap.Int64(a.Key, v.Int64())
+	case slog.KindUint64:
+		return zap.Uint64(a.Key, v.Uint64())
+	case slog.KindFloat64:
+		return zap.Float64(a.Key, v.Float64())
+	case slog.KindBool:
+		return zap.Bool(a.Key, v.Bool())
+	case slog.KindDuration:
+		return zap.Duration(a.Key, v.Duration())
+	case slog.KindTime:
+		return zap.Time(a.Key, v.Time())
+	case slog.KindGroup:
+		group := v.Group()
+		fields := make([]zap.Field, 0, len(group))
+		for _, ga := range group {
+			fields = append(fields, field(ga))
+		}
+		return zap.Dict(a.Key, fields...)
+	default:
+		return zap.Any(a.Key, v.Any())
+	}
+}
@@ -0,0 +1,71 @@
+package zerologadapter
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/rs/zerolog"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+type logger struct {
+	l zerolog.Logger
+}
+
+// New adapts l to router.Logger. slog levels map to the nearest zerolog
+// level and attributes become typed fields on the event.
+func New(l zerolog.Logger) router.Logger {
+	return logger{l: l}
+}
+
+func (z logger) LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	event := z.l.WithLevel(zerologLevel(level))
+	if event == nil {
+		return
+	}
+	for _, a := range attrs {
+		event = addField(event, a)
+	}
+	event.Ctx(ctx).Msg(msg)
+}
+
+func zerologLevel(level slog.Level) zerolog.Level {
+	switch {
+	case level >= slog.LevelError:
+		return zerolog.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zerolog.WarnLevel
+	case level >= slog.LevelInfo:
+		return zerolog.InfoLevel
+	default:
+		return zerolog.DebugLevel
+	}
+}
+
+func addField(event *zerolog.Event, a slog.Attr) *zerolog.Event {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return event.Str(a.Key, v.String())
+	case slog.KindInt64:
+		return event.Int64(a.Key, v.Int64())
+	case slog.KindUint64:
+		return event.Uint64(a.Key, v.Uint64())
+	case slog.KindFloat64:
+		return event.Float64(a.Key, v.Float64())
+	case slog.KindBool:
+		return event.Bool(a.Key, v.Bool())
+	case slog.KindDuration:
+		return event.Dur(a.Key, v.Duration())
+	case slog.KindTime:
+		return event.Time(a.Key, v.Time())
+	case slog.KindGroup:
+		dict := zerolog.Dict()
+		for _, ga := range v.Group() {
+			dict = addField(dict, ga)
+		}
+		return event.Dict(a.Key, dict)
+	default:
+		return event.Interface(a.Key, v.Any())
+	}
+}
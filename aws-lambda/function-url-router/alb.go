@@ -0,0 +1,131 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type ALBOptions struct {
+	HealthCheckPath string
+	HealthCheck     Handler
+}
+
+// ALBHandler returns a handler for Application Load Balancer target group
+// events. When the target group has multi-value headers enabled, the
+// response uses multi-value headers as ALB requires. Health checks from the
+// load balancer are answered by HealthCheck without going through routing or
+// middleware.
+func (r *Router) ALBHandler(opts ALBOptions) func(context.Context, events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+	if opts.HealthCheck == nil {
+		opts.HealthCheck = HandlerFunc(defaultHealthCheckHandler)
+	}
+	return func(ctx context.Context, req events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+		ctx = context.WithValue(ctx, albRequestKey, req)
+		multiValue := len(req.MultiValueHeaders) > 0
+		lambdaReq := albToFunctionURL(req)
+
+		var resp Response
+		if isALBHealthCheck(lambdaReq, opts.HealthCheckPath) {
+			resp = opts.HealthCheck.ServeHTTP(ctx, lambdaReq)
+		} else {
+			resp = r.HandleRequest(ctx, lambdaReq)
+		}
+		return toALBResponse(resp, r.toLambdaResponse(resp), multiValue), nil
+	}
+}
+
+func ALBRequest(ctx context.Context) (events.ALBTargetGroupRequest, bool) {
+	req, ok := ctx.Value(albRequestKey).(events.ALBTargetGroupRequest)
+	return req, ok
+}
+
+func isALBHealthCheck(req events.LambdaFunctionURLRequest, path string) bool {
+	if !strings.HasPrefix(req.Headers["user-agent"], "ELB-HealthChecker") {
+		return false
+	}
+	return path == "" || req.RequestContext.HTTP.Path == path
+}
+
+func albToFunctionURL(req events.ALBTargetGroupRequest) events.LambdaFunctionURLRequest {
+	headers, cookies := foldHeaders(req.Headers, req.MultiValueHeaders)
+
+	// ALB passes query strings through without decoding them.
+	single := make(map[string]string, len(req.QueryStringParameters))
+	for k, v := range req.QueryStringParameters {
+		single[albUnescape(k)] = albUnescape(v)
+	}
+	multi := make(map[string][]string, len(req.MultiValueQueryStringParameters))
+	for k, values := range req.MultiValueQueryStringParameters {
+		for _, v := range values {
+			multi[albUnescape(k)] = append(multi[albUnescape(k)], albUnescape(v))
+		}
+	}
+
+	now := time.Now()
+	return events.LambdaFunctionURLRequest{
+		Version:               "2.0",
+		RawPath:               req.Path,
+		RawQueryString:        rawQuery(single, multi),
+		Cookies:               cookies,
+		Headers:               headers,
+		QueryStringParameters: foldValues(single, multi),
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			DomainName: headers["host"],
+			Time:       now.Format(time.RFC3339),
+			TimeEpoch:  now.UnixNano() / int64(time.Millisecond),
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:    req.HTTPMethod,
+				Path:      req.Path,
+				Protocol:  "HTTP/1.1",
+				SourceIP:  strings.TrimSpace(strings.Split(headers["x-forwarded-for"], ",")[0]),
+				UserAgent: headers["user-agent"],
+			},
+		},
+		Body:            req.Body,
+		IsBase64Encoded: req.IsBase64Encoded,
+	}
+}
+
+func albUnescape(s string) string {
+	if unescaped, err := url.QueryUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
+
+// toALBResponse converts lambdaResp, the encoded form of resp. In
+// multi-value mode repeated headers are taken from resp so each value is
+// sent separately.
+func toALBResponse(resp Response, lambdaResp events.LambdaFunctionURLResponse, multiValue bool) events.ALBTargetGroupResponse {
+	out := events.ALBTargetGroupResponse{
+		StatusCode:        lambdaResp.StatusCode,
+		StatusDescription: fmt.Sprintf("%d %s", lambdaResp.StatusCode, http.StatusText(lambdaResp.StatusCode)),
+		Body:              lambdaResp.Body,
+		IsBase64Encoded:   lambdaResp.IsBase64Encoded,
+	}
+	if multiValue {
+		out.MultiValueHeaders = multiValueHeaders(resp, lambdaResp)
+		return out
+	}
+
+	out.Headers = lambdaResp.Headers
+	if len(lambdaResp.Cookies) > 0 {
+		// Without multi-value headers ALB can only return one cookie.
+		out.Headers["Set-Cookie"] = lambdaResp.Cookies[len(lambdaResp.Cookies)-1]
+	}
+	return out
+}
+
+func defaultHealthCheckHandler(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+	return Response{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       map[string]string{"status": "ok"},
+	}
+}
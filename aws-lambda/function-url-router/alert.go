@@ -0,0 +1,148 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type AlertKind string
+
+const (
+	AlertSlowRequest  AlertKind = "slow_request"
+	AlertServerErrors AlertKind = "server_errors"
+)
+
+// Alert describes the request that crossed a threshold. For
+// AlertServerErrors, Count is the number of 5xx responses the route returned
+// within Window, and the other fields describe the last of them.
+type Alert struct {
+	Kind      AlertKind
+	Time      time.Time
+	Method    string
+	Route     string
+	Path      string
+	Status    int
+	Duration  time.Duration
+	Count     int
+	Window    time.Duration
+	RequestID string
+	TraceID   string
+	Error     string
+}
+
+// Alerter delivers alerts, e.g. snsalert.Publisher.
+type Alerter interface {
+	Alert(ctx context.Context, alert Alert) error
+}
+
+type AlertConfig struct {
+	Alerter Alerter
+	// LatencyThreshold fires AlertSlowRequest for requests that take
+	// longer. Zero disables it.
+	LatencyThreshold time.Duration
+	// ErrorThreshold fires AlertServerErrors once a route returns that many
+	// 5xx responses, or panics, within ErrorWindow. Zero disables it.
+	ErrorThreshold int
+	// ErrorWindow defaults to five minutes.
+	ErrorWindow time.Duration
+	// Cooldown is the minimum time between alerts of the same kind for a
+	// route, so one incident pages once. Defaults to ErrorWindow.
+	Cooldown time.Duration
+}
+
+// SetAlerting calls config.Alerter at the end of requests that cross the
+// configured thresholds. Alerts are sent before the response is returned,
+// since Lambda may freeze the environment right after; failures to send are
+// logged. Counts are kept per execution environment.
+func (r *Router) SetAlerting(config AlertConfig) {
+	if config.ErrorWindow <= 0 {
+		config.ErrorWindow = 5 * time.Minute
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = config.ErrorWindow
+	}
+	r.alerts = &alertTracker{
+		config: config,
+		errors: make(map[routeSeries][]time.Time),
+		fired:  make(map[alertKey]time.Time),
+	}
+}
+
+type alertKey struct {
+	series routeSeries
+	kind   AlertKind
+}
+
+type alertTracker struct {
+	config AlertConfig
+	mu     sync.Mutex
+	errors map[routeSeries][]time.Time
+	fired  map[alertKey]time.Time
+}
+
+func (r *Router) checkAlerts(ctx context.Context, req events.LambdaFunctionURLRequest, resp Response, duration time.Duration, err error) {
+	t := r.alerts
+	if t == nil || t.config.Alerter == nil {
+		return
+	}
+	route := MatchedRoute(ctx)
+	if route == "" {
+		route = "unmatched"
+	}
+	series := routeSeries{method: req.RequestContext.HTTP.Method, route: route}
+	now := time.Now()
+
+	var alerts []Alert
+	t.mu.Lock()
+	if t.config.LatencyThreshold > 0 && duration > t.config.LatencyThreshold && t.fire(series, AlertSlowRequest, now) {
+		alerts = append(alerts, Alert{Kind: AlertSlowRequest})
+	}
+	if t.config.ErrorThreshold > 0 && (err != nil || resp.StatusCode >= http.StatusInternalServerError) {
+		times := t.errors[series]
+		i := 0
+		for i < len(times) && now.Sub(times[i]) > t.config.ErrorWindow {
+			i++
+		}
+		times = append(times[i:], now)
+		if len(times) >= t.config.ErrorThreshold && t.fire(series, AlertServerErrors, now) {
+			alerts = append(alerts, Alert{Kind: AlertServerErrors, Count: len(times), Window: t.config.ErrorWindow})
+			times = nil
+		}
+		t.errors[series] = times
+	}
+	t.mu.Unlock()
+
+	for _, alert := range alerts {
+		alert.Time = now
+		alert.Method = series.method
+		alert.Route = series.route
+		alert.Path = req.RequestContext.HTTP.Path
+		alert.Status = resp.StatusCode
+		alert.Duration = duration
+		alert.RequestID = requestID(ctx, req)
+		if trace, ok := Trace(ctx); ok {
+			alert.TraceID = trace.TraceID
+		}
+		if err != nil {
+			alert.Error = err.Error()
+		}
+		if sendErr := t.config.Alerter.Alert(ctx, alert); sendErr != nil {
+			r.logError(ctx, "Sending alert failed", sendErr)
+		}
+	}
+}
+
+// fire reports whether an alert of kind may be sent for series now, and if
+// so starts its cooldown. t.mu must be held.
+func (t *alertTracker) fire(series routeSeries, kind AlertKind, now time.Time) bool {
+	key := alertKey{series: series, kind: kind}
+	if last, ok := t.fired[key]; ok && now.Sub(last) < t.config.Cooldown {
+		return false
+	}
+	t.fired[key] = now
+	return true
+}
@@ -0,0 +1,143 @@
+package router
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// APIGatewayProxyHandler returns a handler for REST API proxy integration
+// events. Multi-value headers and query strings are folded into the
+// comma-joined form Function URLs use, and cookies are split out of the
+// Cookie header. Responses use multi-value headers, so repeated headers
+// and cookies are each sent separately.
+func (r *Router) APIGatewayProxyHandler() func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		ctx = context.WithValue(ctx, apiGatewayProxyRequestKey, req)
+		resp := r.HandleRequest(ctx, apiGatewayProxyToFunctionURL(req))
+		lambdaResp := r.toLambdaResponse(resp)
+		return events.APIGatewayProxyResponse{
+			StatusCode:        lambdaResp.StatusCode,
+			MultiValueHeaders: multiValueHeaders(resp, lambdaResp),
+			Body:              lambdaResp.Body,
+			IsBase64Encoded:   lambdaResp.IsBase64Encoded,
+		}, nil
+	}
+}
+
+// APIGatewayProxyRequest returns the original REST API event when the
+// request arrived through APIGatewayProxyHandler.
+func APIGatewayProxyRequest(ctx context.Context) (events.APIGatewayProxyRequest, bool) {
+	req, ok := ctx.Value(apiGatewayProxyRequestKey).(events.APIGatewayProxyRequest)
+	return req, ok
+}
+
+func StageVariables(ctx context.Context) map[string]string {
+	if req, ok := APIGatewayProxyRequest(ctx); ok {
+		return req.StageVariables
+	}
+	if req, ok := APIGatewayV2Request(ctx); ok {
+		return req.StageVariables
+	}
+	return nil
+}
+
+func apiGatewayProxyToFunctionURL(req events.APIGatewayProxyRequest) events.LambdaFunctionURLRequest {
+	rc := req.RequestContext
+	headers, cookies := foldHeaders(req.Headers, req.MultiValueHeaders)
+	query := foldValues(req.QueryStringParameters, req.MultiValueQueryStringParameters)
+
+	var authorizer *events.LambdaFunctionURLRequestContextAuthorizerDescription
+	if rc.Identity.AccessKey != "" || rc.Identity.UserArn != "" {
+		authorizer = &events.LambdaFunctionURLRequestContextAuthorizerDescription{
+			IAM: &events.LambdaFunctionURLRequestContextAuthorizerIAMDescription{
+				AccessKey: rc.Identity.AccessKey,
+				AccountID: rc.Identity.AccountID,
+				CallerID:  rc.Identity.Caller,
+				UserARN:   rc.Identity.UserArn,
+				UserID:    rc.Identity.User,
+			},
+		}
+	}
+
+	return events.LambdaFunctionURLRequest{
+		Version:               "2.0",
+		RawPath:               req.Path,
+		RawQueryString:        rawQuery(req.QueryStringParameters, req.MultiValueQueryStringParameters),
+		Cookies:               cookies,
+		Headers:               headers,
+		QueryStringParameters: query,
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			AccountID:    rc.AccountID,
+			RequestID:    rc.RequestID,
+			Authorizer:   authorizer,
+			APIID:        rc.APIID,
+			DomainName:   rc.DomainName,
+			DomainPrefix: rc.DomainPrefix,
+			Time:         rc.RequestTime,
+			TimeEpoch:    rc.RequestTimeEpoch,
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:    req.HTTPMethod,
+				Path:      req.Path,
+				Protocol:  rc.Protocol,
+				SourceIP:  rc.Identity.SourceIP,
+				UserAgent: rc.Identity.UserAgent,
+			},
+		},
+		Body:            req.Body,
+		IsBase64Encoded: req.IsBase64Encoded,
+	}
+}
+
+// foldHeaders lowercases header names, joins repeated values with commas and
+// extracts cookies from the Cookie header.
+func foldHeaders(single map[string]string, multi map[string][]string) (map[string]string, []string) {
+	headers := make(map[string]string, len(single))
+	if len(multi) > 0 {
+		for k, v := range multi {
+			headers[strings.ToLower(k)] = strings.Join(v, ",")
+		}
+	} else {
+		for k, v := range single {
+			headers[strings.ToLower(k)] = v
+		}
+	}
+
+	var cookies []string
+	if cookie, ok := headers["cookie"]; ok {
+		for _, c := range strings.Split(cookie, ";") {
+			if c = strings.TrimSpace(c); c != "" {
+				cookies = append(cookies, c)
+			}
+		}
+		delete(headers, "cookie")
+	}
+	return headers, cookies
+}
+
+func foldValues(single map[string]string, multi map[string][]string) map[string]string {
+	if len(multi) == 0 {
+		return single
+	}
+	values := make(map[string]string, len(multi))
+	for k, v := range multi {
+		values[k] = strings.Join(v, ",")
+	}
+	return values
+}
+
+func rawQuery(single map[string]string, multi map[string][]string) string {
+	values := url.Values{}
+	if len(multi) > 0 {
+		for k, v := range multi {
+			values[k] = v
+		}
+	} else {
+		for k, v := range single {
+			values.Set(k, v)
+		}
+	}
+	return values.Encode()
+}
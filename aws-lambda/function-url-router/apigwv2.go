@@ -0,0 +1,102 @@
+package router
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// APIGatewayV2Handler returns a handler for HTTP API (payload format 2.0)
+// events, so the same routes can be deployed behind API Gateway. The stage
+// prefix is stripped from the path for named stages.
+func (r *Router) APIGatewayV2Handler() func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+	return func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		ctx = context.WithValue(ctx, apiGatewayV2RequestKey, req)
+		resp := r.toLambdaResponse(r.HandleRequest(ctx, apiGatewayV2ToFunctionURL(req)))
+		return events.APIGatewayV2HTTPResponse{
+			StatusCode:      resp.StatusCode,
+			Headers:         resp.Headers,
+			Body:            resp.Body,
+			IsBase64Encoded: resp.IsBase64Encoded,
+			Cookies:         resp.Cookies,
+		}, nil
+	}
+}
+
+// APIGatewayV2Request returns the original HTTP API event when the request
+// arrived through APIGatewayV2Handler.
+func APIGatewayV2Request(ctx context.Context) (events.APIGatewayV2HTTPRequest, bool) {
+	req, ok := ctx.Value(apiGatewayV2RequestKey).(events.APIGatewayV2HTTPRequest)
+	return req, ok
+}
+
+// APIGatewayV2Authorizer returns the JWT, Lambda or IAM authorizer context of
+// an HTTP API request, or nil.
+func APIGatewayV2Authorizer(ctx context.Context) *events.APIGatewayV2HTTPRequestContextAuthorizerDescription {
+	req, ok := APIGatewayV2Request(ctx)
+	if !ok {
+		return nil
+	}
+	return req.RequestContext.Authorizer
+}
+
+func apiGatewayV2ToFunctionURL(req events.APIGatewayV2HTTPRequest) events.LambdaFunctionURLRequest {
+	rc := req.RequestContext
+	path := stripStage(req.RawPath, rc.Stage)
+
+	var authorizer *events.LambdaFunctionURLRequestContextAuthorizerDescription
+	if rc.Authorizer != nil && rc.Authorizer.IAM != nil {
+		iam := rc.Authorizer.IAM
+		authorizer = &events.LambdaFunctionURLRequestContextAuthorizerDescription{
+			IAM: &events.LambdaFunctionURLRequestContextAuthorizerIAMDescription{
+				AccessKey: iam.AccessKey,
+				AccountID: iam.AccountID,
+				CallerID:  iam.CallerID,
+				UserARN:   iam.UserARN,
+				UserID:    iam.UserID,
+			},
+		}
+	}
+
+	return events.LambdaFunctionURLRequest{
+		Version:               "2.0",
+		RawPath:               path,
+		RawQueryString:        req.RawQueryString,
+		Cookies:               req.Cookies,
+		Headers:               req.Headers,
+		QueryStringParameters: req.QueryStringParameters,
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			AccountID:    rc.AccountID,
+			RequestID:    rc.RequestID,
+			Authorizer:   authorizer,
+			APIID:        rc.APIID,
+			DomainName:   rc.DomainName,
+			DomainPrefix: rc.DomainPrefix,
+			Time:         rc.Time,
+			TimeEpoch:    rc.TimeEpoch,
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:    rc.HTTP.Method,
+				Path:      stripStage(rc.HTTP.Path, rc.Stage),
+				Protocol:  rc.HTTP.Protocol,
+				SourceIP:  rc.HTTP.SourceIP,
+				UserAgent: rc.HTTP.UserAgent,
+			},
+		},
+		Body:            req.Body,
+		IsBase64Encoded: req.IsBase64Encoded,
+	}
+}
+
+func stripStage(path, stage string) string {
+	if stage == "" || stage == "$default" {
+		return path
+	}
+	if trimmed, ok := strings.CutPrefix(path, "/"+stage); ok && (trimmed == "" || strings.HasPrefix(trimmed, "/")) {
+		if trimmed == "" {
+			return "/"
+		}
+		return trimmed
+	}
+	return path
+}
@@ -0,0 +1,255 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda/messages"
+)
+
+type AppSyncEvent struct {
+	Arguments json.RawMessage        `json:"arguments"`
+	Identity  json.RawMessage        `json:"identity"`
+	Source    json.RawMessage        `json:"source"`
+	Request   AppSyncRequest         `json:"request"`
+	Info      AppSyncInfo            `json:"info"`
+	Prev      json.RawMessage        `json:"prev"`
+	Stash     map[string]interface{} `json:"stash"`
+}
+
+type AppSyncRequest struct {
+	Headers    map[string]string `json:"headers"`
+	DomainName string            `json:"domainName"`
+}
+
+type AppSyncInfo struct {
+	FieldName           string                 `json:"fieldName"`
+	ParentTypeName      string                 `json:"parentTypeName"`
+	SelectionSetList    []string               `json:"selectionSetList"`
+	SelectionSetGraphQL string                 `json:"selectionSetGraphQL"`
+	Variables           map[string]interface{} `json:"variables"`
+}
+
+// AppSyncError is reported to AppSync with Type as errorType.
+type AppSyncError struct {
+	Type    string
+	Message string
+}
+
+func (e *AppSyncError) Error() string {
+	return e.Message
+}
+
+type ResolverFunc func(context.Context, AppSyncEvent) (interface{}, error)
+
+type appSyncBatchResult struct {
+	Data         interface{} `json:"data"`
+	ErrorMessage string      `json:"errorMessage,omitempty"`
+	ErrorType    string      `json:"errorType,omitempty"`
+}
+
+// AppSyncResolver routes AppSync direct Lambda resolver invocations by
+// parent type and field name. Each invocation runs through the router's pre
+// and post middleware as a POST request to /<type>/<field> carrying the
+// arguments as its body and the GraphQL request headers, so a middleware's
+// ExcludedRoutes, ExcludedMethods and ExcludedHeaders apply to resolvers
+// through that path, method and those headers. Like the first HTTP
+// request, the first invocation freezes the router.
+type AppSyncResolver struct {
+	router    *Router
+	resolvers map[string]ResolverFunc
+	// chains caches each resolver's compiled middleware chain, keyed by
+	// <type>.<field>, along with the route table it was compiled from.
+	chains sync.Map
+}
+
+type appSyncChain struct {
+	table   *routeTable
+	handler Handler
+}
+
+// appSyncInvocation is the context value a resolver's chain reads its event
+// from and reports the resolver's error through.
+type appSyncInvocation struct {
+	event AppSyncEvent
+	err   error
+}
+
+func NewAppSyncResolver(r *Router) *AppSyncResolver {
+	return &AppSyncResolver{router: r, resolvers: make(map[string]ResolverFunc)}
+}
+
+func (a *AppSyncResolver) Resolve(typeName, fieldName string, fn ResolverFunc) {
+	a.resolvers[typeName+"."+fieldName] = fn
+	a.chains.Delete(typeName + "." + fieldName)
+}
+
+func AppSyncEventFromContext(ctx context.Context) (AppSyncEvent, bool) {
+	inv, ok := ctx.Value(appSyncEventKey).(*appSyncInvocation)
+	if !ok {
+		return AppSyncEvent{}, false
+	}
+	return inv.event, true
+}
+
+// chain returns the resolver's handler wrapped in the middleware that
+// applies to path, compiling it once per route table.
+func (a *AppSyncResolver) chain(key, path string, fn ResolverFunc) Handler {
+	table := a.router.routing()
+	if cached, ok := a.chains.Load(key); ok && cached.(*appSyncChain).table == table {
+		return cached.(*appSyncChain).handler
+	}
+	handler := table.chain(http.MethodPost, path, HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		inv := ctx.Value(appSyncEventKey).(*appSyncInvocation)
+		result, err := fn(ctx, inv.event)
+		if err != nil {
+			inv.err = err
+			return Response{StatusCode: http.StatusInternalServerError}
+		}
+		return Response{StatusCode: http.StatusOK, Body: result}
+	}))
+	a.chains.Store(key, &appSyncChain{table: table, handler: handler})
+	return handler
+}
+
+// LambdaHandler accepts both single and batched (BatchInvoke) events.
+func (a *AppSyncResolver) LambdaHandler() func(context.Context, json.RawMessage) (interface{}, error) {
+	return func(ctx context.Context, payload json.RawMessage) (interface{}, error) {
+		if trimmed := strings.TrimSpace(string(payload)); strings.HasPrefix(trimmed, "[") {
+			var batch []AppSyncEvent
+			if err := json.Unmarshal(payload, &batch); err != nil {
+				return nil, err
+			}
+			results := make([]appSyncBatchResult, len(batch))
+			for i, event := range batch {
+				data, err := a.resolve(ctx, event)
+				results[i].Data = data
+				if err != nil {
+					results[i].ErrorType, results[i].ErrorMessage = appSyncErrorFields(err)
+				}
+			}
+			return results, nil
+		}
+
+		var event AppSyncEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, err
+		}
+		data, err := a.resolve(ctx, event)
+		if err != nil {
+			errorType, message := appSyncErrorFields(err)
+			return nil, messages.InvokeResponse_Error{Type: errorType, Message: message}
+		}
+		return data, nil
+	}
+}
+
+func (a *AppSyncResolver) resolve(ctx context.Context, event AppSyncEvent) (data interface{}, err error) {
+	startTime := time.Now()
+	req := appSyncToFunctionURL(event)
+	var resp Response
+
+	defer func() {
+		if e := recover(); e != nil {
+			a.router.recoverPanic(ctx, req, e)
+			err = fmt.Errorf("panic: %v", e)
+			data = nil
+		}
+		a.router.logRequestCompletion(ctx, req, resp, time.Since(startTime), err)
+	}()
+
+	key := event.Info.ParentTypeName + "." + event.Info.FieldName
+	fn, ok := a.resolvers[key]
+	if !ok {
+		resp = Response{StatusCode: http.StatusNotFound}
+		return nil, &AppSyncError{Type: "NotFound", Message: fmt.Sprintf("no resolver for %s.%s", event.Info.ParentTypeName, event.Info.FieldName)}
+	}
+
+	ctx = context.WithValue(ctx, routerKey, a.router)
+	inv := &appSyncInvocation{event: event}
+	ctx = context.WithValue(ctx, appSyncEventKey, inv)
+	resp = a.chain(key, req.RawPath, fn).ServeHTTP(ctx, req)
+
+	switch {
+	case inv.err != nil:
+		return nil, inv.err
+	case resp.StatusCode >= 400:
+		return nil, &AppSyncError{Type: appSyncErrorType(resp.StatusCode), Message: responseErrorMessage(resp)}
+	}
+	return resp.Body, nil
+}
+
+func appSyncToFunctionURL(event AppSyncEvent) events.LambdaFunctionURLRequest {
+	path := "/" + event.Info.ParentTypeName + "/" + event.Info.FieldName
+	headers := make(map[string]string, len(event.Request.Headers))
+	for k, v := range event.Request.Headers {
+		headers[strings.ToLower(k)] = v
+	}
+	now := time.Now()
+	return events.LambdaFunctionURLRequest{
+		Version: "2.0",
+		RawPath: path,
+		Headers: headers,
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			DomainName: event.Request.DomainName,
+			Time:       now.Format(time.RFC3339),
+			TimeEpoch:  now.UnixNano() / int64(time.Millisecond),
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:    http.MethodPost,
+				Path:      path,
+				UserAgent: headers["user-agent"],
+			},
+		},
+		Body: string(event.Arguments),
+	}
+}
+
+func appSyncErrorFields(err error) (string, string) {
+	var appSyncErr *AppSyncError
+	if errors.As(err, &appSyncErr) {
+		return appSyncErr.Type, appSyncErr.Message
+	}
+	return "InternalFailure", err.Error()
+}
+
+func appSyncErrorType(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "Unauthorized"
+	case http.StatusForbidden:
+		return "Forbidden"
+	case http.StatusNotFound:
+		return "NotFound"
+	case http.StatusTooManyRequests:
+		return "TooManyRequests"
+	}
+	if status < 500 {
+		return "BadRequest"
+	}
+	return "InternalFailure"
+}
+
+func responseErrorMessage(resp Response) string {
+	switch body := resp.Body.(type) {
+	case map[string]string:
+		if msg := body["error"]; msg != "" {
+			return msg
+		}
+	case map[string]interface{}:
+		if msg, ok := body["error"].(string); ok {
+			return msg
+		}
+	case string:
+		if body != "" {
+			return body
+		}
+	}
+	return http.StatusText(resp.StatusCode)
+}
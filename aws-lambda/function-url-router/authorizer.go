@@ -0,0 +1,127 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// AuthorizerContext is the context map returned by an API Gateway Lambda
+// authorizer. REST APIs deliver every value as a string while HTTP APIs keep
+// JSON types, so the typed accessors accept either form.
+type AuthorizerContext map[string]interface{}
+
+// Authorizer returns the Lambda authorizer context of a request that arrived
+// through APIGatewayProxyHandler or APIGatewayV2Handler, the claims verified
+// by the JWT middleware, or the claims set with LocalRequestContext when
+// running locally. It returns nil otherwise.
+func Authorizer(ctx context.Context) AuthorizerContext {
+	if claims, ok := ctx.Value(localAuthorizerKey).(AuthorizerContext); ok {
+		return claims
+	}
+	if claims, ok := ctx.Value(jwtClaimsKey).(AuthorizerContext); ok {
+		return claims
+	}
+	if req, ok := APIGatewayProxyRequest(ctx); ok && len(req.RequestContext.Authorizer) > 0 {
+		return AuthorizerContext(req.RequestContext.Authorizer)
+	}
+	if req, ok := APIGatewayV2Request(ctx); ok && req.RequestContext.Authorizer != nil && len(req.RequestContext.Authorizer.Lambda) > 0 {
+		return AuthorizerContext(req.RequestContext.Authorizer.Lambda)
+	}
+	return nil
+}
+
+// PrincipalID returns the principal a REST API Lambda authorizer returned.
+func PrincipalID(ctx context.Context) string {
+	s, _ := Authorizer(ctx).String("principalId")
+	return s
+}
+
+func (a AuthorizerContext) String(key string) (string, bool) {
+	v, ok := a[key]
+	if !ok || v == nil {
+		return "", false
+	}
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+	return fmt.Sprint(v), true
+}
+
+func (a AuthorizerContext) Int(key string) (int64, bool) {
+	switch v := a[key].(type) {
+	case float64:
+		return int64(v), true
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func (a AuthorizerContext) Float(key string) (float64, bool) {
+	switch v := a[key].(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (a AuthorizerContext) Bool(key string) (bool, bool) {
+	switch v := a[key].(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(v)
+		return b, err == nil
+	}
+	return false, false
+}
+
+// RequireAuthorizer rejects requests without an authorizer context with 401
+// and those whose context fails policy with 403.
+func RequireAuthorizer(policy func(AuthorizerContext) bool) MiddlewareFunc {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			auth := Authorizer(ctx)
+			if auth == nil {
+				return Response{
+					StatusCode: http.StatusUnauthorized,
+					Headers:    map[string]string{"Content-Type": "application/json"},
+					Body:       map[string]string{"error": "Unauthorized"},
+				}
+			}
+			if policy != nil && !policy(auth) {
+				return Response{
+					StatusCode: http.StatusForbidden,
+					Headers:    map[string]string{"Content-Type": "application/json"},
+					Body:       map[string]string{"error": "Forbidden"},
+				}
+			}
+			return next.ServeHTTP(ctx, req)
+		})
+	}
+}
+
+// AuthorizerValue is a policy for RequireAuthorizer that passes when key
+// holds one of values.
+func AuthorizerValue(key string, values ...string) func(AuthorizerContext) bool {
+	return func(a AuthorizerContext) bool {
+		v, ok := a.String(key)
+		if !ok {
+			return false
+		}
+		for _, want := range values {
+			if v == want {
+				return true
+			}
+		}
+		return false
+	}
+}
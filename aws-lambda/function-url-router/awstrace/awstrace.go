@@ -0,0 +1,40 @@
+package awstrace
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// Instrument makes every client built from cfg send the trace of the
+// request being handled, from router.Trace, as the X-Amzn-Trace-Id header,
+// so X-Ray links downstream calls to the invocation, along with its
+// router.CorrelationID:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	awstrace.Instrument(&cfg)
+//	db := dynamodb.NewFromConfig(cfg)
+func Instrument(cfg *aws.Config) {
+	cfg.APIOptions = append(cfg.APIOptions, AddMiddleware)
+}
+
+// AddMiddleware adds the trace and correlation ID header middleware to a
+// single client's stack, e.g. through s3.Options.APIOptions.
+func AddMiddleware(stack *middleware.Stack) error {
+	return stack.Build.Add(middleware.BuildMiddlewareFunc("RouterTraceHeader", traceHeader), middleware.After)
+}
+
+func traceHeader(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+	if req, ok := in.Request.(*smithyhttp.Request); ok {
+		if trace, ok := router.Trace(ctx); ok {
+			req.Header.Set("X-Amzn-Trace-Id", trace.Header())
+		}
+		if id := router.CorrelationID(ctx); id != "" {
+			req.Header.Set(router.CorrelationHeader, id)
+		}
+	}
+	return next.HandleBuild(ctx, in)
+}
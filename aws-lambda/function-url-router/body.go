@@ -0,0 +1,133 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// BodyConfig bounds how request bodies received over HTTP, by the local
+// server or HTTPHandler, are read. Events from Lambda are already in memory
+// and aren't affected.
+type BodyConfig struct {
+	// MaxSize rejects larger bodies with 413 Request Entity Too Large. Zero
+	// means no limit; deployed Function URLs reject payloads over 6 MB.
+	MaxSize int64
+	// MemoryLimit is the largest body put into the event. Larger ones are
+	// written to a temporary file in TempDir instead, leaving the event's
+	// Body empty; handlers read them with BodyReader, and Bind reads them
+	// transparently. Zero keeps every body in memory.
+	MemoryLimit int64
+	// TempDir defaults to os.TempDir().
+	TempDir string
+}
+
+func (r *Router) SetBodyConfig(config BodyConfig) {
+	r.bodyConfig = config
+}
+
+// BodyReader returns the request body, decoded if it was base64-encoded.
+// Unlike req.Body it includes bodies the HTTP adapter wrote to disk for
+// being larger than BodyConfig.MemoryLimit, without loading them into
+// memory.
+func BodyReader(ctx context.Context, req events.LambdaFunctionURLRequest) (io.ReadCloser, error) {
+	body, _, err := bodyReader(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if rc, ok := body.(io.ReadCloser); ok {
+		return rc, nil
+	}
+	return io.NopCloser(body), nil
+}
+
+// bodyReader is BodyReader with the body's size. Spilled bodies are returned
+// as the open *os.File, in-memory ones as a *bytes.Reader.
+func bodyReader(ctx context.Context, req events.LambdaFunctionURLRequest) (io.Reader, int64, error) {
+	if path, ok := ctx.Value(spilledBodyKey).(string); ok {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, 0, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		return f, info.Size(), nil
+	}
+	body, err := requestBody(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(body), int64(len(body)), nil
+}
+
+// bodyBytes is requestBody that also reads spilled bodies, for callers that
+// need the whole body anyway.
+func bodyBytes(ctx context.Context, req events.LambdaFunctionURLRequest) ([]byte, error) {
+	if path, ok := ctx.Value(spilledBodyKey).(string); ok {
+		return os.ReadFile(path)
+	}
+	return requestBody(req)
+}
+
+var errBodyTooLarge = errors.New("request body too large")
+
+// readBody reads r's body within config's limits, streaming it to a
+// temporary file once it outgrows MemoryLimit and returning the file's path
+// instead of the body. The caller removes the file.
+func readBody(r *http.Request, config BodyConfig) ([]byte, string, error) {
+	if r.Body == nil {
+		return nil, "", nil
+	}
+	defer r.Body.Close()
+	body := io.Reader(r.Body)
+	if config.MaxSize > 0 {
+		if r.ContentLength > config.MaxSize {
+			return nil, "", errBodyTooLarge
+		}
+		body = io.LimitReader(body, config.MaxSize+1)
+	}
+	if config.MemoryLimit <= 0 {
+		data, err := io.ReadAll(body)
+		if err == nil && config.MaxSize > 0 && int64(len(data)) > config.MaxSize {
+			err = errBodyTooLarge
+		}
+		return data, "", err
+	}
+
+	var buf bytes.Buffer
+	if r.ContentLength > 0 && r.ContentLength <= config.MemoryLimit {
+		buf.Grow(int(r.ContentLength))
+	}
+	n, err := buf.ReadFrom(io.LimitReader(body, config.MemoryLimit+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if n <= config.MemoryLimit {
+		return buf.Bytes(), "", nil
+	}
+
+	f, err := os.CreateTemp(config.TempDir, "router-body-*")
+	if err != nil {
+		return nil, "", err
+	}
+	written, err := io.Copy(f, io.MultiReader(&buf, body))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && config.MaxSize > 0 && written > config.MaxSize {
+		err = errBodyTooLarge
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return nil, "", err
+	}
+	return nil, f.Name(), nil
+}
@@ -0,0 +1,106 @@
+package router
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// TestSpilledBodyReaders sends bodies larger than MemoryLimit through
+// HTTPHandler, so each is written to a temporary file and left out of the
+// event, and checks that every way of reading a body still sees it.
+func TestSpilledBodyReaders(t *testing.T) {
+	dir := t.TempDir()
+	r := newTestRouter()
+	r.SetBodyConfig(BodyConfig{MaxSize: 1 << 20, MemoryLimit: 16, TempDir: dir})
+
+	type item struct {
+		Name string `xml:"name" json:"name"`
+	}
+	name := strings.Repeat("x", 64)
+	bindRoute := func(bind func(ctx context.Context, req events.LambdaFunctionURLRequest, v *item) error) Handler {
+		return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			if req.Body != "" {
+				t.Error("spilled body was also put in the event")
+			}
+			var v item
+			if err := bind(ctx, req, &v); err != nil {
+				return Response{StatusCode: http.StatusBadRequest, Body: err.Error()}
+			}
+			return Response{StatusCode: http.StatusOK, Body: v.Name}
+		})
+	}
+	r.AddRoute(http.MethodPost, "/bind", bindRoute(func(ctx context.Context, req events.LambdaFunctionURLRequest, v *item) error {
+		return Bind(ctx, req, v)
+	}))
+	r.AddRoute(http.MethodPost, "/bindxml", bindRoute(func(ctx context.Context, req events.LambdaFunctionURLRequest, v *item) error {
+		return BindXML(ctx, req, v)
+	}))
+	r.AddRoute(http.MethodPost, "/bindwith", bindRoute(func(ctx context.Context, req events.LambdaFunctionURLRequest, v *item) error {
+		return BindWith(ctx, jsonCodec{}, req, v)
+	}))
+	r.AddRoute(http.MethodPost, "/reader", HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		rc, err := BodyReader(ctx, req)
+		if err != nil {
+			return Response{StatusCode: http.StatusBadRequest, Body: err.Error()}
+		}
+		defer rc.Close()
+		data, _ := io.ReadAll(rc)
+		return Response{StatusCode: http.StatusOK, Body: string(data)}
+	}))
+	r.AddRoute(http.MethodPost, "/handler", FromHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		if req.ContentLength != int64(len(data)) {
+			t.Errorf("ContentLength = %d, body has %d bytes", req.ContentLength, len(data))
+		}
+		w.Write(data)
+	})))
+
+	xmlBody, _ := xml.Marshal(item{Name: name})
+	jsonBody := `{"name":"` + name + `"}`
+	cases := []struct {
+		path, contentType, body, want string
+	}{
+		{"/bind", "application/json", jsonBody, name},
+		{"/bind", "application/xml", string(xmlBody), name},
+		{"/bindxml", "text/xml", string(xmlBody), name},
+		{"/bindwith", "application/json", jsonBody, name},
+		{"/reader", "text/plain", jsonBody, jsonBody},
+		{"/handler", "text/plain", jsonBody, jsonBody},
+	}
+	handler := r.HTTPHandler()
+	for _, c := range cases {
+		t.Run(c.path+" "+c.contentType, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, c.path, strings.NewReader(c.body))
+			req.Header.Set("Content-Type", c.contentType)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != http.StatusOK || w.Body.String() != c.want {
+				t.Errorf("response = %d %q, want 200 %q", w.Code, w.Body.String(), c.want)
+			}
+		})
+	}
+
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("%d spilled bodies were left in the temp dir", len(files))
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	r := newTestRouter()
+	r.SetBodyConfig(BodyConfig{MaxSize: 8})
+	r.AddRoute(http.MethodPost, "/", textHandler("ok"))
+
+	w := httptest.NewRecorder()
+	r.HTTPHandler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("0123456789")))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
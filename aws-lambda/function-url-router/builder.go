@@ -0,0 +1,123 @@
+package router
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"strings"
+)
+
+type ResponseBuilder struct {
+	resp Response
+	err  error
+}
+
+func NewResponse() *ResponseBuilder {
+	return &ResponseBuilder{resp: Response{
+		StatusCode: http.StatusOK,
+		Headers:    make(map[string]string),
+	}}
+}
+
+// ModifyResponse starts a builder from an existing response, typically in
+// middleware. The header maps are copied so the original is left untouched.
+func ModifyResponse(resp Response) *ResponseBuilder {
+	b := &ResponseBuilder{resp: resp}
+	b.resp.Headers = make(map[string]string, len(resp.Headers))
+	for k, v := range resp.Headers {
+		b.resp.Headers[k] = v
+	}
+	if resp.MultiValueHeaders != nil {
+		b.resp.MultiValueHeaders = make(map[string][]string, len(resp.MultiValueHeaders))
+		for k, v := range resp.MultiValueHeaders {
+			b.resp.MultiValueHeaders[k] = append([]string(nil), v...)
+		}
+	}
+	return b
+}
+
+func (b *ResponseBuilder) Status(status int) *ResponseBuilder {
+	b.resp.StatusCode = status
+	return b
+}
+
+func (b *ResponseBuilder) Header(key, value string) *ResponseBuilder {
+	b.DelHeader(key)
+	b.resp.Headers[http.CanonicalHeaderKey(key)] = value
+	return b
+}
+
+func (b *ResponseBuilder) AddHeader(key, value string) *ResponseBuilder {
+	b.resp.AddHeader(key, value)
+	return b
+}
+
+func (b *ResponseBuilder) DelHeader(key string) *ResponseBuilder {
+	for k := range b.resp.Headers {
+		if strings.EqualFold(k, key) {
+			delete(b.resp.Headers, k)
+		}
+	}
+	for k := range b.resp.MultiValueHeaders {
+		if strings.EqualFold(k, key) {
+			delete(b.resp.MultiValueHeaders, k)
+		}
+	}
+	return b
+}
+
+func (b *ResponseBuilder) Cookie(cookie *http.Cookie) *ResponseBuilder {
+	return b.AddHeader("Set-Cookie", cookie.String())
+}
+
+func (b *ResponseBuilder) JSON(v interface{}) *ResponseBuilder {
+	b.Header("Content-Type", "application/json")
+	b.resp.Body = v
+	b.resp.IsBase64Encoded = false
+	return b
+}
+
+func (b *ResponseBuilder) XML(v interface{}) *ResponseBuilder {
+	out, err := xml.Marshal(v)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	return b.Text("application/xml; charset=utf-8", xml.Header+string(out))
+}
+
+func (b *ResponseBuilder) HTML(html string) *ResponseBuilder {
+	return b.Text("text/html; charset=utf-8", html)
+}
+
+func (b *ResponseBuilder) Text(contentType, text string) *ResponseBuilder {
+	b.Header("Content-Type", contentType)
+	b.resp.Body = text
+	b.resp.IsBase64Encoded = false
+	return b
+}
+
+func (b *ResponseBuilder) Bytes(contentType string, data []byte) *ResponseBuilder {
+	b.Header("Content-Type", contentType)
+	b.resp.Body = base64.StdEncoding.EncodeToString(data)
+	b.resp.IsBase64Encoded = true
+	return b
+}
+
+func (b *ResponseBuilder) Body(v interface{}) *ResponseBuilder {
+	b.resp.Body = v
+	return b
+}
+
+// Build returns the response. If any step failed, a 500 response is
+// returned instead.
+func (b *ResponseBuilder) Build() Response {
+	if b.err != nil {
+		return Response{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       map[string]string{"error": "Internal Server Error"},
+		}
+	}
+	return b.resp
+}
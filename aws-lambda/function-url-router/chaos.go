@@ -0,0 +1,145 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"math/rand"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+)
+
+// ChaosConfig injects latency and faults into local requests so client retry
+// and timeout behavior can be exercised before deployment. Rates are
+// probabilities between 0 and 1.
+type ChaosConfig struct {
+	// Routes limits the config to request paths matching one of these
+	// path.Match patterns. Empty matches every path.
+	Routes []string
+	// Latency delays each request, plus up to Jitter more at random.
+	Latency time.Duration
+	Jitter  time.Duration
+	// ErrorRate answers with a random 500, 502, 503 or 504 instead of
+	// calling the router.
+	ErrorRate float64
+	// ResetRate closes the connection without writing a response.
+	ResetRate float64
+	// TruncateRate sends the full Content-Length but only half the body,
+	// then closes the connection.
+	TruncateRate float64
+}
+
+var chaosStatusCodes = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// WithChaos enables fault injection in the local server. The first config
+// whose Routes match the request path applies.
+func WithChaos(configs ...ChaosConfig) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.chaos = append(c.chaos, configs...)
+	}
+}
+
+func (c ChaosConfig) matches(p string) bool {
+	if len(c.Routes) == 0 {
+		return true
+	}
+	for _, pattern := range c.Routes {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func chaosHandler(configs []ChaosConfig, next http.Handler, logger *log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var config *ChaosConfig
+		for i := range configs {
+			if configs[i].matches(r.URL.Path) {
+				config = &configs[i]
+				break
+			}
+		}
+		if config == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if delay := config.Latency + randomDuration(config.Jitter); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			}
+		}
+
+		switch {
+		case chance(config.ResetRate):
+			logger.Printf("Chaos: resetting connection for %s %s", r.Method, r.URL.Path)
+			panic(http.ErrAbortHandler)
+		case chance(config.ErrorRate):
+			status := chaosStatusCodes[rand.Intn(len(chaosStatusCodes))]
+			logger.Printf("Chaos: responding %d to %s %s", status, r.Method, r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": http.StatusText(status)})
+		case chance(config.TruncateRate):
+			logger.Printf("Chaos: truncating response body for %s %s", r.Method, r.URL.Path)
+			tw := &truncatingWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(tw, r)
+			tw.truncate()
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// truncatingWriter buffers the response so it can advertise the full length
+// and then stop halfway through the body.
+type truncatingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (tw *truncatingWriter) WriteHeader(status int) {
+	tw.status = status
+}
+
+func (tw *truncatingWriter) Write(p []byte) (int, error) {
+	return tw.body.Write(p)
+}
+
+// Flush is a no-op so streamed responses are buffered like everything else.
+func (tw *truncatingWriter) Flush() {}
+
+func (tw *truncatingWriter) truncate() {
+	body := tw.body.Bytes()
+	tw.ResponseWriter.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	tw.ResponseWriter.WriteHeader(tw.status)
+	tw.ResponseWriter.Write(body[:len(body)/2])
+	if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+	panic(http.ErrAbortHandler)
+}
+
+func chance(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
+
+func randomDuration(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
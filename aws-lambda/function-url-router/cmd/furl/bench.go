@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// benchResult is one benchmark's numbers. Across -count runs the fastest
+// time is kept, which is the least affected by noise.
+type benchResult struct {
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+}
+
+// runBench runs a package's benchmarks and compares them with a stored
+// baseline, failing when one got slower by more than -threshold percent or
+// allocates more than before. Allocation counts don't depend on the machine,
+// so they make a reliable gate in CI; time comparisons are only meaningful
+// against a baseline recorded on similar hardware.
+func runBench(args []string) error {
+	fset := flag.NewFlagSet("bench", flag.ExitOnError)
+	baselinePath := fset.String("baseline", "testdata/bench.json", "baseline file, relative to the package")
+	update := fset.Bool("update", false, "write the results to the baseline instead of comparing")
+	bench := fset.String("bench", ".", "benchmarks to run, as for go test -bench")
+	count := fset.Int("count", 5, "runs of each benchmark")
+	threshold := fset.Float64("threshold", 20, "allowed slowdown in percent; 0 disables time comparisons")
+	fset.Parse(args)
+	pkg := "."
+	if fset.NArg() > 0 {
+		pkg = fset.Arg(0)
+	}
+
+	cmd := exec.Command("go", "test", "-run", "^$", "-bench", *bench, "-benchmem", "-count", strconv.Itoa(*count), ".")
+	cmd.Dir = pkg
+	cmd.Stderr = os.Stderr
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		os.Stdout.Write(out.Bytes())
+		return err
+	}
+	results, err := parseBench(&out)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no benchmarks matched %q", *bench)
+	}
+
+	path := *baselinePath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(pkg, path)
+	}
+	if *update {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %d benchmarks to %s\n", len(results), path)
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w (run with -update to record a baseline)", err)
+	}
+	var baseline map[string]benchResult
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if regressions := compareBench(os.Stdout, baseline, results, *threshold); regressions > 0 {
+		return fmt.Errorf("%d benchmarks regressed", regressions)
+	}
+	return nil
+}
+
+var benchLine = regexp.MustCompile(`^(Benchmark\S+?)(?:-\d+)?\s+\d+\s+([\d.]+) ns/op(?:\s+(\d+) B/op\s+(\d+) allocs/op)?`)
+
+func parseBench(r io.Reader) (map[string]benchResult, error) {
+	results := make(map[string]benchResult)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m := benchLine.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		ns, _ := strconv.ParseFloat(m[2], 64)
+		bytesPerOp, _ := strconv.ParseInt(m[3], 10, 64)
+		allocs, _ := strconv.ParseInt(m[4], 10, 64)
+		result, seen := results[m[1]]
+		if !seen || ns < result.NsPerOp {
+			result.NsPerOp = ns
+		}
+		result.BytesPerOp = bytesPerOp
+		result.AllocsPerOp = allocs
+		results[m[1]] = result
+	}
+	return results, scanner.Err()
+}
+
+// compareBench prints each benchmark next to its baseline and returns how
+// many regressed. Benchmarks missing from either side are reported but
+// don't fail the comparison.
+func compareBench(w io.Writer, baseline, results map[string]benchResult, threshold float64) int {
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	regressions := 0
+	for _, name := range names {
+		got := results[name]
+		want, ok := baseline[name]
+		if !ok {
+			fmt.Fprintf(w, "new   %s: %.1f ns/op, %d allocs/op\n", name, got.NsPerOp, got.AllocsPerOp)
+			continue
+		}
+		var problems []string
+		if got.AllocsPerOp > want.AllocsPerOp {
+			problems = append(problems, fmt.Sprintf("allocs/op %d -> %d", want.AllocsPerOp, got.AllocsPerOp))
+		}
+		if change := 100 * (got.NsPerOp - want.NsPerOp) / want.NsPerOp; threshold > 0 && change > threshold {
+			problems = append(problems, fmt.Sprintf("ns/op %.1f -> %.1f (+%.0f%%)", want.NsPerOp, got.NsPerOp, change))
+		}
+		if len(problems) > 0 {
+			regressions++
+			fmt.Fprintf(w, "FAIL  %s: %s\n", name, strings.Join(problems, ", "))
+			continue
+		}
+		fmt.Fprintf(w, "ok    %s: %.1f ns/op (baseline %.1f), %d allocs/op\n", name, got.NsPerOp, want.NsPerOp, got.AllocsPerOp)
+	}
+	var gone []string
+	for name := range baseline {
+		if _, ok := results[name]; !ok {
+			gone = append(gone, name)
+		}
+	}
+	sort.Strings(gone)
+	for _, name := range gone {
+		fmt.Fprintf(w, "gone  %s\n", name)
+	}
+	return regressions
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// runDev keeps a reverse proxy listening on -addr and runs the app, built
+// from package, on a private port behind it. When a watched file changes the
+// app is rebuilt and started next to the running one; traffic switches over
+// once the new process accepts connections, so the port never goes away and a
+// failed build leaves the previous version serving. The app must listen on
+// $PORT, as router.Start does. The app gets the variables from .env (and
+// .env.<profile> with -profile) plus those the Lambda runtime sets.
+func runDev(args []string) error {
+	fset := flag.NewFlagSet("dev", flag.ExitOnError)
+	addr := fset.String("addr", ":8080", "address the proxy listens on")
+	dir := fset.String("dir", ".", "directory to watch")
+	interval := fset.Duration("interval", 500*time.Millisecond, "how often to poll for changes")
+	profile := fset.String("profile", "", "also load .env.<profile> from -dir")
+	fset.Parse(args)
+	pkg := "."
+	if fset.NArg() > 0 {
+		pkg = fset.Arg(0)
+	}
+
+	logger := log.New(os.Stdout, "FURL: ", log.Ldate|log.Ltime)
+	env := router.EnvConfig{Dir: *dir, Profile: *profile}
+	dev := &devServer{pkg: pkg, env: env, logger: logger, ready: make(chan struct{})}
+	defer dev.stop()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	server := &http.Server{Addr: *addr, Handler: dev}
+	go func() {
+		logger.Printf("Proxying %s to %s", *addr, pkg)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Printf("Proxy error: %v", err)
+			cancel()
+		}
+	}()
+
+	dev.reload()
+	last := snapshot(*dir)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, done := context.WithTimeout(context.Background(), 5*time.Second)
+			defer done()
+			return server.Shutdown(shutdownCtx)
+		case <-ticker.C:
+			current := snapshot(*dir)
+			if changed(last, current) {
+				last = current
+				dev.reload()
+			}
+		}
+	}
+}
+
+type devServer struct {
+	pkg    string
+	env    router.EnvConfig
+	logger *log.Logger
+
+	mu     sync.Mutex
+	proxy  *httputil.ReverseProxy
+	cmd    *exec.Cmd
+	binary string
+	ready  chan struct{}
+	once   sync.Once
+}
+
+func (d *devServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-d.ready:
+	case <-r.Context().Done():
+		return
+	}
+	d.mu.Lock()
+	proxy := d.proxy
+	d.mu.Unlock()
+	if proxy == nil {
+		http.Error(w, "furl: app failed to build", http.StatusBadGateway)
+		return
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+func (d *devServer) reload() {
+	defer d.once.Do(func() { close(d.ready) })
+
+	start := time.Now()
+	binary := filepath.Join(os.TempDir(), "furl-"+strconv.FormatInt(start.UnixNano(), 36))
+	build := exec.Command("go", "build", "-o", binary, d.pkg)
+	build.Stdout, build.Stderr = os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		d.logger.Printf("Build failed, keeping the previous version: %v", err)
+		return
+	}
+
+	port, err := freePort()
+	if err != nil {
+		d.logger.Printf("Error finding a free port: %v", err)
+		os.Remove(binary)
+		return
+	}
+	cmd := exec.Command(binary)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	env, err := appEnv(d.env)
+	if err != nil {
+		d.logger.Printf("Error loading environment, keeping the previous version: %v", err)
+		os.Remove(binary)
+		return
+	}
+	cmd.Env = append(env, "PORT="+strconv.Itoa(port))
+	if err := cmd.Start(); err != nil {
+		d.logger.Printf("Error starting app: %v", err)
+		os.Remove(binary)
+		return
+	}
+	target := "127.0.0.1:" + strconv.Itoa(port)
+	if err := waitForListener(target, 10*time.Second); err != nil {
+		d.logger.Printf("App did not start listening on $PORT: %v", err)
+		stopProcess(cmd, binary)
+		return
+	}
+
+	d.mu.Lock()
+	oldCmd, oldBinary := d.cmd, d.binary
+	d.cmd, d.binary = cmd, binary
+	d.proxy = httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: target})
+	// Pass streamed responses through as they are written.
+	d.proxy.FlushInterval = -1
+	d.mu.Unlock()
+	stopProcess(oldCmd, oldBinary)
+	d.logger.Printf("Reloaded in %v", time.Since(start).Round(time.Millisecond))
+}
+
+func (d *devServer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stopProcess(d.cmd, d.binary)
+	d.cmd = nil
+}
+
+// appEnv drops the Runtime API address so the app runs its local server
+// even when furl itself is started from a Lambda-like shell, and adds the
+// .env files and Lambda runtime variables for config. Variables already
+// set in furl's environment win. The files are read on every reload.
+func appEnv(config router.EnvConfig) ([]string, error) {
+	vars, err := router.LambdaEnv(config)
+	if err != nil {
+		return nil, err
+	}
+	var env []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, "AWS_LAMBDA_RUNTIME_API=") || strings.HasPrefix(kv, "PORT=") {
+			continue
+		}
+		key, _, _ := strings.Cut(kv, "=")
+		delete(vars, key)
+		env = append(env, kv)
+	}
+	for k, v := range vars {
+		env = append(env, k+"="+v)
+	}
+	return env, nil
+}
+
+func stopProcess(cmd *exec.Cmd, binary string) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	cmd.Process.Signal(os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		cmd.Process.Kill()
+		<-done
+	}
+	os.Remove(binary)
+}
+
+func freePort() (int, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port, nil
+}
+
+func waitForListener(addr string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, 200*time.Millisecond)
+		if err == nil {
+			return conn.Close()
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for %s", addr)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func snapshot(root string) map[string]time.Time {
+	files := make(map[string]time.Time)
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(name, ".go") || name == "go.mod" || name == "go.sum" {
+			if info, err := d.Info(); err == nil {
+				files[path] = info.ModTime()
+			}
+		}
+		return nil
+	})
+	return files
+}
+
+func changed(before, after map[string]time.Time) bool {
+	if len(before) != len(after) {
+		return true
+	}
+	for path, mod := range after {
+		if prev, ok := before[path]; !ok || !prev.Equal(mod) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+type headerFlags []string
+
+func (h *headerFlags) String() string     { return strings.Join(*h, ", ") }
+func (h *headerFlags) Set(v string) error { *h = append(*h, v); return nil }
+
+// runInvoke builds a Function URL event for METHOD PATH and sends it either
+// to a local server or, with -function, straight to a deployed function
+// through the Lambda Invoke API.
+func runInvoke(args []string) error {
+	fset := flag.NewFlagSet("invoke", flag.ExitOnError)
+	var headers headerFlags
+	fset.Var(&headers, "H", `request header, "Name: value" (repeatable)`)
+	data := fset.String("d", "", "request body, or @file to read it from a file")
+	target := fset.String("target", "http://localhost:8080", "base URL of the local server")
+	function := fset.String("function", "", "name or ARN of a deployed function to invoke instead")
+	printEvent := fset.Bool("event", false, "print the generated event and exit")
+
+	// Allow flags after the positional arguments, as in
+	// furl invoke GET /users/42 -H "Accept: application/json".
+	var positional []string
+	for {
+		fset.Parse(args)
+		if fset.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fset.Arg(0))
+		args = fset.Args()[1:]
+	}
+	if len(positional) != 2 {
+		return fmt.Errorf("usage: furl invoke [flags] METHOD PATH")
+	}
+
+	body, err := readBody(*data)
+	if err != nil {
+		return err
+	}
+	event, err := buildEvent(strings.ToUpper(positional[0]), positional[1], headers, body)
+	if err != nil {
+		return err
+	}
+
+	if *printEvent {
+		return printJSON(os.Stdout, event)
+	}
+	ctx := context.Background()
+	if *function != "" {
+		return invokeFunction(ctx, *function, event)
+	}
+	return invokeLocal(ctx, *target, event)
+}
+
+func readBody(data string) ([]byte, error) {
+	if name, ok := strings.CutPrefix(data, "@"); ok {
+		if name == "-" {
+			return io.ReadAll(os.Stdin)
+		}
+		return os.ReadFile(name)
+	}
+	return []byte(data), nil
+}
+
+func buildEvent(method, target string, headers []string, body []byte) (events.LambdaFunctionURLRequest, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return events.LambdaFunctionURLRequest{}, err
+	}
+	if !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+	}
+
+	event := events.LambdaFunctionURLRequest{
+		Version:               "2.0",
+		RawPath:               u.Path,
+		RawQueryString:        u.RawQuery,
+		Headers:               make(map[string]string),
+		QueryStringParameters: make(map[string]string),
+	}
+	for k, v := range u.Query() {
+		event.QueryStringParameters[k] = strings.Join(v, ",")
+	}
+	for _, h := range headers {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return event, fmt.Errorf("invalid header %q", h)
+		}
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+		if name == "cookie" {
+			for _, c := range strings.Split(value, ";") {
+				if c = strings.TrimSpace(c); c != "" {
+					event.Cookies = append(event.Cookies, c)
+				}
+			}
+			continue
+		}
+		if existing, ok := event.Headers[name]; ok {
+			value = existing + "," + value
+		}
+		event.Headers[name] = value
+	}
+	if _, ok := event.Headers["host"]; !ok {
+		event.Headers["host"] = "localhost"
+	}
+
+	if len(body) > 0 {
+		if utf8.Valid(body) {
+			event.Body = string(body)
+		} else {
+			event.Body = base64.StdEncoding.EncodeToString(body)
+			event.IsBase64Encoded = true
+		}
+	}
+
+	id := make([]byte, 16)
+	rand.Read(id)
+	now := time.Now()
+	event.RequestContext = events.LambdaFunctionURLRequestContext{
+		AccountID:    "123456789012",
+		RequestID:    hex.EncodeToString(id),
+		APIID:        "furl",
+		DomainName:   event.Headers["host"],
+		DomainPrefix: "furl",
+		Time:         now.Format(time.RFC3339),
+		TimeEpoch:    now.UnixMilli(),
+		HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+			Method:    method,
+			Path:      u.Path,
+			Protocol:  "HTTP/1.1",
+			SourceIP:  "127.0.0.1",
+			UserAgent: event.Headers["user-agent"],
+		},
+	}
+	return event, nil
+}
+
+func invokeLocal(ctx context.Context, target string, event events.LambdaFunctionURLRequest) error {
+	base, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	req, err := router.NewHTTPRequest(ctx, event)
+	if err != nil {
+		return err
+	}
+	req.RequestURI = ""
+	req.URL.Scheme, req.URL.Host = base.Scheme, base.Host
+	req.Host = base.Host
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string, len(resp.Header))
+	for k, v := range resp.Header {
+		headers[k] = strings.Join(v, ", ")
+	}
+	printResponse(resp.StatusCode, headers, nil, body)
+	return nil
+}
+
+func invokeFunction(ctx context.Context, function string, event events.LambdaFunctionURLRequest) error {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	out, err := lambda.NewFromConfig(cfg).Invoke(ctx, &lambda.InvokeInput{
+		FunctionName: aws.String(function),
+		Payload:      payload,
+	})
+	if err != nil {
+		return err
+	}
+	if out.FunctionError != nil {
+		var buf bytes.Buffer
+		json.Indent(&buf, out.Payload, "", "  ")
+		return fmt.Errorf("function error (%s):\n%s", aws.ToString(out.FunctionError), buf.String())
+	}
+
+	var resp events.LambdaFunctionURLResponse
+	if err := json.Unmarshal(out.Payload, &resp); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	body := []byte(resp.Body)
+	if resp.IsBase64Encoded {
+		if body, err = base64.StdEncoding.DecodeString(resp.Body); err != nil {
+			return fmt.Errorf("decode body: %w", err)
+		}
+	}
+	printResponse(resp.StatusCode, resp.Headers, resp.Cookies, body)
+	return nil
+}
+
+func printResponse(status int, headers map[string]string, cookies []string, body []byte) {
+	fmt.Printf("%d %s\n", status, http.StatusText(status))
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%s: %s\n", http.CanonicalHeaderKey(k), headers[k])
+	}
+	for _, c := range cookies {
+		fmt.Printf("Set-Cookie: %s\n", c)
+	}
+	if len(body) == 0 {
+		return
+	}
+	fmt.Println()
+	var pretty bytes.Buffer
+	switch {
+	case json.Indent(&pretty, body, "", "  ") == nil:
+		fmt.Println(pretty.String())
+	case utf8.Valid(body):
+		fmt.Println(string(body))
+	default:
+		fmt.Printf("<%d bytes of binary data>\n", len(body))
+	}
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
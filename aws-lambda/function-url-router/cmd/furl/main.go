@@ -0,0 +1,50 @@
+// Command furl is a development tool for function-url-router apps.
+//
+//	furl dev [-addr :8080] [-dir .] [-profile name] [package]
+//	furl replay [-target http://localhost:8080] [-v] file|dir...
+//	furl invoke METHOD PATH [-H "Name: value"] [-d body|@file] [-target URL | -function NAME] [-event]
+//	furl bench [-baseline testdata/bench.json] [-update] [-bench regexp] [-count n] [-threshold percent] [package]
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: furl <command> [arguments]
+
+commands:
+  dev      rebuild and restart a router app whenever its Go files change
+  replay   send events saved by router.Record to a running local server
+  invoke   send a synthetic Function URL event to a local server or deployed function
+  bench    run benchmarks and compare them with a stored baseline
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "dev":
+		err = runDev(os.Args[2:])
+	case "replay":
+		err = runReplay(os.Args[2:])
+	case "invoke":
+		err = runInvoke(os.Args[2:])
+	case "bench":
+		err = runBench(os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "furl: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "furl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
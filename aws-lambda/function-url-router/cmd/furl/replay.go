@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// runReplay sends recorded events to a running local server, in file name
+// (and so recording) order.
+func runReplay(args []string) error {
+	fset := flag.NewFlagSet("replay", flag.ExitOnError)
+	target := fset.String("target", "http://localhost:8080", "base URL of the local server")
+	verbose := fset.Bool("v", false, "print response bodies")
+	fset.Parse(args)
+	if fset.NArg() == 0 {
+		return fmt.Errorf("no event files or directories given")
+	}
+
+	base, err := url.Parse(*target)
+	if err != nil {
+		return err
+	}
+	files, err := eventFiles(fset.Args())
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	for _, file := range files {
+		event, err := router.LoadEvent(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		req, err := router.NewHTTPRequest(context.Background(), event)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		req.RequestURI = ""
+		req.URL.Scheme, req.URL.Host = base.Scheme, base.Host
+		req.Host = base.Host
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		fmt.Printf("%s %s %s -> %d\n", filepath.Base(file), req.Method, req.URL.RequestURI(), resp.StatusCode)
+		if *verbose && len(body) > 0 {
+			fmt.Printf("%s\n", body)
+		}
+	}
+	return nil
+}
+
+func eventFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+				names = append(names, filepath.Join(path, e.Name()))
+			}
+		}
+		sort.Strings(names)
+		files = append(files, names...)
+	}
+	return files, nil
+}
@@ -0,0 +1,146 @@
+package router
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var ErrUnsupportedMediaType = errors.New("router: unsupported media type")
+
+// Codec encodes and decodes bodies of a single media type. Codecs registered
+// on a router are used by Render and Bind for requests it handles.
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// SetJSONCodec replaces encoding/json for every JSON body the router reads
+// or writes, e.g. with a jsoniter or sonic backed Codec.
+func (r *Router) SetJSONCodec(c Codec) {
+	r.jsonCodec = c
+}
+
+// RegisterCodec adds c for its content type, replacing an earlier codec for
+// the same type. Negotiate offers codecs in the order they were first
+// registered.
+func (r *Router) RegisterCodec(c Codec) {
+	if _, ok := r.codecs[c.ContentType()]; !ok {
+		r.codecOrder = append(r.codecOrder, c.ContentType())
+	}
+	r.codecs[c.ContentType()] = c
+}
+
+func (r *Router) codec(mediaType string) (Codec, bool) {
+	c, ok := r.codecs[mediaType]
+	return c, ok
+}
+
+func routerFromContext(ctx context.Context) *Router {
+	r, _ := ctx.Value(routerKey).(*Router)
+	return r
+}
+
+func jsonCodecFromContext(ctx context.Context) Codec {
+	if r := routerFromContext(ctx); r != nil {
+		return r.jsonCodec
+	}
+	return jsonCodec{}
+}
+
+// outputCodecFromContext returns the codec the router writes JSON response
+// bodies with, which indents them in dev mode.
+func outputCodecFromContext(ctx context.Context) Codec {
+	if r := routerFromContext(ctx); r != nil {
+		return r.outputCodec()
+	}
+	return jsonCodec{}
+}
+
+func codecFromContext(ctx context.Context, mediaType string) (Codec, bool) {
+	if r := routerFromContext(ctx); r != nil {
+		return r.codec(mediaType)
+	}
+	return nil, false
+}
+
+// Bind decodes the request body into v according to its Content-Type.
+// JSON and XML are always supported; other types need a registered codec.
+func Bind(ctx context.Context, req events.LambdaFunctionURLRequest, v interface{}) error {
+	mediaType := "application/json"
+	if ct := req.Headers["content-type"]; ct != "" {
+		parsed, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return ErrUnsupportedMediaType
+		}
+		mediaType = parsed
+	}
+
+	body, err := bodyBytes(ctx, req)
+	if err != nil {
+		return err
+	}
+	if c, ok := codecFromContext(ctx, mediaType); ok {
+		return c.Unmarshal(body, v)
+	}
+	switch mediaType {
+	case "application/json":
+		return jsonCodecFromContext(ctx).Unmarshal(body, v)
+	case "application/xml", "text/xml":
+		return xml.Unmarshal(body, v)
+	}
+	return ErrUnsupportedMediaType
+}
+
+func BindWith(ctx context.Context, c Codec, req events.LambdaFunctionURLRequest, v interface{}) error {
+	body, err := bodyBytes(ctx, req)
+	if err != nil {
+		return err
+	}
+	return c.Unmarshal(body, v)
+}
+
+// EncodeWith encodes v with c, base64-encoding the body unless the codec
+// produces text.
+func EncodeWith(c Codec, status int, v interface{}) Response {
+	out, err := c.Marshal(v)
+	if err != nil {
+		return Response{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       map[string]string{"error": "Internal Server Error"},
+		}
+	}
+	resp := Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": c.ContentType()},
+	}
+	if isTextContentType(c.ContentType()) {
+		resp.Body = string(out)
+	} else {
+		resp.Body = base64.StdEncoding.EncodeToString(out)
+		resp.IsBase64Encoded = true
+	}
+	return resp
+}
@@ -0,0 +1,33 @@
+package cbor
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/fxamacker/cbor/v2"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+const ContentType = "application/cbor"
+
+type Codec struct{}
+
+func (Codec) ContentType() string {
+	return ContentType
+}
+
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	return cbor.Marshal(v)
+}
+
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	return cbor.Unmarshal(data, v)
+}
+
+func Response(status int, v interface{}) router.Response {
+	return router.EncodeWith(Codec{}, status, v)
+}
+
+func Bind(ctx context.Context, req events.LambdaFunctionURLRequest, v interface{}) error {
+	return router.BindWith(ctx, Codec{}, req, v)
+}
@@ -0,0 +1,33 @@
+package msgpack
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const ContentType = "application/msgpack"
+
+type Codec struct{}
+
+func (Codec) ContentType() string {
+	return ContentType
+}
+
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+func Response(status int, v interface{}) router.Response {
+	return router.EncodeWith(Codec{}, status, v)
+}
+
+func Bind(ctx context.Context, req events.LambdaFunctionURLRequest, v interface{}) error {
+	return router.BindWith(ctx, Codec{}, req, v)
+}
@@ -0,0 +1,43 @@
+package protobuf
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+	"google.golang.org/protobuf/proto"
+)
+
+const ContentType = "application/x-protobuf"
+
+type Codec struct{}
+
+func (Codec) ContentType() string {
+	return ContentType
+}
+
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf: %T is not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// Response encodes m as a base64 protobuf body regardless of negotiation.
+func Response(status int, m proto.Message) router.Response {
+	return router.EncodeWith(Codec{}, status, m)
+}
+
+func Bind(ctx context.Context, req events.LambdaFunctionURLRequest, m proto.Message) error {
+	return router.BindWith(ctx, Codec{}, req, m)
+}
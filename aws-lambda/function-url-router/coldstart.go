@@ -0,0 +1,136 @@
+package router
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// warm is set by the first request the execution environment handles,
+	// which is the one that paid for the cold start.
+	warm atomic.Bool
+	// initStarted is when the environment started initializing, in Unix
+	// nanoseconds: process start, or the latest simulated cold start.
+	initStarted  atomic.Int64
+	initDuration atomic.Int64
+)
+
+func init() {
+	initStarted.Store(time.Now().UnixNano())
+}
+
+// IsColdStart reports whether the request handled with ctx is the first
+// one in its execution environment.
+func IsColdStart(ctx context.Context) bool {
+	c := requestFromContext(ctx)
+	return c != nil && c.coldStart
+}
+
+// InitDuration returns the time from the start of the execution environment
+// to its first request, which includes everything main ran before serving.
+// It is 0 until the first request.
+func InitDuration() time.Duration {
+	return time.Duration(initDuration.Load())
+}
+
+// beginInvocation reports whether the request starting at start is the
+// environment's first.
+func beginInvocation(start time.Time) bool {
+	if warm.Swap(true) {
+		return false
+	}
+	initDuration.Store(start.UnixNano() - initStarted.Load())
+	return true
+}
+
+// resetColdStart makes the next request a cold start again, for simulated
+// cold starts in the local server.
+func resetColdStart(start time.Time) {
+	initStarted.Store(start.UnixNano())
+	warm.Store(false)
+}
+
+// ColdStartConfig makes the local server discard its router and build a
+// new one with Init, the way Lambda starts a fresh execution environment.
+// Requests that arrive during a cold start wait for it, surfacing races in
+// lazily initialized clients.
+type ColdStartConfig struct {
+	// Init builds the router, running the same setup as main.
+	Init func() (*Router, error)
+	// Every triggers a cold start on the first request once this much time
+	// has passed since the previous cold start.
+	Every time.Duration
+	// EveryRequests triggers a cold start every n requests.
+	EveryRequests int
+	// Latency is added to each cold start on top of Init's own duration.
+	Latency time.Duration
+}
+
+// WithColdStarts enables cold start simulation. The router passed to
+// RunLocalServer serves until the first cold start.
+func WithColdStarts(config ColdStartConfig) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.coldStart = &config
+	}
+}
+
+type coldStartHandler struct {
+	config  ColdStartConfig
+	build   func(*Router) http.Handler
+	logger  *log.Logger
+	mu      sync.RWMutex
+	current http.Handler
+	started time.Time
+	served  int
+}
+
+func newColdStartHandler(config ColdStartConfig, build func(*Router) http.Handler, initial http.Handler, logger *log.Logger) *coldStartHandler {
+	return &coldStartHandler{
+		config:  config,
+		build:   build,
+		logger:  logger,
+		current: initial,
+		started: time.Now(),
+	}
+}
+
+func (h *coldStartHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	h.served++
+	if h.due() {
+		h.coldStart()
+	}
+	handler := h.current
+	h.mu.Unlock()
+	handler.ServeHTTP(w, r)
+}
+
+func (h *coldStartHandler) due() bool {
+	if h.config.Init == nil {
+		return false
+	}
+	if h.config.EveryRequests > 0 && h.served > h.config.EveryRequests {
+		return true
+	}
+	return h.config.Every > 0 && time.Since(h.started) >= h.config.Every
+}
+
+// coldStart runs with h.mu held so concurrent requests queue behind it.
+func (h *coldStartHandler) coldStart() {
+	start := time.Now()
+	time.Sleep(h.config.Latency)
+	router, err := h.config.Init()
+	if err != nil {
+		h.logger.Printf("Cold start failed, keeping the previous router: %v", err)
+	} else {
+		h.current = h.build(router)
+		resetColdStart(start)
+	}
+	h.started = time.Now()
+	h.served = 1
+	h.logger.Printf("Cold start: reinitialized router in %v", time.Since(start))
+}
@@ -0,0 +1,139 @@
+package router
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type Compressor func(io.Writer) io.WriteCloser
+
+type CompressionConfig struct {
+	MinSize      int
+	ContentTypes []string
+	Encodings    []string
+	Compressors  map[string]Compressor
+}
+
+var defaultCompressors = map[string]Compressor{
+	"gzip": func(w io.Writer) io.WriteCloser {
+		return gzip.NewWriter(w)
+	},
+	"deflate": func(w io.Writer) io.WriteCloser {
+		return zlib.NewWriter(w)
+	},
+}
+
+// SetCompression enables response compression. ContentTypes entries ending
+// in "/" match by prefix; when empty, textual types are compressed. Encodings
+// lists the preferred order and defaults to gzip then deflate.
+func (r *Router) SetCompression(config CompressionConfig) {
+	if len(config.Encodings) == 0 {
+		config.Encodings = []string{"gzip", "deflate"}
+	}
+	compressors := make(map[string]Compressor, len(defaultCompressors)+len(config.Compressors))
+	for name, c := range defaultCompressors {
+		compressors[name] = c
+	}
+	for name, c := range config.Compressors {
+		compressors[name] = c
+	}
+	config.Compressors = compressors
+	r.compression = &config
+}
+
+func (r *Router) compressResponse(req events.LambdaFunctionURLRequest, resp Response) Response {
+	config := r.compression
+	if config == nil || resp.Body == nil {
+		return resp
+	}
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return resp
+	}
+	if len(resp.HeaderValues("Content-Encoding")) > 0 {
+		return resp
+	}
+	if _, ok := resp.Body.(io.Reader); ok {
+		return resp
+	}
+
+	contentType := "application/json"
+	if values := resp.HeaderValues("Content-Type"); len(values) > 0 {
+		contentType = values[0]
+	}
+	if !config.compressible(contentType) {
+		return resp
+	}
+
+	encoding := config.negotiate(req.Headers["accept-encoding"])
+	if encoding == "" {
+		return resp
+	}
+
+	body, err := encodeBody(r.outputCodec(), resp.Body)
+	if err == nil && resp.IsBase64Encoded {
+		body, err = base64.StdEncoding.DecodeString(string(body))
+	}
+	if err != nil || len(body) < config.MinSize {
+		return resp
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	zw := config.Compressors[encoding](buf)
+	if _, err := zw.Write(body); err != nil {
+		return resp
+	}
+	if err := zw.Close(); err != nil {
+		return resp
+	}
+
+	headers := make(map[string]string, len(resp.Headers)+3)
+	for k, v := range resp.Headers {
+		headers[k] = v
+	}
+	if _, ok := headers["Content-Type"]; !ok {
+		headers["Content-Type"] = contentType
+	}
+	headers["Content-Encoding"] = encoding
+	if vary := headers["Vary"]; vary != "" {
+		headers["Vary"] = vary + ", Accept-Encoding"
+	} else {
+		headers["Vary"] = "Accept-Encoding"
+	}
+	resp.Headers = headers
+	resp.Body = base64.StdEncoding.EncodeToString(buf.Bytes())
+	resp.IsBase64Encoded = true
+	return resp
+}
+
+func (c *CompressionConfig) compressible(contentType string) bool {
+	if len(c.ContentTypes) == 0 {
+		return isCompressible(contentType)
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, allowed := range c.ContentTypes {
+		if strings.HasSuffix(allowed, "/") && strings.HasPrefix(mediaType, allowed) {
+			return true
+		}
+		if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CompressionConfig) negotiate(acceptEncoding string) string {
+	for _, encoding := range c.Encodings {
+		if _, ok := c.Compressors[encoding]; ok && acceptsEncoding(acceptEncoding, encoding) {
+			return encoding
+		}
+	}
+	return ""
+}
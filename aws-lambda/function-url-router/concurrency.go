@@ -0,0 +1,36 @@
+package router
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// WithConcurrencyLimit caps in-flight requests at max, like reserved
+// concurrency on a deployed function. Requests beyond the cap get status,
+// 429 when zero, without reaching the router.
+func WithConcurrencyLimit(max int, status int) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.concurrency = max
+		c.throttleStatus = status
+	}
+}
+
+func concurrencyHandler(max, status int, next http.Handler, logger *log.Logger) http.Handler {
+	if status == 0 {
+		status = http.StatusTooManyRequests
+	}
+	slots := make(chan struct{}, max)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		default:
+			logger.Printf("Throttled %s %s: %d requests in flight", r.Method, r.URL.Path, max)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": http.StatusText(status)})
+		}
+	})
+}
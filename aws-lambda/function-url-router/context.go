@@ -0,0 +1,51 @@
+package router
+
+import "context"
+
+type contextKey int
+
+const (
+	negotiatedTypeKey contextKey = iota
+	routerKey
+	panicKey
+	apiGatewayV2RequestKey
+	apiGatewayProxyRequestKey
+	albRequestKey
+	appSyncEventKey
+	edgeRequestKey
+	localAuthorizerKey
+	jwtClaimsKey
+	requestKey
+	spilledBodyKey
+)
+
+// requestContext holds the values HandleRequest sets for every request, so
+// they cost one allocation rather than a context.WithValue layer each. It
+// isn't pooled: handlers may keep the context after returning, e.g. in
+// goroutines.
+type requestContext struct {
+	context.Context
+	router        *Router
+	trace         TraceContext
+	traced        bool
+	coldStart     bool
+	correlationID string
+	route         string
+}
+
+func (c *requestContext) Value(key interface{}) interface{} {
+	switch key {
+	case requestKey:
+		return c
+	case routerKey:
+		return c.router
+	}
+	return c.Context.Value(key)
+}
+
+// requestFromContext returns the innermost request handled by a Router
+// with ctx, or nil.
+func requestFromContext(ctx context.Context) *requestContext {
+	c, _ := ctx.Value(requestKey).(*requestContext)
+	return c
+}
@@ -0,0 +1,73 @@
+package router
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// CorrelationHeader carries the correlation ID on outgoing calls.
+const CorrelationHeader = "X-Correlation-Id"
+
+// CorrelationID returns the ID that ties the request handled with ctx to the
+// calls it makes: the caller's X-Correlation-Id or X-Request-Id header when
+// present, otherwise the Lambda request ID.
+func CorrelationID(ctx context.Context) string {
+	if c := requestFromContext(ctx); c != nil {
+		return c.correlationID
+	}
+	return ""
+}
+
+func requestCorrelationID(ctx context.Context, req events.LambdaFunctionURLRequest) string {
+	if id := req.Headers["x-correlation-id"]; id != "" {
+		return id
+	}
+	if id := req.Headers["x-request-id"]; id != "" {
+		return id
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok && lc.AwsRequestID != "" {
+		return lc.AwsRequestID
+	}
+	return req.RequestContext.RequestID
+}
+
+type correlationTransport struct {
+	base http.RoundTripper
+}
+
+// Transport wraps base, or http.DefaultTransport when nil, so requests made
+// with a handler's context carry its correlation ID and X-Ray trace
+// headers. Headers already set on a request are kept:
+//
+//	client := &http.Client{Transport: router.Transport(nil)}
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+//	resp, err := client.Do(req)
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return correlationTransport{base: base}
+}
+
+func (t correlationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	id := CorrelationID(ctx)
+	trace, traced := Trace(ctx)
+	setID := id != "" && req.Header.Get(CorrelationHeader) == ""
+	setTrace := traced && req.Header.Get("X-Amzn-Trace-Id") == ""
+	if !setID && !setTrace {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(ctx)
+	if setID {
+		req.Header.Set(CorrelationHeader, id)
+	}
+	if setTrace {
+		req.Header.Set("X-Amzn-Trace-Id", trace.Header())
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type CORSConfig struct {
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           int
+}
+
+// SetCORS answers preflight requests for registered paths and adds CORS
+// headers to every response. AllowOrigins defaults to "*"; when
+// AllowMethods is empty, preflights allow the methods registered for the
+// path. The same config can be rendered into Function URL settings by the
+// iac package.
+func (r *Router) SetCORS(config CORSConfig) {
+	if len(config.AllowOrigins) == 0 {
+		config.AllowOrigins = []string{"*"}
+	}
+	r.cors = &config
+}
+
+// CORS returns the config set with SetCORS, or nil.
+func (r *Router) CORS() *CORSConfig {
+	return r.cors
+}
+
+func (r *Router) isPreflight(req events.LambdaFunctionURLRequest, path string) bool {
+	return r.cors != nil &&
+		req.RequestContext.HTTP.Method == http.MethodOptions &&
+		req.Headers["access-control-request-method"] != "" &&
+		r.hasRoute(path)
+}
+
+func (r *Router) preflightResponse(req events.LambdaFunctionURLRequest, path string) Response {
+	config := r.cors
+	methods := config.AllowMethods
+	if len(methods) == 0 {
+		for method := range r.routing().routes[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+	}
+	headers := map[string]string{
+		"Access-Control-Allow-Methods": strings.Join(methods, ", "),
+	}
+	if len(config.AllowHeaders) > 0 {
+		headers["Access-Control-Allow-Headers"] = strings.Join(config.AllowHeaders, ", ")
+	} else if requested := req.Headers["access-control-request-headers"]; requested != "" {
+		headers["Access-Control-Allow-Headers"] = requested
+	}
+	if config.MaxAge > 0 {
+		headers["Access-Control-Max-Age"] = strconv.Itoa(config.MaxAge)
+	}
+	return Response{StatusCode: http.StatusNoContent, Headers: headers}
+}
+
+func (r *Router) corsResponse(req events.LambdaFunctionURLRequest, resp Response) Response {
+	config := r.cors
+	origin := req.Headers["origin"]
+	if config == nil || origin == "" {
+		return resp
+	}
+	allowed := ""
+	for _, o := range config.AllowOrigins {
+		if o == "*" {
+			allowed = "*"
+			if config.AllowCredentials {
+				allowed = origin
+			}
+			break
+		}
+		if strings.EqualFold(o, origin) {
+			allowed = origin
+			break
+		}
+	}
+	if allowed == "" {
+		return resp
+	}
+
+	headers := make(map[string]string, len(resp.Headers)+4)
+	for k, v := range resp.Headers {
+		headers[k] = v
+	}
+	headers["Access-Control-Allow-Origin"] = allowed
+	if allowed != "*" {
+		resp.AddHeader("Vary", "Origin")
+	}
+	if config.AllowCredentials {
+		headers["Access-Control-Allow-Credentials"] = "true"
+	}
+	if len(config.ExposeHeaders) > 0 {
+		headers["Access-Control-Expose-Headers"] = strings.Join(config.ExposeHeaders, ", ")
+	}
+	resp.Headers = headers
+	return resp
+}
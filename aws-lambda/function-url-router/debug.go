@@ -0,0 +1,136 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var ErrDebugAuthRequired = errors.New("router: DebugConfig.Auth is required")
+
+type DebugConfig struct {
+	// Path defaults to "/_debug".
+	Path string
+	// Auth guards the endpoint, e.g. JWT or RequireAuthorizer with an admin
+	// policy. It is required so the endpoint is never public by accident.
+	Auth MiddlewareFunc
+	// Samples is how many recent errors are kept. Defaults to 20.
+	Samples int
+}
+
+// ErrorSample is a request that failed with a 5xx response or a panic.
+type ErrorSample struct {
+	Time      time.Time `json:"time"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+type errorSamples struct {
+	mu      sync.Mutex
+	samples []ErrorSample
+	next    int
+	full    bool
+}
+
+func (s *errorSamples) add(sample ErrorSample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.samples[s.next] = sample
+	s.next = (s.next + 1) % len(s.samples)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// recent returns the samples newest first.
+func (s *errorSamples) recent() []ErrorSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.next
+	if s.full {
+		n = len(s.samples)
+	}
+	out := make([]ErrorSample, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, s.samples[(s.next-i+len(s.samples))%len(s.samples)])
+	}
+	return out
+}
+
+// EnableDebug adds a GET endpoint, behind config.Auth, that reports the
+// route table, the middleware chain, build information and the most recent
+// failed requests. Nothing is registered or sampled unless it is called.
+func (r *Router) EnableDebug(config DebugConfig) error {
+	if config.Auth == nil {
+		return ErrDebugAuthRequired
+	}
+	if config.Path == "" {
+		config.Path = "/_debug"
+	}
+	if config.Samples <= 0 {
+		config.Samples = 20
+	}
+	samples := &errorSamples{samples: make([]ErrorSample, config.Samples)}
+
+	err := r.AddRoute(http.MethodGet, config.Path, config.Auth(HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		return Response{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "application/json", "Cache-Control": "no-store"},
+			Body: map[string]interface{}{
+				"routes":           r.Routes(),
+				"middleware":       r.middlewareNames(),
+				"build":            buildInfo(),
+				"init_duration_ms": float64(InitDuration()) / float64(time.Millisecond),
+				"recent_errors":    samples.recent(),
+			},
+		}
+	})))
+	if err != nil {
+		return err
+	}
+	r.errorSamples = samples
+	return nil
+}
+
+func (r *Router) sampleError(ctx context.Context, req events.LambdaFunctionURLRequest, resp Response, err error) {
+	if r.errorSamples == nil || (err == nil && resp.StatusCode < http.StatusInternalServerError) {
+		return
+	}
+	sample := ErrorSample{
+		Time:      time.Now(),
+		Method:    req.RequestContext.HTTP.Method,
+		Path:      req.RequestContext.HTTP.Path,
+		Status:    resp.StatusCode,
+		RequestID: requestID(ctx, req),
+	}
+	if err != nil {
+		sample.Error = err.Error()
+	}
+	r.errorSamples.add(sample)
+}
+
+func buildInfo() map[string]interface{} {
+	info := map[string]interface{}{"go_version": runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info["path"] = bi.Main.Path
+	info["version"] = bi.Main.Version
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision", "vcs.time", "vcs.modified", "GOARCH", "GOOS":
+			info[s.Key] = s.Value
+		}
+	}
+	return info
+}
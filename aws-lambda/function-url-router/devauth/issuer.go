@@ -0,0 +1,163 @@
+package devauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+const (
+	defaultIssuer = "http://localhost/devauth"
+	defaultTTL    = time.Hour
+)
+
+type Config struct {
+	// Issuer is the iss claim of minted tokens. Defaults to
+	// http://localhost/devauth.
+	Issuer string
+	// Audience is the aud claim of minted tokens when the claims don't set
+	// one.
+	Audience string
+	// TTL defaults to one hour.
+	TTL time.Duration
+}
+
+// Issuer mints RS256 tokens with a key generated at startup. Pass it as
+// router.JWTConfig.DevKeys so the JWT middleware trusts its tokens in dev
+// mode only.
+type Issuer struct {
+	config Config
+	kid    string
+	key    *rsa.PrivateKey
+}
+
+func NewIssuer(config Config) (*Issuer, error) {
+	if config.Issuer == "" {
+		config.Issuer = defaultIssuer
+	}
+	if config.TTL == 0 {
+		config.TTL = defaultTTL
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &Issuer{
+		config: config,
+		kid:    fmt.Sprintf("devauth-%d", time.Now().Unix()),
+		key:    key,
+	}, nil
+}
+
+func (i *Issuer) PublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if kid != i.kid {
+		return nil, router.ErrUnknownKey
+	}
+	return &i.key.PublicKey, nil
+}
+
+// JWKS returns the key set document for the issuer's signing key.
+func (i *Issuer) JWKS() router.JWKSet {
+	return router.JWKSet{Keys: []router.JWK{router.NewJWK(i.kid, &i.key.PublicKey)}}
+}
+
+// Mint signs claims, filling in iss, aud, iat and exp when missing.
+func (i *Issuer) Mint(claims map[string]interface{}) (string, error) {
+	now := time.Now()
+	out := map[string]interface{}{
+		"iss": i.config.Issuer,
+		"iat": now.Unix(),
+		"exp": now.Add(i.config.TTL).Unix(),
+	}
+	if i.config.Audience != "" {
+		out["aud"] = i.config.Audience
+	}
+	for k, v := range claims {
+		out[k] = v
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": i.kid})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Mount registers the OIDC discovery document, the JWKS and a token
+// endpoint that mints a token from the JSON claims in the request body.
+// Like the issuer's keys, the endpoints only answer in dev mode.
+func (i *Issuer) Mount(r *router.Router) error {
+	if err := r.AddRoute(http.MethodGet, "/.well-known/openid-configuration", devOnly(func(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+		base := "https://" + req.RequestContext.DomainName
+		if host := req.Headers["host"]; host != "" {
+			base = "http://" + host
+		}
+		return jsonResponse(http.StatusOK, map[string]interface{}{
+			"issuer":                                i.config.Issuer,
+			"jwks_uri":                              base + "/.well-known/jwks.json",
+			"token_endpoint":                        base + "/_auth/token",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})); err != nil {
+		return err
+	}
+	if err := r.AddRoute(http.MethodGet, "/.well-known/jwks.json", devOnly(func(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+		return jsonResponse(http.StatusOK, i.JWKS())
+	})); err != nil {
+		return err
+	}
+	return r.AddRoute(http.MethodPost, "/_auth/token", devOnly(func(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+		claims := map[string]interface{}{}
+		if req.Body != "" {
+			if err := router.Bind(ctx, req, &claims); err != nil {
+				return router.Error(ctx, http.StatusBadRequest, err)
+			}
+		}
+		token, err := i.Mint(claims)
+		if err != nil {
+			return router.Error(ctx, http.StatusInternalServerError, err)
+		}
+		return jsonResponse(http.StatusOK, map[string]interface{}{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_in":   int(i.config.TTL.Seconds()),
+		})
+	}))
+}
+
+func devOnly(fn router.HandlerFunc) router.Handler {
+	return router.HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+		if !router.IsDevMode(ctx) {
+			return jsonResponse(http.StatusNotFound, map[string]string{"error": "Not Found"})
+		}
+		return fn(ctx, req)
+	})
+}
+
+func jsonResponse(status int, body interface{}) router.Response {
+	return router.Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       body,
+	}
+}
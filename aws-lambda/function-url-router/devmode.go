@@ -0,0 +1,74 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SetDevMode toggles local development behavior: JSON responses are
+// indented, and 500 bodies produced by the default panic handler and by
+// Error include the underlying cause and stack trace. Keep it off in
+// production.
+func (r *Router) SetDevMode(dev bool) {
+	r.devMode = dev
+}
+
+func IsDevMode(ctx context.Context) bool {
+	r := routerFromContext(ctx)
+	return r != nil && r.devMode
+}
+
+// Error builds a JSON error response for err. Outside dev mode only the
+// status text is exposed.
+func Error(ctx context.Context, status int, err error) Response {
+	body := map[string]interface{}{"error": http.StatusText(status)}
+	if IsDevMode(ctx) && err != nil {
+		var causes []string
+		for e := err; e != nil; e = errors.Unwrap(e) {
+			causes = append(causes, e.Error())
+		}
+		body["cause"] = err.Error()
+		body["causes"] = causes
+	}
+	return Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       body,
+	}
+}
+
+func (r *Router) outputCodec() Codec {
+	if r.devMode {
+		return indentCodec{r.jsonCodec}
+	}
+	return r.jsonCodec
+}
+
+type indentCodec struct {
+	Codec
+}
+
+func (c indentCodec) Marshal(v interface{}) ([]byte, error) {
+	out, err := c.Codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, out, "", "  "); err != nil {
+		return out, nil
+	}
+	return buf.Bytes(), nil
+}
+
+func devPanicBody(report PanicReport) map[string]interface{} {
+	return map[string]interface{}{
+		"error": "Internal Server Error",
+		"panic": fmt.Sprint(report.Value),
+		"stack": strings.Split(strings.TrimSpace(string(report.Stack)), "\n"),
+	}
+}
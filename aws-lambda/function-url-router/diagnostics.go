@@ -0,0 +1,42 @@
+package router
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// LambdaDiagnostics describes the invocation and execution environment a
+// request runs in. Outside Lambda only Remaining may be set, from the
+// context deadline.
+type LambdaDiagnostics struct {
+	RequestID       string        `json:"request_id,omitempty"`
+	FunctionName    string        `json:"function_name,omitempty"`
+	FunctionVersion string        `json:"function_version,omitempty"`
+	MemoryLimitMB   int           `json:"memory_limit_mb,omitempty"`
+	LogGroup        string        `json:"log_group,omitempty"`
+	LogStream       string        `json:"log_stream,omitempty"`
+	Remaining       time.Duration `json:"remaining,omitempty"`
+}
+
+// Diagnostics returns the Lambda context of the request handled with ctx.
+// Remaining is the time left before the function times out, measured now,
+// so logging it shows how close slow requests came to the limit.
+func Diagnostics(ctx context.Context) LambdaDiagnostics {
+	var d LambdaDiagnostics
+	if deadline, ok := ctx.Deadline(); ok {
+		d.Remaining = time.Until(deadline)
+	}
+	lc, ok := lambdacontext.FromContext(ctx)
+	if !ok {
+		return d
+	}
+	d.RequestID = lc.AwsRequestID
+	d.FunctionName = lambdacontext.FunctionName
+	d.FunctionVersion = lambdacontext.FunctionVersion
+	d.MemoryLimitMB = lambdacontext.MemoryLimitInMB
+	d.LogGroup = lambdacontext.LogGroupName
+	d.LogStream = lambdacontext.LogStreamName
+	return d
+}
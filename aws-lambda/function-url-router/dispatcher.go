@@ -0,0 +1,204 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+type EventType string
+
+const (
+	EventUnknown      EventType = "unknown"
+	EventFunctionURL  EventType = "function-url"
+	EventAPIGatewayV2 EventType = "apigateway-v2"
+	EventAPIGatewayV1 EventType = "apigateway-v1"
+	EventALB          EventType = "alb"
+	EventSQS          EventType = "sqs"
+	EventSNS          EventType = "sns"
+	EventS3           EventType = "s3"
+	EventDynamoDB     EventType = "dynamodb"
+	EventKinesis      EventType = "kinesis"
+	EventEventBridge  EventType = "eventbridge"
+	EventScheduled    EventType = "scheduled"
+	EventAlexa        EventType = "alexa"
+)
+
+type eventProbe struct {
+	RouteKey       string `json:"routeKey"`
+	HTTPMethod     string `json:"httpMethod"`
+	RequestContext *struct {
+		ELB        *json.RawMessage `json:"elb"`
+		HTTP       *json.RawMessage `json:"http"`
+		DomainName string           `json:"domainName"`
+		Stage      string           `json:"stage"`
+	} `json:"requestContext"`
+	Records []struct {
+		EventSource string `json:"eventSource"`
+	} `json:"Records"`
+	Source     string   `json:"source"`
+	DetailType string   `json:"detail-type"`
+	Resources  []string `json:"resources"`
+	Schedule   string   `json:"schedule"`
+	Request    *struct {
+		Type string `json:"type"`
+	} `json:"request"`
+	Context *struct {
+		System *json.RawMessage `json:"System"`
+	} `json:"context"`
+}
+
+// DetectEventType inspects a raw invoke payload and reports which kind of
+// event it is.
+func DetectEventType(payload []byte) EventType {
+	var p eventProbe
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return EventUnknown
+	}
+
+	if rc := p.RequestContext; rc != nil {
+		switch {
+		case rc.ELB != nil:
+			return EventALB
+		case rc.HTTP != nil && strings.Contains(rc.DomainName, ".lambda-url."):
+			return EventFunctionURL
+		case rc.HTTP != nil && rc.Stage == "" && p.RouteKey == "$default":
+			return EventFunctionURL
+		case rc.HTTP != nil:
+			return EventAPIGatewayV2
+		case p.HTTPMethod != "":
+			return EventAPIGatewayV1
+		}
+	}
+
+	if len(p.Records) > 0 {
+		switch p.Records[0].EventSource {
+		case "aws:sqs":
+			return EventSQS
+		case "aws:sns":
+			return EventSNS
+		case "aws:s3":
+			return EventS3
+		case "aws:dynamodb":
+			return EventDynamoDB
+		case "aws:kinesis":
+			return EventKinesis
+		}
+	}
+
+	if p.Request != nil && p.Request.Type != "" && p.Context != nil && p.Context.System != nil {
+		return EventAlexa
+	}
+
+	if p.Source == "aws.events" && p.DetailType == "Scheduled Event" || p.Schedule != "" {
+		return EventScheduled
+	}
+	if p.Source != "" && p.DetailType != "" {
+		return EventEventBridge
+	}
+	return EventUnknown
+}
+
+type dispatchRoute struct {
+	match   func([]byte) bool
+	handler lambda.Handler
+}
+
+// Dispatcher lets a single Lambda serve several triggers. It implements
+// lambda.Handler, so it can be passed straight to lambda.Start.
+type Dispatcher struct {
+	handlers  map[EventType]lambda.Handler
+	custom    []dispatchRoute
+	schedules []scheduleRoute
+	fallback  lambda.Handler
+}
+
+type scheduleRoute struct {
+	key     string
+	handler lambda.Handler
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[EventType]lambda.Handler)}
+}
+
+// Handle registers handler for an event type. handler may be any function
+// signature accepted by lambda.Start.
+func (d *Dispatcher) Handle(eventType EventType, handler interface{}) {
+	d.handlers[eventType] = lambda.NewHandler(handler)
+}
+
+// HandleFunc registers handler for payloads accepted by match. Custom
+// matchers are consulted, in order, before the built-in event types.
+func (d *Dispatcher) HandleFunc(match func(payload []byte) bool, handler interface{}) {
+	d.custom = append(d.custom, dispatchRoute{match: match, handler: lambda.NewHandler(handler)})
+}
+
+// HandleHTTP routes every supported HTTP event shape to r.
+func (d *Dispatcher) HandleHTTP(r *Router) {
+	d.Handle(EventFunctionURL, r.LambdaHandler())
+	d.Handle(EventAPIGatewayV2, r.APIGatewayV2Handler())
+	d.Handle(EventAPIGatewayV1, r.APIGatewayProxyHandler())
+	d.Handle(EventALB, r.ALBHandler(ALBOptions{}))
+}
+
+// Schedule routes scheduled invocations to a named job. key is matched
+// against the rule name or ARN in the event's resources. EventBridge does not
+// include the schedule expression in the event, so an expression such as
+// "rate(5 minutes)" only matches when the rule passes it as constant input
+// in a "schedule" field. A key of "*" matches any scheduled invocation.
+func (d *Dispatcher) Schedule(key string, handler interface{}) {
+	d.schedules = append(d.schedules, scheduleRoute{key: key, handler: lambda.NewHandler(handler)})
+}
+
+func (d *Dispatcher) scheduled(payload []byte) (lambda.Handler, error) {
+	var p eventProbe
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, err
+	}
+	for _, route := range d.schedules {
+		if route.key == "*" || route.key == p.Schedule {
+			return route.handler, nil
+		}
+		for _, resource := range p.Resources {
+			if resource == route.key || strings.HasSuffix(resource, ":rule/"+route.key) {
+				return route.handler, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("router: no scheduled job for %v%s", p.Resources, p.Schedule)
+}
+
+func (d *Dispatcher) SetFallback(handler interface{}) {
+	d.fallback = lambda.NewHandler(handler)
+}
+
+func (d *Dispatcher) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	for _, route := range d.custom {
+		if route.match(payload) {
+			return route.handler.Invoke(ctx, payload)
+		}
+	}
+
+	eventType := DetectEventType(payload)
+	if eventType == EventScheduled && len(d.schedules) > 0 {
+		h, err := d.scheduled(payload)
+		if err != nil {
+			return nil, err
+		}
+		return h.Invoke(ctx, payload)
+	}
+	if h, ok := d.handlers[eventType]; ok {
+		return h.Invoke(ctx, payload)
+	}
+	if h, ok := d.handlers[EventEventBridge]; ok && eventType == EventScheduled {
+		return h.Invoke(ctx, payload)
+	}
+	if d.fallback != nil {
+		return d.fallback.Invoke(ctx, payload)
+	}
+	return nil, fmt.Errorf("router: no handler registered for %s event", eventType)
+}
@@ -0,0 +1,156 @@
+package dynamolocal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+	"github.com/rthing31/go/aws-lambda/function-url-router/awstrace"
+)
+
+// Endpoints probed, in order, when no endpoint is configured: DynamoDB Local
+// and LocalStack on their default ports.
+var defaultEndpoints = []string{"http://localhost:8000", "http://localhost:4566"}
+
+var ErrNoLocalEndpoint = errors.New("dynamolocal: no DynamoDB Local or LocalStack endpoint reachable")
+
+// Table describes a table used by a DynamoDB-backed component. Keys are
+// string attributes.
+type Table struct {
+	Name         string
+	PartitionKey string
+	SortKey      string
+	// TTLAttribute enables time to live on this number attribute.
+	TTLAttribute string
+}
+
+// NewClient returns a DynamoDB client for the AWS account when running in
+// Lambda. Elsewhere it targets a local endpoint, taken from
+// $AWS_ENDPOINT_URL_DYNAMODB or found by probing DynamoDB Local and
+// LocalStack, with dummy credentials, and creates any missing tables.
+func NewClient(ctx context.Context, tables ...Table) (*dynamodb.Client, error) {
+	if router.IsLambda() {
+		cfg, err := config.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		awstrace.Instrument(&cfg)
+		return dynamodb.NewFromConfig(cfg), nil
+	}
+
+	endpoint, err := localEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	client := dynamodb.New(dynamodb.Options{
+		Region:       region,
+		BaseEndpoint: aws.String(endpoint),
+		Credentials:  credentials.NewStaticCredentialsProvider("local", "local", ""),
+	})
+	if err := EnsureTables(ctx, client, tables...); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func localEndpoint() (string, error) {
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL_DYNAMODB"); endpoint != "" {
+		return endpoint, nil
+	}
+	for _, endpoint := range defaultEndpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			continue
+		}
+		conn, err := net.DialTimeout("tcp", u.Host, 500*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return endpoint, nil
+		}
+	}
+	return "", ErrNoLocalEndpoint
+}
+
+// EnsureTables creates the tables that don't exist yet with on-demand
+// billing and waits for them to become active.
+func EnsureTables(ctx context.Context, client *dynamodb.Client, tables ...Table) error {
+	for _, table := range tables {
+		_, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table.Name)})
+		var notFound *types.ResourceNotFoundException
+		if err == nil {
+			continue
+		}
+		if !errors.As(err, &notFound) {
+			return fmt.Errorf("dynamolocal: describe %s: %w", table.Name, err)
+		}
+		if err := createTable(ctx, client, table); err != nil {
+			return fmt.Errorf("dynamolocal: create %s: %w", table.Name, err)
+		}
+	}
+	return nil
+}
+
+func createTable(ctx context.Context, client *dynamodb.Client, table Table) error {
+	input := &dynamodb.CreateTableInput{
+		TableName:   aws.String(table.Name),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(table.PartitionKey), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(table.PartitionKey), KeyType: types.KeyTypeHash},
+		},
+	}
+	if table.SortKey != "" {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(table.SortKey), AttributeType: types.ScalarAttributeTypeS,
+		})
+		input.KeySchema = append(input.KeySchema, types.KeySchemaElement{
+			AttributeName: aws.String(table.SortKey), KeyType: types.KeyTypeRange,
+		})
+	}
+	if _, err := client.CreateTable(ctx, input); err != nil {
+		return err
+	}
+	waiter := dynamodb.NewTableExistsWaiter(client)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table.Name)}, time.Minute); err != nil {
+		return err
+	}
+	if table.TTLAttribute == "" {
+		return nil
+	}
+	_, err := client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(table.Name),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(table.TTLAttribute),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	return err
+}
+
+// HealthCheck reports whether table can be described with client, for
+// Router.EnableHealthChecks.
+func HealthCheck(client *dynamodb.Client, table string) router.HealthCheck {
+	return router.HealthCheck{
+		Name: "dynamodb:" + table,
+		Check: func(ctx context.Context) error {
+			_, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(table)})
+			return err
+		},
+	}
+}
@@ -0,0 +1,195 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type CloudFrontEvent struct {
+	Records []CloudFrontRecord `json:"Records"`
+}
+
+type CloudFrontRecord struct {
+	CF CloudFrontRecordData `json:"cf"`
+}
+
+type CloudFrontRecordData struct {
+	Config  CloudFrontConfig  `json:"config"`
+	Request CloudFrontRequest `json:"request"`
+}
+
+type CloudFrontConfig struct {
+	DistributionDomainName string `json:"distributionDomainName"`
+	DistributionID         string `json:"distributionId"`
+	EventType              string `json:"eventType"`
+	RequestID              string `json:"requestId"`
+}
+
+type CloudFrontHeader struct {
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value"`
+}
+
+type CloudFrontHeaders map[string][]CloudFrontHeader
+
+type CloudFrontRequest struct {
+	ClientIP    string                 `json:"clientIp"`
+	Headers     CloudFrontHeaders      `json:"headers"`
+	Method      string                 `json:"method"`
+	QueryString string                 `json:"querystring"`
+	URI         string                 `json:"uri"`
+	Body        *CloudFrontRequestBody `json:"body,omitempty"`
+	Origin      map[string]interface{} `json:"origin,omitempty"`
+}
+
+type CloudFrontRequestBody struct {
+	InputTruncated bool   `json:"inputTruncated"`
+	Action         string `json:"action"`
+	Encoding       string `json:"encoding"`
+	Data           string `json:"data"`
+}
+
+type CloudFrontResponse struct {
+	Status            string            `json:"status"`
+	StatusDescription string            `json:"statusDescription"`
+	Headers           CloudFrontHeaders `json:"headers,omitempty"`
+	Body              string            `json:"body,omitempty"`
+	BodyEncoding      string            `json:"bodyEncoding,omitempty"`
+}
+
+type EdgeOptions struct {
+	// ForwardUnmatched sends requests without a matching route to the origin
+	// instead of answering them with the not found handler.
+	ForwardUnmatched bool
+}
+
+// Forward returns a response telling the edge adapter to send the request,
+// including any changes made through EdgeRequest, on to the origin.
+func Forward() Response {
+	return Response{}
+}
+
+// EdgeRequest returns the CloudFront request being handled so that handlers
+// and middleware can modify it before it is forwarded.
+func EdgeRequest(ctx context.Context) *CloudFrontRequest {
+	req, _ := ctx.Value(edgeRequestKey).(*CloudFrontRequest)
+	return req
+}
+
+// EdgeHandler returns a Lambda@Edge handler for viewer-request and
+// origin-request events. It returns either a generated *CloudFrontResponse
+// or the forwarded *CloudFrontRequest. CloudFront Functions run JavaScript
+// only and cannot use this adapter.
+func (r *Router) EdgeHandler(opts EdgeOptions) func(context.Context, CloudFrontEvent) (interface{}, error) {
+	return func(ctx context.Context, event CloudFrontEvent) (interface{}, error) {
+		if len(event.Records) == 0 {
+			return nil, errors.New("router: CloudFront event has no records")
+		}
+		cf := event.Records[0].CF
+		cfReq := cf.Request
+		ctx = context.WithValue(ctx, edgeRequestKey, &cfReq)
+
+		req := edgeToFunctionURL(cf)
+		if opts.ForwardUnmatched && !r.hasRoute(req.RequestContext.HTTP.Path) {
+			return &cfReq, nil
+		}
+
+		resp := r.HandleRequest(ctx, req)
+		if resp.StatusCode == 0 {
+			return &cfReq, nil
+		}
+		return toCloudFrontResponse(r.toLambdaResponse(resp)), nil
+	}
+}
+
+func edgeToFunctionURL(cf CloudFrontRecordData) events.LambdaFunctionURLRequest {
+	req := cf.Request
+	headers := make(map[string]string, len(req.Headers))
+	var cookies []string
+	for name, values := range req.Headers {
+		name = strings.ToLower(name)
+		parts := make([]string, 0, len(values))
+		for _, h := range values {
+			parts = append(parts, h.Value)
+		}
+		if name == "cookie" {
+			for _, part := range parts {
+				for _, c := range strings.Split(part, ";") {
+					if c = strings.TrimSpace(c); c != "" {
+						cookies = append(cookies, c)
+					}
+				}
+			}
+			continue
+		}
+		headers[name] = strings.Join(parts, ",")
+	}
+
+	var body string
+	var isBase64 bool
+	if req.Body != nil {
+		body = req.Body.Data
+		isBase64 = req.Body.Encoding == "base64"
+	}
+
+	now := time.Now()
+	return events.LambdaFunctionURLRequest{
+		Version:               "2.0",
+		RawPath:               req.URI,
+		RawQueryString:        req.QueryString,
+		Cookies:               cookies,
+		Headers:               headers,
+		QueryStringParameters: queryParameters(req.QueryString),
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			RequestID:  cf.Config.RequestID,
+			DomainName: cf.Config.DistributionDomainName,
+			Time:       now.Format(time.RFC3339),
+			TimeEpoch:  now.UnixNano() / int64(time.Millisecond),
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:    req.Method,
+				Path:      req.URI,
+				Protocol:  "HTTP/1.1",
+				SourceIP:  req.ClientIP,
+				UserAgent: headers["user-agent"],
+			},
+		},
+		Body:            body,
+		IsBase64Encoded: isBase64,
+	}
+}
+
+func toCloudFrontResponse(resp events.LambdaFunctionURLResponse) *CloudFrontResponse {
+	out := &CloudFrontResponse{
+		Status:            strconv.Itoa(resp.StatusCode),
+		StatusDescription: http.StatusText(resp.StatusCode),
+		Headers:           make(CloudFrontHeaders, len(resp.Headers)+1),
+		Body:              resp.Body,
+		BodyEncoding:      "text",
+	}
+	if resp.IsBase64Encoded {
+		out.BodyEncoding = "base64"
+	}
+	for k, v := range resp.Headers {
+		out.Headers[strings.ToLower(k)] = []CloudFrontHeader{{Key: http.CanonicalHeaderKey(k), Value: v}}
+	}
+	for _, cookie := range resp.Cookies {
+		out.Headers["set-cookie"] = append(out.Headers["set-cookie"], CloudFrontHeader{Key: "Set-Cookie", Value: cookie})
+	}
+	return out
+}
+
+func queryParameters(rawQuery string) map[string]string {
+	values, _ := url.ParseQuery(rawQuery)
+	params := make(map[string]string, len(values))
+	for k, v := range values {
+		params[k] = strings.Join(v, ",")
+	}
+	return params
+}
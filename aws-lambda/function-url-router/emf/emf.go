@@ -0,0 +1,263 @@
+package emf
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// CloudWatch rejects directives with more metrics, or metrics with more
+// values, than this; larger batches are split across records.
+const maxPerRecord = 100
+
+type Unit string
+
+const (
+	None         Unit = "None"
+	Count        Unit = "Count"
+	Percent      Unit = "Percent"
+	Seconds      Unit = "Seconds"
+	Milliseconds Unit = "Milliseconds"
+	Microseconds Unit = "Microseconds"
+	Bytes        Unit = "Bytes"
+	Kilobytes    Unit = "Kilobytes"
+	Megabytes    Unit = "Megabytes"
+)
+
+type Config struct {
+	// Namespace defaults to $AWS_LAMBDA_FUNCTION_NAME, or
+	// "aws-embedded-metrics" outside Lambda.
+	Namespace string
+	// Dimensions are added to every metric.
+	Dimensions map[string]string
+	// Writer defaults to os.Stdout, which the Lambda runtime forwards to
+	// CloudWatch Logs.
+	Writer io.Writer
+}
+
+type metric struct {
+	unit   Unit
+	values []float64
+}
+
+// Logger buffers metrics, dimensions and properties and writes them as
+// embedded metric format records on Flush. It is safe for concurrent use.
+type Logger struct {
+	config     Config
+	mu         sync.Mutex
+	dimensions map[string]string
+	properties map[string]interface{}
+	metrics    map[string]*metric
+	order      []string
+}
+
+func New(config Config) *Logger {
+	if config.Namespace == "" {
+		config.Namespace = os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	}
+	if config.Namespace == "" {
+		config.Namespace = "aws-embedded-metrics"
+	}
+	if config.Writer == nil {
+		config.Writer = os.Stdout
+	}
+	l := &Logger{config: config}
+	l.reset()
+	return l
+}
+
+func (l *Logger) reset() {
+	l.dimensions = make(map[string]string, len(l.config.Dimensions))
+	for k, v := range l.config.Dimensions {
+		l.dimensions[k] = v
+	}
+	l.properties = make(map[string]interface{})
+	l.metrics = make(map[string]*metric)
+	l.order = nil
+}
+
+// Put adds a value for name. Repeated values within one invocation are
+// written as a list and aggregated by CloudWatch.
+func (l *Logger) Put(name string, value float64, unit Unit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m, ok := l.metrics[name]
+	if !ok {
+		m = &metric{unit: unit}
+		l.metrics[name] = m
+		l.order = append(l.order, name)
+	}
+	m.values = append(m.values, value)
+}
+
+// SetDimension adds a dimension to every metric in the next record.
+func (l *Logger) SetDimension(key, value string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.dimensions[key] = value
+}
+
+// SetProperty adds a field that is searchable in Logs Insights but is not a
+// metric or dimension, such as a request ID.
+func (l *Logger) SetProperty(key string, value interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.properties[key] = value
+}
+
+// Flush writes the buffered metrics and clears them, keeping only the
+// configured dimensions. It writes nothing when no metric was put.
+func (l *Logger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer l.reset()
+	if len(l.order) == 0 {
+		return nil
+	}
+
+	dimensionKeys := make([]string, 0, len(l.dimensions))
+	for k := range l.dimensions {
+		dimensionKeys = append(dimensionKeys, k)
+	}
+	sort.Strings(dimensionKeys)
+
+	var out []byte
+	for pending := l.order; len(pending) > 0; {
+		record := make(map[string]interface{}, len(l.properties)+len(l.dimensions)+maxPerRecord+1)
+		for k, v := range l.properties {
+			record[k] = v
+		}
+		for k, v := range l.dimensions {
+			record[k] = v
+		}
+
+		var definitions []map[string]string
+		var next []string
+		for _, name := range pending {
+			m := l.metrics[name]
+			if len(definitions) == maxPerRecord {
+				next = append(next, name)
+				continue
+			}
+			values := m.values
+			if len(values) > maxPerRecord {
+				values, m.values = values[:maxPerRecord], values[maxPerRecord:]
+				next = append(next, name)
+			}
+			definitions = append(definitions, map[string]string{"Name": name, "Unit": string(m.unit)})
+			if len(values) == 1 {
+				record[name] = values[0]
+			} else {
+				record[name] = values
+			}
+		}
+		pending = next
+
+		record["_aws"] = map[string]interface{}{
+			"Timestamp": time.Now().UnixMilli(),
+			"CloudWatchMetrics": []map[string]interface{}{{
+				"Namespace":  l.config.Namespace,
+				"Dimensions": [][]string{dimensionKeys},
+				"Metrics":    definitions,
+			}},
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		out = append(append(out, data...), '\n')
+	}
+	_, err := l.config.Writer.Write(out)
+	return err
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the Logger installed by Middleware, or a Logger with
+// the default config that is never flushed if there is none.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return New(Config{Writer: io.Discard})
+}
+
+// MiddlewareConfig configures Middleware. RequestMetrics adds Requests,
+// Errors (5xx responses) and Latency for every request, with Route and
+// ColdStart dimensions.
+type MiddlewareConfig struct {
+	Config
+	RequestMetrics bool
+}
+
+// Middleware gives each request a Logger, reachable with FromContext, and
+// flushes it when the handler returns, so every invocation writes its
+// metrics before the runtime freezes the process.
+func Middleware(config MiddlewareConfig) router.MiddlewareFunc {
+	return func(next router.Handler) router.Handler {
+		return router.HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+			l := New(config.Config)
+			start := time.Now()
+			resp := next.ServeHTTP(NewContext(ctx, l), req)
+
+			if config.RequestMetrics {
+				l.SetDimension("Route", req.RequestContext.HTTP.Method+" "+router.MatchedRoute(ctx))
+				l.SetDimension("ColdStart", strconv.FormatBool(router.IsColdStart(ctx)))
+				l.Put("Requests", 1, Count)
+				errors := 0.0
+				if resp.StatusCode >= 500 {
+					errors = 1
+				}
+				l.Put("Errors", errors, Count)
+				l.Put("Latency", milliseconds(time.Since(start)), Milliseconds)
+			}
+			requestID := req.RequestContext.RequestID
+			if lc, ok := lambdacontext.FromContext(ctx); ok {
+				requestID = lc.AwsRequestID
+			}
+			l.SetProperty("requestId", requestID)
+			if err := l.Flush(); err != nil {
+				router.LogError(ctx, "Error writing EMF record", err)
+			}
+			return resp
+		})
+	}
+}
+
+// LatencyReporter returns a router.LatencyConfig.Report function that
+// writes each route's LatencyP50, LatencyP95, LatencyP99 and LatencyMax, in
+// milliseconds, and LatencySamples with a Route dimension.
+func LatencyReporter(config Config) func(context.Context, []router.RouteLatency) {
+	return func(ctx context.Context, routes []router.RouteLatency) {
+		for _, route := range routes {
+			l := New(config)
+			l.SetDimension("Route", route.Method+" "+route.Route)
+			l.Put("LatencyP50", milliseconds(route.P50), Milliseconds)
+			l.Put("LatencyP95", milliseconds(route.P95), Milliseconds)
+			l.Put("LatencyP99", milliseconds(route.P99), Milliseconds)
+			l.Put("LatencyMax", milliseconds(route.Max), Milliseconds)
+			l.Put("LatencySamples", float64(route.Count), Count)
+			if err := l.Flush(); err != nil {
+				router.LogError(ctx, "Error writing EMF record", err)
+				return
+			}
+		}
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,167 @@
+package router
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// maxEnvSize is the limit Lambda puts on the combined size of a function's
+// environment variables.
+const maxEnvSize = 4096
+
+var envKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// reservedEnvKeys are set by the Lambda runtime and rejected in function
+// configuration.
+var reservedEnvKeys = map[string]bool{
+	"_HANDLER": true, "_X_AMZN_TRACE_ID": true, "AWS_DEFAULT_REGION": true, "AWS_REGION": true,
+	"AWS_EXECUTION_ENV": true, "AWS_LAMBDA_FUNCTION_NAME": true, "AWS_LAMBDA_FUNCTION_MEMORY_SIZE": true,
+	"AWS_LAMBDA_FUNCTION_VERSION": true, "AWS_LAMBDA_INITIALIZATION_TYPE": true, "AWS_LAMBDA_LOG_GROUP_NAME": true,
+	"AWS_LAMBDA_LOG_STREAM_NAME": true, "AWS_ACCESS_KEY": true, "AWS_ACCESS_KEY_ID": true,
+	"AWS_SECRET_ACCESS_KEY": true, "AWS_SESSION_TOKEN": true, "AWS_LAMBDA_RUNTIME_API": true,
+	"LAMBDA_TASK_ROOT": true, "LAMBDA_RUNTIME_DIR": true,
+}
+
+type EnvConfig struct {
+	// Dir holds .env and the profile files. Defaults to the current
+	// directory.
+	Dir string
+	// Profile also loads .env.<Profile>, whose values win over .env.
+	Profile string
+	// FunctionName defaults to "local".
+	FunctionName string
+	// Region defaults to $AWS_REGION, then us-east-1.
+	Region string
+	// MemorySize defaults to 128.
+	MemorySize int
+}
+
+// LambdaEnv reads the .env files for config and adds the variables the
+// Lambda runtime sets, such as AWS_LAMBDA_FUNCTION_NAME and AWS_REGION.
+// Files are checked against the rules Lambda applies to function
+// configuration: keys must be valid identifiers, runtime variables can't be
+// overridden and the total size is capped at 4 KB.
+func LambdaEnv(config EnvConfig) (map[string]string, error) {
+	if config.Dir == "" {
+		config.Dir = "."
+	}
+	files := []string{".env"}
+	if config.Profile != "" {
+		files = append(files, ".env."+config.Profile)
+	}
+
+	vars := make(map[string]string)
+	for _, name := range files {
+		path := filepath.Join(config.Dir, name)
+		fileVars, err := readEnvFile(path)
+		if errors.Is(err, fs.ErrNotExist) && name == ".env" {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fileVars {
+			if reservedEnvKeys[k] {
+				return nil, fmt.Errorf("%s: %s is reserved by the Lambda runtime", path, k)
+			}
+			vars[k] = v
+		}
+	}
+	size := 0
+	for k, v := range vars {
+		size += len(k) + len(v)
+	}
+	if size > maxEnvSize {
+		return nil, fmt.Errorf("environment variables take %d bytes, over Lambda's %d byte limit", size, maxEnvSize)
+	}
+
+	name := orDefault(config.FunctionName, "local")
+	region := orDefault(config.Region, orDefault(os.Getenv("AWS_REGION"), "us-east-1"))
+	memory := config.MemorySize
+	if memory == 0 {
+		memory = 128
+	}
+	taskRoot, _ := os.Getwd()
+	vars["AWS_LAMBDA_FUNCTION_NAME"] = name
+	vars["AWS_LAMBDA_FUNCTION_VERSION"] = "$LATEST"
+	vars["AWS_LAMBDA_FUNCTION_MEMORY_SIZE"] = strconv.Itoa(memory)
+	vars["AWS_LAMBDA_LOG_GROUP_NAME"] = "/aws/lambda/" + name
+	vars["AWS_LAMBDA_LOG_STREAM_NAME"] = "local"
+	vars["AWS_LAMBDA_INITIALIZATION_TYPE"] = "on-demand"
+	vars["AWS_REGION"] = region
+	vars["AWS_DEFAULT_REGION"] = region
+	vars["LAMBDA_TASK_ROOT"] = taskRoot
+	return vars, nil
+}
+
+// LoadEnv sets the variables from LambdaEnv in the process environment,
+// keeping any that are already set, and refreshes the lambdacontext
+// globals. Call it first thing in main when running locally.
+func LoadEnv(config EnvConfig) error {
+	vars, err := LambdaEnv(config)
+	if err != nil {
+		return err
+	}
+	for k, v := range vars {
+		if _, ok := os.LookupEnv(k); ok {
+			continue
+		}
+		if err := os.Setenv(k, v); err != nil {
+			return err
+		}
+	}
+	lambdacontext.FunctionName = os.Getenv("AWS_LAMBDA_FUNCTION_NAME")
+	lambdacontext.FunctionVersion = os.Getenv("AWS_LAMBDA_FUNCTION_VERSION")
+	lambdacontext.LogGroupName = os.Getenv("AWS_LAMBDA_LOG_GROUP_NAME")
+	lambdacontext.LogStreamName = os.Getenv("AWS_LAMBDA_LOG_STREAM_NAME")
+	lambdacontext.MemoryLimitInMB, _ = strconv.Atoi(os.Getenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE"))
+	return nil
+}
+
+// readEnvFile parses KEY=VALUE lines. Blank lines, # comments and an
+// "export " prefix are ignored; values may be single-quoted (literal) or
+// double-quoted (with \n, \t, \" and \\ escapes).
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("%s:%d: invalid line %q", path, lineNo, line)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			value = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(value[1 : len(value)-1])
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		vars[key] = value
+	}
+	return vars, scanner.Err()
+}
@@ -0,0 +1,245 @@
+package router
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type FileServerOptions struct {
+	Prefix       string
+	Index        string
+	CacheControl string
+	ModTime      time.Time
+	Compress     bool
+	NotFound     Handler
+}
+
+type staticFile struct {
+	content     []byte
+	gzipped     []byte
+	contentType string
+	etag        string
+	// gzipETag identifies the gzipped content, which caches must not
+	// confuse with the identity content served under etag.
+	gzipETag string
+	modTime  time.Time
+}
+
+type fileServer struct {
+	fsys  fs.FS
+	opts  FileServerOptions
+	files sync.Map
+}
+
+// FileServer serves files from fsys. Since routes are matched exactly, it is
+// usually installed with SetNotFoundHandler so that any unrouted path is
+// looked up in fsys. Prefix is stripped from the request path before lookup.
+func FileServer(fsys fs.FS, opts FileServerOptions) Handler {
+	if opts.Index == "" {
+		opts.Index = "index.html"
+	}
+	if opts.NotFound == nil {
+		opts.NotFound = HandlerFunc(defaultNotFoundHandler)
+	}
+	return &fileServer{fsys: fsys, opts: opts}
+}
+
+func (fsrv *fileServer) ServeHTTP(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+	method := req.RequestContext.HTTP.Method
+	if method != http.MethodGet && method != http.MethodHead {
+		return fsrv.opts.NotFound.ServeHTTP(ctx, req)
+	}
+
+	name, ok := fsrv.fileName(req.RequestContext.HTTP.Path)
+	if !ok {
+		return fsrv.opts.NotFound.ServeHTTP(ctx, req)
+	}
+	file, err := fsrv.load(name)
+	if err != nil {
+		return fsrv.opts.NotFound.ServeHTTP(ctx, req)
+	}
+
+	headers := map[string]string{
+		"Content-Type": file.contentType,
+		"ETag":         file.etag,
+	}
+	if fsrv.opts.CacheControl != "" {
+		headers["Cache-Control"] = fsrv.opts.CacheControl
+	}
+	if !file.modTime.IsZero() {
+		headers["Last-Modified"] = file.modTime.UTC().Format(http.TimeFormat)
+	}
+
+	content := file.content
+	if file.gzipped != nil {
+		headers["Vary"] = "Accept-Encoding"
+		if acceptsEncoding(req.Headers["accept-encoding"], "gzip") {
+			headers["Content-Encoding"] = "gzip"
+			headers["ETag"] = file.gzipETag
+			content = file.gzipped
+		}
+	}
+
+	if notModified(req, file, headers["ETag"]) {
+		delete(headers, "Content-Type")
+		delete(headers, "Content-Encoding")
+		return Response{StatusCode: http.StatusNotModified, Headers: headers}
+	}
+
+	resp := Response{StatusCode: http.StatusOK, Headers: headers}
+	if method == http.MethodHead {
+		return resp
+	}
+	if headers["Content-Encoding"] != "" || !isTextContentType(file.contentType) {
+		resp.Body = base64.StdEncoding.EncodeToString(content)
+		resp.IsBase64Encoded = true
+	} else {
+		resp.Body = string(content)
+	}
+	return resp
+}
+
+func (fsrv *fileServer) fileName(urlPath string) (string, bool) {
+	if fsrv.opts.Prefix != "" {
+		// The prefix must end at a path segment, so /static doesn't
+		// also serve /staticfoo.
+		trimmed, ok := strings.CutPrefix(urlPath, fsrv.opts.Prefix)
+		if !ok || (trimmed != "" && !strings.HasPrefix(trimmed, "/") && !strings.HasSuffix(fsrv.opts.Prefix, "/")) {
+			return "", false
+		}
+		urlPath = trimmed
+	}
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "" || strings.HasSuffix(urlPath, "/") {
+		name = path.Join(name, fsrv.opts.Index)
+	}
+	return name, true
+}
+
+func (fsrv *fileServer) load(name string) (*staticFile, error) {
+	if cached, ok := fsrv.files.Load(name); ok {
+		return cached.(*staticFile), nil
+	}
+
+	info, err := fs.Stat(fsrv.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		name = path.Join(name, fsrv.opts.Index)
+		if info, err = fs.Stat(fsrv.fsys, name); err != nil {
+			return nil, err
+		}
+	}
+	if info.IsDir() {
+		return nil, errors.New("is a directory")
+	}
+	content, err := fs.ReadFile(fsrv.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	h := fnv.New64a()
+	h.Write(content)
+
+	file := &staticFile{
+		content:     content,
+		contentType: contentType,
+		etag:        fmt.Sprintf(`"%x"`, h.Sum64()),
+		gzipETag:    fmt.Sprintf(`"%x-gzip"`, h.Sum64()),
+		modTime:     info.ModTime(),
+	}
+	if !fsrv.opts.ModTime.IsZero() {
+		file.modTime = fsrv.opts.ModTime
+	}
+	if fsrv.opts.Compress && isCompressible(contentType) && len(content) > 1024 {
+		if gz, err := gzipBytes(content); err == nil && len(gz) < len(content) {
+			file.gzipped = gz
+		}
+	}
+
+	fsrv.files.Store(name, file)
+	return file, nil
+}
+
+// notModified reports whether the client's cached copy is current. etag is
+// the tag of the variant the response would carry.
+func notModified(req events.LambdaFunctionURLRequest, file *staticFile, etag string) bool {
+	if inm := req.Headers["if-none-match"]; inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := req.Headers["if-modified-since"]; ims != "" && !file.modTime.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !file.modTime.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		return strings.ReplaceAll(params, " ", "") != "q=0"
+	}
+	return false
+}
+
+func gzipBytes(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func isTextContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(strings.ToLower(mediaType))
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-www-form-urlencoded", "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+func isCompressible(contentType string) bool {
+	return isTextContentType(contentType)
+}
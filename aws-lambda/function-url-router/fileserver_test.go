@@ -0,0 +1,60 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileServerPrefix(t *testing.T) {
+	fsys := fstest.MapFS{"app.js": {Data: []byte("js")}, "foo/app.js": {Data: []byte("foo")}}
+	for _, prefix := range []string{"/static", "/static/"} {
+		srv := FileServer(fsys, FileServerOptions{Prefix: prefix})
+		for path, want := range map[string]int{
+			"/static/app.js":    http.StatusOK,
+			"/staticfoo/app.js": http.StatusNotFound,
+			"/other/app.js":     http.StatusNotFound,
+		} {
+			if resp := srv.ServeHTTP(context.Background(), benchRequest(http.MethodGet, path)); resp.StatusCode != want {
+				t.Errorf("prefix %s: GET %s = %d, want %d", prefix, path, resp.StatusCode, want)
+			}
+		}
+	}
+}
+
+func TestFileServerGzipETag(t *testing.T) {
+	fsys := fstest.MapFS{"app.js": {Data: []byte(strings.Repeat("console.log(1);\n", 200))}}
+	srv := FileServer(fsys, FileServerOptions{Compress: true})
+
+	get := func(acceptEncoding, ifNoneMatch string) Response {
+		req := benchRequest(http.MethodGet, "/app.js")
+		req.Headers = map[string]string{"accept-encoding": acceptEncoding, "if-none-match": ifNoneMatch}
+		return srv.ServeHTTP(context.Background(), req)
+	}
+	identity := get("", "")
+	gzipped := get("gzip", "")
+	if gzipped.Headers["Content-Encoding"] != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", gzipped.Headers["Content-Encoding"])
+	}
+	identityTag, gzipTag := identity.Headers["ETag"], gzipped.Headers["ETag"]
+	if identityTag == gzipTag {
+		t.Fatalf("identity and gzip responses share the ETag %s", identityTag)
+	}
+
+	cases := []struct {
+		acceptEncoding, ifNoneMatch string
+		want                        int
+	}{
+		{"", identityTag, http.StatusNotModified},
+		{"gzip", gzipTag, http.StatusNotModified},
+		{"gzip", identityTag, http.StatusOK},
+		{"", gzipTag, http.StatusOK},
+	}
+	for _, c := range cases {
+		if resp := get(c.acceptEncoding, c.ifNoneMatch); resp.StatusCode != c.want {
+			t.Errorf("Accept-Encoding %q, If-None-Match %s = %d, want %d", c.acceptEncoding, c.ifNoneMatch, resp.StatusCode, c.want)
+		}
+	}
+}
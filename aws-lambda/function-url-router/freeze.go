@@ -0,0 +1,173 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var ErrFrozen = errors.New("router: routes and middleware can't be added after Freeze, use Update")
+
+// routeTable is the routing state requests read. It isn't modified once
+// built, so requests use it without locking; Update replaces it instead.
+type routeTable struct {
+	routes         map[string]map[string]Handler
+	preMiddleware  []Middleware
+	postMiddleware []Middleware
+	// healthPaths are the EnableHealthChecks routes, left out of the access
+	// log.
+	healthPaths map[string]bool
+	// chains holds each route's handler wrapped in the middleware, keyed
+	// by method and path so a request is matched with a single lookup.
+	chains map[routeKey]compiledRoute
+}
+
+type routeKey struct {
+	method, path string
+}
+
+type compiledRoute struct {
+	handler Handler
+	// direct is set for GET and HEAD routes without middleware, which
+	// HandleRequest serves through serveDirect.
+	direct bool
+}
+
+func newRouteTable(routes map[string]map[string]Handler, pre, post []Middleware, health map[string]bool) *routeTable {
+	t := &routeTable{
+		routes:         make(map[string]map[string]Handler, len(routes)),
+		preMiddleware:  append([]Middleware(nil), pre...),
+		postMiddleware: append([]Middleware(nil), post...),
+		healthPaths:    make(map[string]bool, len(health)),
+		chains:         make(map[routeKey]compiledRoute, len(routes)),
+	}
+	for path := range health {
+		t.healthPaths[path] = true
+	}
+	for path, handlers := range routes {
+		t.routes[path] = make(map[string]Handler, len(handlers))
+		for method, handler := range handlers {
+			t.routes[path][method] = handler
+			t.chains[routeKey{method: method, path: path}] = compiledRoute{
+				handler: t.chain(method, path, handler),
+				direct:  (method == http.MethodGet || method == http.MethodHead) && len(t.middleware(method, path)) == 0,
+			}
+		}
+	}
+	return t
+}
+
+// chain wraps handler in the middleware that applies to the route.
+// ExcludedRoutes and ExcludedMethods are settled here, once per route, so
+// excluded middleware costs nothing per request. ExcludedHeaders depend on
+// the request, so middleware that has them is wrapped in a check that calls
+// past it when a header matches.
+func (t *routeTable) chain(method, path string, handler Handler) Handler {
+	middleware := t.middleware(method, path)
+	for i := len(middleware) - 1; i >= 0; i-- {
+		mw := middleware[i]
+		next := handler
+		handler = mw.Func(next)
+		if len(mw.Config.ExcludedHeaders) > 0 {
+			handler = skipOnHeaders(mw.Config.ExcludedHeaders, handler, next)
+		}
+	}
+	return handler
+}
+
+// middleware returns, outermost first, the middleware that isn't excluded
+// for the route.
+func (t *routeTable) middleware(method, path string) []Middleware {
+	var middleware []Middleware
+	for _, list := range [][]Middleware{t.preMiddleware, t.postMiddleware} {
+		for _, mw := range list {
+			if !matchesAny(mw.Config.ExcludedRoutes, path) && !containsFold(mw.Config.ExcludedMethods, method) {
+				middleware = append(middleware, mw)
+			}
+		}
+	}
+	return middleware
+}
+
+func skipOnHeaders(headers map[string]string, wrapped, next Handler) Handler {
+	// Function URL events carry lowercase header names.
+	lower := make(map[string]string, len(headers))
+	for k, v := range headers {
+		lower[strings.ToLower(k)] = v
+	}
+	return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		for k, v := range lower {
+			if value, ok := req.Headers[k]; ok && value == v {
+				return next.ServeHTTP(ctx, req)
+			}
+		}
+		return wrapped.ServeHTTP(ctx, req)
+	})
+}
+
+// Freeze compiles the routes and middleware into an immutable table that
+// requests read without locking, so the router can serve requests from
+// many goroutines. The first request freezes the router if Freeze wasn't
+// called before. Calling it again does nothing.
+func (r *Router) Freeze() {
+	r.updateMu.Lock()
+	defer r.updateMu.Unlock()
+	if r.table.Load() == nil {
+		r.table.Store(newRouteTable(r.routes, r.preMiddleware, r.postMiddleware, r.healthPaths))
+	}
+}
+
+// Update changes the routes or middleware of a frozen router while it
+// serves requests. fn registers them on a router holding a copy of the
+// current ones, which is then compiled and swapped in atomically: each
+// request sees either the old table or the new one. Only routes,
+// middleware and health check paths are taken from the copy, so other
+// settings, including EnableDebug, must be made before the router is
+// frozen. On a router that isn't frozen, fn is called with r itself.
+func (r *Router) Update(fn func(*Router)) {
+	r.updateMu.Lock()
+	current := r.table.Load()
+	if current == nil {
+		r.updateMu.Unlock()
+		fn(r)
+		return
+	}
+	defer r.updateMu.Unlock()
+	staging := NewRouter(r.logger)
+	for path, handlers := range current.routes {
+		staging.routes[path] = make(map[string]Handler, len(handlers))
+		for method, handler := range handlers {
+			staging.routes[path][method] = handler
+		}
+	}
+	staging.preMiddleware = append(staging.preMiddleware, current.preMiddleware...)
+	staging.postMiddleware = append(staging.postMiddleware, current.postMiddleware...)
+	staging.healthPaths = make(map[string]bool, len(current.healthPaths))
+	for path := range current.healthPaths {
+		staging.healthPaths[path] = true
+	}
+	fn(staging)
+	r.table.Store(newRouteTable(staging.routes, staging.preMiddleware, staging.postMiddleware, staging.healthPaths))
+}
+
+// routing returns the table requests use, freezing the router first if
+// needed.
+func (r *Router) routing() *routeTable {
+	if t := r.table.Load(); t != nil {
+		return t
+	}
+	r.Freeze()
+	return r.table.Load()
+}
+
+// registered returns the routes and middleware for introspection without
+// freezing the router. Chains are only set once it's frozen.
+func (r *Router) registered() *routeTable {
+	if t := r.table.Load(); t != nil {
+		return t
+	}
+	return &routeTable{routes: r.routes, preMiddleware: r.preMiddleware, postMiddleware: r.postMiddleware, healthPaths: r.healthPaths}
+}
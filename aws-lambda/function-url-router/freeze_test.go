@@ -0,0 +1,235 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func newTestRouter() *Router {
+	r := NewRouter(log.New(io.Discard, "", 0))
+	r.SetAccessLog(AccessLogConfig{Format: AccessLogOff})
+	return r
+}
+
+func textHandler(body string) Handler {
+	return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		return Response{StatusCode: http.StatusOK, Body: body}
+	})
+}
+
+// headerMiddleware sets X-Version on every response, so tests can tell
+// which table's chain served a request.
+func headerMiddleware(version string) MiddlewareFunc {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			resp := next.ServeHTTP(ctx, req)
+			resp.AddHeader("X-Version", version)
+			return resp
+		})
+	}
+}
+
+func TestFrozenRouterRejectsRegistration(t *testing.T) {
+	for _, freeze := range []string{"Freeze", "first request"} {
+		t.Run(freeze, func(t *testing.T) {
+			r := newTestRouter()
+			if err := r.AddRoute(http.MethodGet, "/a", textHandler("a")); err != nil {
+				t.Fatal(err)
+			}
+			if freeze == "Freeze" {
+				r.Freeze()
+			} else {
+				r.HandleRequest(context.Background(), benchRequest(http.MethodGet, "/a"))
+			}
+
+			registrations := map[string]error{
+				"AddRoute":           r.AddRoute(http.MethodGet, "/b", textHandler("b")),
+				"UsePre":             r.UsePre(headerMiddleware("pre"), MiddlewareConfig{}),
+				"UsePost":            r.UsePost(headerMiddleware("post"), MiddlewareConfig{}),
+				"EnableHealthChecks": r.EnableHealthChecks("/healthz"),
+				"EnableDebug": r.EnableDebug(DebugConfig{Auth: func(next Handler) Handler {
+					return next
+				}}),
+			}
+			for name, err := range registrations {
+				if !errors.Is(err, ErrFrozen) {
+					t.Errorf("%s = %v, want ErrFrozen", name, err)
+				}
+			}
+
+			for _, path := range []string{"/b", "/healthz", "/_debug"} {
+				if resp := r.HandleRequest(context.Background(), benchRequest(http.MethodGet, path)); resp.StatusCode != http.StatusNotFound {
+					t.Errorf("GET %s = %d, want 404", path, resp.StatusCode)
+				}
+			}
+			resp := r.HandleRequest(context.Background(), benchRequest(http.MethodGet, "/a"))
+			if len(resp.HeaderValues("X-Version")) != 0 {
+				t.Errorf("middleware added after freezing ran: %v", resp.HeaderValues("X-Version"))
+			}
+			if r.errorSamples != nil || len(r.routing().healthPaths) != 0 {
+				t.Error("rejected helpers changed the router's settings")
+			}
+		})
+	}
+}
+
+func TestUpdateNotFoundAndMethodNotAllowed(t *testing.T) {
+	r := newTestRouter()
+	r.AddRoute(http.MethodGet, "/a", textHandler("a"))
+	r.Freeze()
+
+	cases := []struct {
+		method, path string
+		before       int
+		after        int
+	}{
+		{http.MethodGet, "/a", http.StatusOK, http.StatusOK},
+		{http.MethodPost, "/a", http.StatusMethodNotAllowed, http.StatusOK},
+		{http.MethodGet, "/b", http.StatusNotFound, http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/b", http.StatusNotFound, http.StatusOK},
+		{http.MethodGet, "/c", http.StatusNotFound, http.StatusNotFound},
+	}
+	check := func(when string, want func(int) int) {
+		t.Helper()
+		for i, c := range cases {
+			if got := r.HandleRequest(context.Background(), benchRequest(c.method, c.path)).StatusCode; got != want(i) {
+				t.Errorf("%s Update: %s %s = %d, want %d", when, c.method, c.path, got, want(i))
+			}
+		}
+	}
+	check("before", func(i int) int { return cases[i].before })
+
+	r.Update(func(u *Router) {
+		if err := u.AddRoute(http.MethodPost, "/a", textHandler("post a")); err != nil {
+			t.Error(err)
+		}
+		if err := u.AddRoute(http.MethodDelete, "/b", textHandler("delete b")); err != nil {
+			t.Error(err)
+		}
+	})
+	check("after", func(i int) int { return cases[i].after })
+}
+
+func TestUpdateKeepsHealthPaths(t *testing.T) {
+	r := newTestRouter()
+	r.EnableHealthChecks("/healthz")
+	r.Freeze()
+	r.Update(func(u *Router) {
+		if err := u.EnableHealthChecks("/ready"); err != nil {
+			t.Error(err)
+		}
+	})
+	for _, path := range []string{"/healthz", "/healthz/live", "/ready", "/ready/live"} {
+		if !r.routing().healthPaths[path] {
+			t.Errorf("%s isn't a health path after Update", path)
+		}
+		if resp := r.HandleRequest(context.Background(), benchRequest(http.MethodGet, path)); resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s = %d, want 200", path, resp.StatusCode)
+		}
+	}
+}
+
+// TestUpdateInFlight checks that a request keeps the chain it started with
+// while Update swaps in a new table.
+func TestUpdateInFlight(t *testing.T) {
+	r := newTestRouter()
+	r.UsePre(headerMiddleware("1"), MiddlewareConfig{})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r.AddRoute(http.MethodPost, "/slow", HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		close(started)
+		<-release
+		return Response{StatusCode: http.StatusOK, Body: "1"}
+	}))
+	r.Freeze()
+
+	inFlight := make(chan Response)
+	go func() {
+		inFlight <- r.HandleRequest(context.Background(), benchRequest(http.MethodPost, "/slow"))
+	}()
+	<-started
+
+	r.Update(func(u *Router) {
+		u.preMiddleware = nil
+		u.UsePre(headerMiddleware("2"), MiddlewareConfig{})
+		delete(u.routes, "/slow")
+		u.AddRoute(http.MethodPost, "/slow", textHandler("2"))
+	})
+	resp := r.HandleRequest(context.Background(), benchRequest(http.MethodPost, "/slow"))
+	if resp.Body != "2" || fmt.Sprint(resp.HeaderValues("X-Version")) != "[2]" {
+		t.Errorf("request after Update = %v %v, want the new table", resp.Body, resp.HeaderValues("X-Version"))
+	}
+
+	close(release)
+	resp = <-inFlight
+	if resp.Body != "1" || fmt.Sprint(resp.HeaderValues("X-Version")) != "[1]" {
+		t.Errorf("in-flight request = %v %v, want the table it started with", resp.Body, resp.HeaderValues("X-Version"))
+	}
+}
+
+// TestUpdateConcurrent runs requests while tables are swapped, checking
+// under -race that each request sees one whole table: the handler and the
+// middleware always come from the same version.
+func TestUpdateConcurrent(t *testing.T) {
+	r := newTestRouter()
+	r.UsePre(headerMiddleware("0"), MiddlewareConfig{})
+	r.AddRoute(http.MethodPost, "/v", textHandler("0"))
+	r.Freeze()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				resp := r.HandleRequest(context.Background(), benchRequest(http.MethodPost, "/v"))
+				if versions := resp.HeaderValues("X-Version"); len(versions) != 1 || versions[0] != resp.Body {
+					t.Errorf("handler %v ran with middleware %v", resp.Body, versions)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= 200; i++ {
+		version := fmt.Sprint(i)
+		r.Update(func(u *Router) {
+			u.preMiddleware = nil
+			u.UsePre(headerMiddleware(version), MiddlewareConfig{})
+			delete(u.routes, "/v")
+			u.AddRoute(http.MethodPost, "/v", textHandler(version))
+		})
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestFallbackBodies(t *testing.T) {
+	r := newTestRouter()
+	r.AddRoute(http.MethodGet, "/a", textHandler("a"))
+	for _, c := range []struct {
+		method, path, want string
+	}{
+		{http.MethodGet, "/b", `{"error":"Not Found"}`},
+		{http.MethodPost, "/a", `{"error":"Method Not Allowed"}`},
+	} {
+		resp, _ := r.LambdaHandler()(context.Background(), benchRequest(c.method, c.path))
+		if resp.Body != c.want || resp.Headers["Content-Type"] != "application/json" {
+			t.Errorf("%s %s = %v %s, want JSON %s", c.method, c.path, resp.Headers, resp.Body, c.want)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+const defaultMaxUploadBytes = 5 << 20
+
+type Params struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions"`
+}
+
+// Upload is placed into Variables for every file in a multipart request.
+// File is closed once the Executor returns.
+type Upload struct {
+	File        io.ReadSeeker
+	Filename    string
+	ContentType string
+	Size        int64
+}
+
+// Executor runs a single operation and returns a JSON-encodable result.
+// Wrap gqlgen's executor or a graphql-go schema's Exec to satisfy it.
+type Executor interface {
+	Execute(ctx context.Context, params Params) interface{}
+}
+
+type ExecutorFunc func(context.Context, Params) interface{}
+
+func (f ExecutorFunc) Execute(ctx context.Context, params Params) interface{} {
+	return f(ctx, params)
+}
+
+type Options struct {
+	MaxUploadBytes int64
+	// OnResolve receives every resolver trace recorded with StartResolver.
+	OnResolve func(context.Context, ResolverTrace)
+}
+
+// Handler serves GraphQL over GET (query string), POST (JSON, batched JSON
+// or application/graphql) and multipart uploads.
+func Handler(exec Executor, opts Options) router.Handler {
+	if opts.MaxUploadBytes == 0 {
+		opts.MaxUploadBytes = defaultMaxUploadBytes
+	}
+	return router.HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+		if opts.OnResolve != nil {
+			ctx = context.WithValue(ctx, tracerKey, opts.OnResolve)
+		}
+
+		if req.RequestContext.HTTP.Method == http.MethodGet {
+			params, err := paramsFromQuery(req.QueryStringParameters)
+			if err != nil {
+				return badRequest(err)
+			}
+			return result(exec.Execute(ctx, params))
+		}
+
+		rc, err := router.BodyReader(ctx, req)
+		if err != nil {
+			return badRequest(err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return badRequest(err)
+		}
+
+		mediaType, mediaParams, _ := mime.ParseMediaType(req.Headers["content-type"])
+		switch mediaType {
+		case "application/graphql":
+			return result(exec.Execute(ctx, Params{Query: string(body)}))
+		case "multipart/form-data":
+			params, closeFiles, err := paramsFromMultipart(body, mediaParams["boundary"], opts.MaxUploadBytes)
+			if err != nil {
+				return badRequest(err)
+			}
+			defer closeFiles()
+			return result(exec.Execute(ctx, params))
+		}
+
+		body = bytes.TrimSpace(body)
+		if len(body) > 0 && body[0] == '[' {
+			var batch []Params
+			if err := json.Unmarshal(body, &batch); err != nil {
+				return badRequest(err)
+			}
+			results := make([]interface{}, len(batch))
+			for i, params := range batch {
+				results[i] = exec.Execute(ctx, params)
+			}
+			return result(results)
+		}
+
+		var params Params
+		if err := json.Unmarshal(body, &params); err != nil {
+			return badRequest(err)
+		}
+		return result(exec.Execute(ctx, params))
+	})
+}
+
+// Mount registers the handler for GET and POST on path. It fails with
+// router.ErrFrozen once r is frozen.
+func Mount(r *router.Router, path string, h router.Handler) error {
+	if err := r.AddRoute(http.MethodGet, path, h); err != nil {
+		return err
+	}
+	return r.AddRoute(http.MethodPost, path, h)
+}
+
+func paramsFromQuery(query map[string]string) (Params, error) {
+	params := Params{Query: query["query"], OperationName: query["operationName"]}
+	if v := query["variables"]; v != "" {
+		if err := json.Unmarshal([]byte(v), &params.Variables); err != nil {
+			return params, fmt.Errorf("invalid variables: %w", err)
+		}
+	}
+	if v := query["extensions"]; v != "" {
+		if err := json.Unmarshal([]byte(v), &params.Extensions); err != nil {
+			return params, fmt.Errorf("invalid extensions: %w", err)
+		}
+	}
+	return params, nil
+}
+
+// paramsFromMultipart implements the GraphQL multipart request spec: an
+// "operations" field, a "map" field and one part per file. The Upload files
+// stay open for the resolvers; closeFiles closes them and removes any the
+// form wrote to disk, and is only returned without an error.
+func paramsFromMultipart(body []byte, boundary string, maxBytes int64) (params Params, closeFiles func(), err error) {
+	if boundary == "" {
+		return params, nil, errors.New("missing multipart boundary")
+	}
+	form, err := multipart.NewReader(bytes.NewReader(body), boundary).ReadForm(maxBytes)
+	if err != nil {
+		return params, nil, err
+	}
+	var files []multipart.File
+	closeFiles = func() {
+		for _, f := range files {
+			f.Close()
+		}
+		form.RemoveAll()
+	}
+	defer func() {
+		if err != nil {
+			closeFiles()
+			closeFiles = nil
+		}
+	}()
+
+	operations := form.Value["operations"]
+	if len(operations) == 0 {
+		return params, nil, errors.New("missing operations field")
+	}
+	if err := json.Unmarshal([]byte(operations[0]), &params); err != nil {
+		return params, nil, fmt.Errorf("invalid operations: %w", err)
+	}
+
+	var fileMap map[string][]string
+	if values := form.Value["map"]; len(values) > 0 {
+		if err := json.Unmarshal([]byte(values[0]), &fileMap); err != nil {
+			return params, nil, fmt.Errorf("invalid map: %w", err)
+		}
+	}
+
+	root := map[string]interface{}{"variables": params.Variables}
+	if params.Variables == nil {
+		root["variables"] = map[string]interface{}{}
+	}
+	for key, paths := range fileMap {
+		headers := form.File[key]
+		if len(headers) == 0 {
+			return params, nil, fmt.Errorf("missing file part %q", key)
+		}
+		fh := headers[0]
+		f, err := fh.Open()
+		if err != nil {
+			return params, nil, err
+		}
+		files = append(files, f)
+		upload := Upload{File: f, Filename: fh.Filename, ContentType: fh.Header.Get("Content-Type"), Size: fh.Size}
+		for _, path := range paths {
+			if err := setPath(root, strings.Split(path, "."), upload); err != nil {
+				return params, nil, err
+			}
+		}
+	}
+	params.Variables, _ = root["variables"].(map[string]interface{})
+	return params, closeFiles, nil
+}
+
+func setPath(node interface{}, path []string, value interface{}) error {
+	for i, part := range path {
+		last := i == len(path)-1
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if last {
+				n[part] = value
+				return nil
+			}
+			node = n[part]
+		case []interface{}:
+			idx, err := strconv.Atoi(part)
+			if err != nil || idx < 0 || idx >= len(n) {
+				return fmt.Errorf("invalid upload path %q", strings.Join(path, "."))
+			}
+			if last {
+				n[idx] = value
+				return nil
+			}
+			node = n[idx]
+		default:
+			return fmt.Errorf("invalid upload path %q", strings.Join(path, "."))
+		}
+	}
+	return nil
+}
+
+func result(v interface{}) router.Response {
+	return router.Response{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       v,
+	}
+}
+
+func badRequest(err error) router.Response {
+	return router.Response{
+		StatusCode: http.StatusBadRequest,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       map[string]interface{}{"errors": []map[string]string{{"message": err.Error()}}},
+	}
+}
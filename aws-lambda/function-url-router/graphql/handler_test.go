@@ -0,0 +1,47 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestMultipartUploadsClosed(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("operations", `{"query":"mutation($file: Upload!) { upload(file: $file) }","variables":{"file":null}}`)
+	mw.WriteField("map", `{"0":["variables.file"]}`)
+	part, _ := mw.CreateFormFile("0", "a.txt")
+	part.Write([]byte(strings.Repeat("x", 4096)))
+	mw.Close()
+
+	var upload Upload
+	h := Handler(ExecutorFunc(func(ctx context.Context, params Params) interface{} {
+		upload, _ = params.Variables["file"].(Upload)
+		data, err := io.ReadAll(upload.File)
+		if err != nil {
+			t.Errorf("reading upload: %v", err)
+		}
+		return map[string]int{"size": len(data)}
+	}), Options{MaxUploadBytes: 1024})
+
+	var req events.LambdaFunctionURLRequest
+	req.RequestContext.HTTP.Method = http.MethodPost
+	req.Headers = map[string]string{"content-type": mw.FormDataContentType()}
+	req.Body = buf.String()
+	if resp := h.ServeHTTP(context.Background(), req); resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, body %v", resp.StatusCode, resp.Body)
+	}
+	if upload.File == nil {
+		t.Fatal("executor got no upload")
+	}
+	if _, err := upload.File.Seek(0, io.SeekStart); err == nil {
+		t.Error("upload is still open after the executor returned")
+	}
+}
@@ -0,0 +1,31 @@
+package graphql
+
+import (
+	"context"
+	"time"
+)
+
+type contextKey int
+
+const tracerKey contextKey = iota
+
+type ResolverTrace struct {
+	Path     string
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// StartResolver marks the start of a resolver and returns the function to
+// call when it finishes. Call it from gqlgen's AroundFields or a graphql-go
+// Tracer; traces go to Options.OnResolve for the current request.
+func StartResolver(ctx context.Context, path string) func(error) {
+	onResolve, _ := ctx.Value(tracerKey).(func(context.Context, ResolverTrace))
+	if onResolve == nil {
+		return func(error) {}
+	}
+	start := time.Now()
+	return func(err error) {
+		onResolve(ctx, ResolverTrace{Path: path, Start: start, Duration: time.Since(start), Err: err})
+	}
+}
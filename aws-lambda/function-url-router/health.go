@@ -0,0 +1,132 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// HealthCheck reports whether a dependency, such as a table or loaded
+// configuration, is usable. Check returning nil means healthy.
+type HealthCheck struct {
+	Name  string
+	Check func(context.Context) error
+	// Timeout defaults to 2 seconds.
+	Timeout time.Duration
+}
+
+type healthResult struct {
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// EnableHealthChecks registers two endpoints. path+"/live" answers 200 as
+// long as the function can serve requests. path runs every check
+// concurrently and answers 200, or 503 if any failed, with each check's
+// status:
+//
+//	{"status": "error", "checks": {"dynamodb": {"status": "error", "duration_ms": 2000, "error": "context deadline exceeded"}}}
+//
+// Requests to both are left out of the access log unless
+// AccessLogConfig.HealthChecks is set. Like AddRoute, it returns ErrFrozen
+// once the router is frozen; use Update then.
+func (r *Router) EnableHealthChecks(path string, checks ...HealthCheck) error {
+	path = strings.TrimRight(path, "/")
+	live := path + "/live"
+
+	ready := HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		results := runHealthChecks(ctx, checks)
+		status, code := "ok", http.StatusOK
+		for _, result := range results {
+			if result.Status != "ok" {
+				status, code = "error", http.StatusServiceUnavailable
+			}
+		}
+		return Response{
+			StatusCode: code,
+			Headers:    map[string]string{"Content-Type": "application/json", "Cache-Control": "no-store"},
+			Body:       map[string]interface{}{"status": status, "checks": results},
+		}
+	})
+	alive := HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		return Response{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "application/json", "Cache-Control": "no-store"},
+			Body:       map[string]string{"status": "ok"},
+		}
+	})
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		if err := r.AddRoute(method, path, ready); err != nil {
+			return err
+		}
+		if err := r.AddRoute(method, live, alive); err != nil {
+			return err
+		}
+	}
+
+	if r.healthPaths == nil {
+		r.healthPaths = make(map[string]bool)
+	}
+	r.healthPaths[path] = true
+	r.healthPaths[live] = true
+	return nil
+}
+
+func runHealthChecks(ctx context.Context, checks []HealthCheck) map[string]healthResult {
+	results := make(map[string]healthResult, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			result := runHealthCheck(ctx, check)
+			mu.Lock()
+			results[check.Name] = result
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+	return results
+}
+
+func runHealthCheck(ctx context.Context, check HealthCheck) healthResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		defer func() {
+			if e := recover(); e != nil {
+				errc <- fmt.Errorf("panic: %v", e)
+			}
+		}()
+		errc <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := healthResult{Status: "ok", DurationMS: float64(time.Since(start)) / float64(time.Millisecond)}
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package router
+
+import (
+	"bytes"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"sync"
+)
+
+type HTMLRenderer struct {
+	fsys     fs.FS
+	patterns []string
+	funcs    template.FuncMap
+
+	mu   sync.Mutex
+	tmpl *template.Template
+}
+
+// NewHTMLRenderer loads templates from fsys (typically an embed.FS). The
+// files matching patterns are parsed on first use and cached for the
+// lifetime of the renderer, so warm invocations skip parsing entirely.
+func NewHTMLRenderer(fsys fs.FS, patterns ...string) *HTMLRenderer {
+	return &HTMLRenderer{
+		fsys:     fsys,
+		patterns: patterns,
+		funcs:    template.FuncMap{},
+	}
+}
+
+func (hr *HTMLRenderer) Funcs(funcs template.FuncMap) *HTMLRenderer {
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+	for k, v := range funcs {
+		hr.funcs[k] = v
+	}
+	hr.tmpl = nil
+	return hr
+}
+
+func (hr *HTMLRenderer) HTML(status int, name string, data interface{}) Response {
+	var buf bytes.Buffer
+	if err := hr.Execute(&buf, name, data); err != nil {
+		return Response{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       map[string]string{"error": "Internal Server Error"},
+		}
+	}
+	return Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "text/html; charset=utf-8"},
+		Body:       buf.String(),
+	}
+}
+
+func (hr *HTMLRenderer) Execute(buf *bytes.Buffer, name string, data interface{}) error {
+	tmpl, err := hr.templates()
+	if err != nil {
+		return err
+	}
+	return tmpl.ExecuteTemplate(buf, name, data)
+}
+
+func (hr *HTMLRenderer) templates() (*template.Template, error) {
+	hr.mu.Lock()
+	defer hr.mu.Unlock()
+	if hr.tmpl != nil {
+		return hr.tmpl, nil
+	}
+	tmpl, err := template.New("").Funcs(hr.funcs).ParseFS(hr.fsys, hr.patterns...)
+	if err != nil {
+		return nil, err
+	}
+	hr.tmpl = tmpl
+	return tmpl, nil
+}
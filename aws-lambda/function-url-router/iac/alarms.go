@@ -0,0 +1,233 @@
+package iac
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+var ErrNamespaceRequired = errors.New("iac: AlarmOptions.Namespace is required")
+
+// AlarmOptions configures Alarms. Routes can override the thresholds with
+// metadata set through SetRouteMetadata: "alarm.error_rate" (a percentage),
+// "alarm.latency_p99" (a duration such as "750ms"), or "alarm" set to "off"
+// to skip the route.
+type AlarmOptions struct {
+	// Namespace and Dimensions must match the emf.Config the function
+	// uses.
+	Namespace  string
+	Dimensions map[string]string
+	// ErrorRate is the percentage of requests answered with a 5xx, or a
+	// panic, that raises the alarm. Defaults to 5.
+	ErrorRate float64
+	// LatencyP99 defaults to one second.
+	LatencyP99 time.Duration
+	// ReportedLatency alarms on the LatencyP99 metric written by
+	// emf.LatencyReporter instead of the p99 of the per-request Latency
+	// metric, which only covers warm requests because percentiles can't be
+	// combined across the ColdStart dimension.
+	ReportedLatency bool
+	// Period in seconds. Defaults to 60.
+	Period int
+	// EvaluationPeriods defaults to 5 and DatapointsToAlarm to 3.
+	EvaluationPeriods int
+	DatapointsToAlarm int
+	// AlarmActions are notified, e.g. SNS topic ARNs, when an alarm fires
+	// and OKActions when it recovers.
+	AlarmActions []string
+	OKActions    []string
+}
+
+type cfnTemplate struct {
+	AWSTemplateFormatVersion string                 `yaml:"AWSTemplateFormatVersion"`
+	Resources                map[string]samResource `yaml:"Resources"`
+}
+
+type cfnAlarm struct {
+	AlarmName          string           `yaml:"AlarmName"`
+	AlarmDescription   string           `yaml:"AlarmDescription"`
+	Namespace          string           `yaml:"Namespace,omitempty"`
+	MetricName         string           `yaml:"MetricName,omitempty"`
+	Dimensions         []cfnDimension   `yaml:"Dimensions,omitempty"`
+	Statistic          string           `yaml:"Statistic,omitempty"`
+	ExtendedStatistic  string           `yaml:"ExtendedStatistic,omitempty"`
+	Period             int              `yaml:"Period,omitempty"`
+	Unit               string           `yaml:"Unit,omitempty"`
+	Metrics            []cfnMetricQuery `yaml:"Metrics,omitempty"`
+	ComparisonOperator string           `yaml:"ComparisonOperator"`
+	Threshold          float64          `yaml:"Threshold"`
+	EvaluationPeriods  int              `yaml:"EvaluationPeriods"`
+	DatapointsToAlarm  int              `yaml:"DatapointsToAlarm"`
+	TreatMissingData   string           `yaml:"TreatMissingData"`
+	AlarmActions       []string         `yaml:"AlarmActions,omitempty"`
+	OKActions          []string         `yaml:"OKActions,omitempty"`
+}
+
+type cfnDimension struct {
+	Name  string `yaml:"Name"`
+	Value string `yaml:"Value"`
+}
+
+type cfnMetricQuery struct {
+	ID         string         `yaml:"Id"`
+	Expression string         `yaml:"Expression,omitempty"`
+	Label      string         `yaml:"Label,omitempty"`
+	MetricStat *cfnMetricStat `yaml:"MetricStat,omitempty"`
+	ReturnData bool           `yaml:"ReturnData"`
+}
+
+type cfnMetricStat struct {
+	Metric cfnMetric `yaml:"Metric"`
+	Period int       `yaml:"Period"`
+	Stat   string    `yaml:"Stat"`
+}
+
+type cfnMetric struct {
+	Namespace  string         `yaml:"Namespace"`
+	MetricName string         `yaml:"MetricName"`
+	Dimensions []cfnDimension `yaml:"Dimensions"`
+}
+
+// Alarms renders a CloudFormation template with an error rate alarm and a
+// p99 latency alarm for every route in r, on the metrics emf.Middleware
+// writes. The resources can be deployed on their own or copied into a SAM
+// template; regenerating it after adding routes keeps the alarms in step.
+func Alarms(r *router.Router, opts AlarmOptions) ([]byte, error) {
+	if opts.Namespace == "" {
+		return nil, ErrNamespaceRequired
+	}
+	if opts.ErrorRate <= 0 {
+		opts.ErrorRate = 5
+	}
+	if opts.LatencyP99 <= 0 {
+		opts.LatencyP99 = time.Second
+	}
+	if opts.Period <= 0 {
+		opts.Period = 60
+	}
+	if opts.EvaluationPeriods <= 0 {
+		opts.EvaluationPeriods = 5
+	}
+	if opts.DatapointsToAlarm <= 0 {
+		opts.DatapointsToAlarm = 3
+	}
+
+	tmpl := cfnTemplate{
+		AWSTemplateFormatVersion: "2010-09-09",
+		Resources:                make(map[string]samResource),
+	}
+	for _, route := range r.Routes() {
+		if route.Metadata["alarm"] == "off" {
+			continue
+		}
+		errorRate := opts.ErrorRate
+		if v, ok := route.Metadata["alarm.error_rate"]; ok {
+			rate, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("iac: %s %s: alarm.error_rate: %w", route.Method, route.Path, err)
+			}
+			errorRate = rate
+		}
+		latency := opts.LatencyP99
+		if v, ok := route.Metadata["alarm.latency_p99"]; ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("iac: %s %s: alarm.latency_p99: %w", route.Method, route.Path, err)
+			}
+			latency = d
+		}
+
+		name := eventName(route)
+		tmpl.Resources[name+"ErrorRateAlarm"] = samResource{
+			Type:       "AWS::CloudWatch::Alarm",
+			Properties: errorRateAlarm(route, opts, errorRate),
+		}
+		tmpl.Resources[name+"LatencyAlarm"] = samResource{
+			Type:       "AWS::CloudWatch::Alarm",
+			Properties: latencyAlarm(route, opts, latency),
+		}
+	}
+	return marshalYAML(tmpl)
+}
+
+// errorRateAlarm sums Errors and Requests over both ColdStart values, since
+// an alarm needs every dimension of the metrics it reads.
+func errorRateAlarm(route router.Route, opts AlarmOptions, threshold float64) cfnAlarm {
+	var metrics []cfnMetricQuery
+	for i, coldStart := range []string{"false", "true"} {
+		dims := alarmDimensions(route, opts, cfnDimension{Name: "ColdStart", Value: coldStart})
+		for _, m := range []struct{ id, name string }{{"e", "Errors"}, {"r", "Requests"}} {
+			metrics = append(metrics, cfnMetricQuery{
+				ID: m.id + strconv.Itoa(i),
+				MetricStat: &cfnMetricStat{
+					Metric: cfnMetric{Namespace: opts.Namespace, MetricName: m.name, Dimensions: dims},
+					Period: opts.Period,
+					Stat:   "Sum",
+				},
+			})
+		}
+	}
+	metrics = append([]cfnMetricQuery{{
+		ID:         "rate",
+		Expression: "100 * (FILL(e0, 0) + FILL(e1, 0)) / (FILL(r0, 0) + FILL(r1, 0))",
+		Label:      "Error rate (%)",
+		ReturnData: true,
+	}}, metrics...)
+
+	return cfnAlarm{
+		AlarmName:          fmt.Sprintf("%s %s %s error rate", opts.Namespace, route.Method, route.Path),
+		AlarmDescription:   fmt.Sprintf("More than %g%% of %s %s requests failed with a 5xx.", threshold, route.Method, route.Path),
+		Metrics:            metrics,
+		ComparisonOperator: "GreaterThanThreshold",
+		Threshold:          threshold,
+		EvaluationPeriods:  opts.EvaluationPeriods,
+		DatapointsToAlarm:  opts.DatapointsToAlarm,
+		TreatMissingData:   "notBreaching",
+		AlarmActions:       opts.AlarmActions,
+		OKActions:          opts.OKActions,
+	}
+}
+
+func latencyAlarm(route router.Route, opts AlarmOptions, threshold time.Duration) cfnAlarm {
+	alarm := cfnAlarm{
+		AlarmName:          fmt.Sprintf("%s %s %s p99 latency", opts.Namespace, route.Method, route.Path),
+		AlarmDescription:   fmt.Sprintf("p99 latency of %s %s is above %v.", route.Method, route.Path, threshold),
+		Namespace:          opts.Namespace,
+		Period:             opts.Period,
+		Unit:               "Milliseconds",
+		ComparisonOperator: "GreaterThanThreshold",
+		Threshold:          float64(threshold) / float64(time.Millisecond),
+		EvaluationPeriods:  opts.EvaluationPeriods,
+		DatapointsToAlarm:  opts.DatapointsToAlarm,
+		TreatMissingData:   "notBreaching",
+		AlarmActions:       opts.AlarmActions,
+		OKActions:          opts.OKActions,
+	}
+	if opts.ReportedLatency {
+		alarm.MetricName = "LatencyP99"
+		alarm.Dimensions = alarmDimensions(route, opts)
+		alarm.Statistic = "Maximum"
+	} else {
+		alarm.MetricName = "Latency"
+		alarm.Dimensions = alarmDimensions(route, opts, cfnDimension{Name: "ColdStart", Value: "false"})
+		alarm.ExtendedStatistic = "p99"
+	}
+	return alarm
+}
+
+func alarmDimensions(route router.Route, opts AlarmOptions, extra ...cfnDimension) []cfnDimension {
+	dims := []cfnDimension{{Name: "Route", Value: route.Method + " " + route.Path}}
+	keys := make([]string, 0, len(opts.Dimensions))
+	for k := range opts.Dimensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		dims = append(dims, cfnDimension{Name: k, Value: opts.Dimensions[k]})
+	}
+	return append(dims, extra...)
+}
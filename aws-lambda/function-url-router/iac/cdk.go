@@ -0,0 +1,111 @@
+package iac
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+type CDKOptions struct {
+	// FunctionVar is the TypeScript variable holding the lambda.Function.
+	// Defaults to "fn".
+	FunctionVar string
+	// CloudFront adds a distribution with a FunctionUrlOrigin, using origin
+	// access control when AuthType is AWS_IAM.
+	CloudFront bool
+}
+
+var cdkTemplate = template.Must(template.New("cdk").Funcs(template.FuncMap{
+	"list":    tsList,
+	"methods": cdkMethods,
+}).Parse(`const {{.Var}}Url = {{.Var}}.addFunctionUrl({
+  authType: lambda.FunctionUrlAuthType.{{.AuthType}},
+  invokeMode: lambda.InvokeMode.{{.InvokeMode}},
+{{- with .CORS}}
+  cors: {
+    allowedOrigins: {{list .AllowOrigins}},
+{{- if .AllowMethods}}
+    allowedMethods: {{methods .AllowMethods}},
+{{- end}}
+{{- if .AllowHeaders}}
+    allowedHeaders: {{list .AllowHeaders}},
+{{- end}}
+{{- if .ExposeHeaders}}
+    exposedHeaders: {{list .ExposeHeaders}},
+{{- end}}
+{{- if .AllowCredentials}}
+    allowCredentials: true,
+{{- end}}
+{{- if .MaxAge}}
+    maxAge: cdk.Duration.seconds({{.MaxAge}}),
+{{- end}}
+  },
+{{- end}}
+});
+{{- if .CloudFront}}
+
+const {{.Var}}Distribution = new cloudfront.Distribution(this, '{{.Name}}Distribution', {
+  defaultBehavior: {
+    origin: {{if .IAM}}origins.FunctionUrlOrigin.withOriginAccessControl({{.Var}}Url){{else}}new origins.FunctionUrlOrigin({{.Var}}Url){{end}},
+    viewerProtocolPolicy: cloudfront.ViewerProtocolPolicy.REDIRECT_TO_HTTPS,
+    allowedMethods: cloudfront.AllowedMethods.ALLOW_ALL,
+    cachePolicy: cloudfront.CachePolicy.CACHING_DISABLED,
+    originRequestPolicy: cloudfront.OriginRequestPolicy.ALL_VIEWER_EXCEPT_HOST_HEADER,
+  },
+});
+new cdk.CfnOutput(this, '{{.Name}}Url', { value: ` + "`https://${ {{- .Var}}Distribution.distributionDomainName}/`" + ` });
+{{- else}}
+new cdk.CfnOutput(this, '{{.Name}}Url', { value: {{.Var}}Url.url });
+{{- end}}
+`))
+
+// CDK renders a TypeScript snippet that adds a Function URL, with CORS
+// settings from r.CORS(), to an existing lambda.Function. It expects the
+// aws-cdk-lib, aws-lambda, aws-cloudfront and aws-cloudfront-origins modules
+// to be imported as cdk, lambda, cloudfront and origins.
+func CDK(r *router.Router, fn Function, opts CDKOptions) ([]byte, error) {
+	fn = fn.withDefaults()
+	data := struct {
+		Name       string
+		Var        string
+		AuthType   string
+		InvokeMode string
+		CORS       *router.CORSConfig
+		CloudFront bool
+		IAM        bool
+	}{
+		Name:       fn.Name,
+		Var:        orDefault(opts.FunctionVar, "fn"),
+		AuthType:   fn.AuthType,
+		InvokeMode: fn.InvokeMode,
+		CORS:       urlCORS(r),
+		CloudFront: opts.CloudFront,
+		IAM:        fn.AuthType == "AWS_IAM",
+	}
+	var buf bytes.Buffer
+	if err := cdkTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func tsList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", `\'`) + "'"
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func cdkMethods(methods []string) string {
+	values := make([]string, len(methods))
+	for i, m := range methods {
+		if m == "*" {
+			m = "ALL"
+		}
+		values[i] = "lambda.HttpMethod." + strings.ToUpper(m)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
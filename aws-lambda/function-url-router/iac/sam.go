@@ -0,0 +1,238 @@
+package iac
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+	"gopkg.in/yaml.v3"
+)
+
+// Function describes the Lambda function and Function URL the generators
+// emit. Empty fields use the defaults noted below.
+type Function struct {
+	// Name is the logical ID or resource name. Defaults to "Function".
+	Name string
+	// CodeURI is the directory or zip with the bootstrap binary. Defaults
+	// to ".".
+	CodeURI string
+	// Handler defaults to "bootstrap".
+	Handler string
+	// Runtime defaults to "provided.al2023".
+	Runtime string
+	// Architecture defaults to "arm64".
+	Architecture string
+	MemorySize   int
+	Timeout      int
+	// AuthType is "NONE" or "AWS_IAM". Defaults to "NONE".
+	AuthType string
+	// InvokeMode is "BUFFERED" or "RESPONSE_STREAM". Defaults to
+	// "BUFFERED".
+	InvokeMode string
+}
+
+func (f Function) withDefaults() Function {
+	f.Name = orDefault(f.Name, "Function")
+	f.CodeURI = orDefault(f.CodeURI, ".")
+	f.Handler = orDefault(f.Handler, "bootstrap")
+	f.Runtime = orDefault(f.Runtime, "provided.al2023")
+	f.Architecture = orDefault(f.Architecture, "arm64")
+	f.AuthType = orDefault(f.AuthType, "NONE")
+	f.InvokeMode = orDefault(f.InvokeMode, "BUFFERED")
+	return f
+}
+
+type SAMOptions struct {
+	// APIGateway adds an HttpApi event for every registered route, so the
+	// function is also reachable through API Gateway.
+	APIGateway bool
+	// Principals are granted lambda:InvokeFunctionUrl when AuthType is
+	// AWS_IAM. With AuthType NONE the URL is public.
+	Principals []string
+}
+
+type samTemplate struct {
+	AWSTemplateFormatVersion string                 `yaml:"AWSTemplateFormatVersion"`
+	Transform                string                 `yaml:"Transform"`
+	Resources                map[string]samResource `yaml:"Resources"`
+	Outputs                  map[string]samOutput   `yaml:"Outputs"`
+}
+
+type samResource struct {
+	Type       string      `yaml:"Type"`
+	Properties interface{} `yaml:"Properties"`
+}
+
+type samOutput struct {
+	Value interface{} `yaml:"Value"`
+}
+
+type samFunction struct {
+	CodeURI           string              `yaml:"CodeUri"`
+	Handler           string              `yaml:"Handler"`
+	Runtime           string              `yaml:"Runtime"`
+	Architectures     []string            `yaml:"Architectures"`
+	MemorySize        int                 `yaml:"MemorySize,omitempty"`
+	Timeout           int                 `yaml:"Timeout,omitempty"`
+	FunctionURLConfig samURLConfig        `yaml:"FunctionUrlConfig"`
+	Events            map[string]samEvent `yaml:"Events,omitempty"`
+}
+
+type samURLConfig struct {
+	AuthType   string   `yaml:"AuthType"`
+	InvokeMode string   `yaml:"InvokeMode"`
+	Cors       *samCORS `yaml:"Cors,omitempty"`
+}
+
+type samCORS struct {
+	AllowOrigins     []string `yaml:"AllowOrigins,omitempty"`
+	AllowMethods     []string `yaml:"AllowMethods,omitempty"`
+	AllowHeaders     []string `yaml:"AllowHeaders,omitempty"`
+	ExposeHeaders    []string `yaml:"ExposeHeaders,omitempty"`
+	AllowCredentials bool     `yaml:"AllowCredentials,omitempty"`
+	MaxAge           int      `yaml:"MaxAge,omitempty"`
+}
+
+type samEvent struct {
+	Type       string            `yaml:"Type"`
+	Properties map[string]string `yaml:"Properties"`
+}
+
+type samPermission struct {
+	Action              string      `yaml:"Action"`
+	FunctionName        interface{} `yaml:"FunctionName"`
+	Principal           string      `yaml:"Principal"`
+	FunctionURLAuthType string      `yaml:"FunctionUrlAuthType"`
+}
+
+// SAM renders a SAM template with the function, its Function URL, the URL
+// invoke permissions and, optionally, API Gateway routes for r. The URL's
+// CORS settings come from r.CORS().
+func SAM(r *router.Router, fn Function, opts SAMOptions) ([]byte, error) {
+	fn = fn.withDefaults()
+	props := samFunction{
+		CodeURI:           fn.CodeURI,
+		Handler:           fn.Handler,
+		Runtime:           fn.Runtime,
+		Architectures:     []string{fn.Architecture},
+		MemorySize:        fn.MemorySize,
+		Timeout:           fn.Timeout,
+		FunctionURLConfig: samURLConfig{AuthType: fn.AuthType, InvokeMode: fn.InvokeMode},
+	}
+	if cors := urlCORS(r); cors != nil {
+		props.FunctionURLConfig.Cors = &samCORS{
+			AllowOrigins:     cors.AllowOrigins,
+			AllowMethods:     cors.AllowMethods,
+			AllowHeaders:     cors.AllowHeaders,
+			ExposeHeaders:    cors.ExposeHeaders,
+			AllowCredentials: cors.AllowCredentials,
+			MaxAge:           cors.MaxAge,
+		}
+	}
+	if opts.APIGateway {
+		props.Events = make(map[string]samEvent)
+		for _, route := range r.Routes() {
+			props.Events[eventName(route)] = samEvent{
+				Type:       "HttpApi",
+				Properties: map[string]string{"Method": route.Method, "Path": route.Path},
+			}
+		}
+	}
+
+	tmpl := samTemplate{
+		AWSTemplateFormatVersion: "2010-09-09",
+		Transform:                "AWS::Serverless-2016-10-31",
+		Resources: map[string]samResource{
+			fn.Name: {Type: "AWS::Serverless::Function", Properties: props},
+		},
+		Outputs: map[string]samOutput{
+			fn.Name + "Url": {Value: map[string]string{"Fn::GetAtt": fn.Name + "Url.FunctionUrl"}},
+		},
+	}
+	principals := opts.Principals
+	if fn.AuthType == "NONE" {
+		principals = []string{"*"}
+	}
+	for i, principal := range principals {
+		tmpl.Resources[fn.Name+"UrlPermission"+suffix(i)] = samResource{
+			Type: "AWS::Lambda::Permission",
+			Properties: samPermission{
+				Action:              "lambda:InvokeFunctionUrl",
+				FunctionName:        map[string]string{"Ref": fn.Name},
+				Principal:           principal,
+				FunctionURLAuthType: fn.AuthType,
+			},
+		}
+	}
+	return marshalYAML(tmpl)
+}
+
+func marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// eventName builds a logical ID such as GetUsersId from GET /users/{id}.
+func eventName(route router.Route) string {
+	var b strings.Builder
+	for _, part := range nonAlphanumeric.Split(strings.ToLower(route.Method)+" "+route.Path, -1) {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	if route.Path == "/" {
+		b.WriteString("Root")
+	}
+	return b.String()
+}
+
+// urlCORS converts the router's CORS config to Function URL settings, which
+// take a single method list for the whole URL and cap MaxAge at a day.
+func urlCORS(r *router.Router) *router.CORSConfig {
+	config := r.CORS()
+	if config == nil {
+		return nil
+	}
+	cors := *config
+	if len(cors.AllowMethods) == 0 {
+		seen := make(map[string]bool)
+		for _, route := range r.Routes() {
+			if !seen[route.Method] {
+				seen[route.Method] = true
+				cors.AllowMethods = append(cors.AllowMethods, route.Method)
+			}
+		}
+		sort.Strings(cors.AllowMethods)
+	}
+	if cors.MaxAge > 86400 {
+		cors.MaxAge = 86400
+	}
+	return &cors
+}
+
+func suffix(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.Itoa(i + 1)
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
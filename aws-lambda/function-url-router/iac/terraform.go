@@ -0,0 +1,152 @@
+package iac
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+type TerraformOptions struct {
+	// FunctionRef is the expression for the function name. Defaults to
+	// aws_lambda_function.<name>.function_name.
+	FunctionRef string
+	// CloudFront adds a distribution in front of the Function URL. With
+	// AuthType AWS_IAM it signs origin requests through origin access
+	// control.
+	CloudFront bool
+}
+
+var terraformTemplate = template.Must(template.New("terraform").Funcs(template.FuncMap{
+	"list": hclList,
+}).Parse(`resource "aws_lambda_function_url" "{{.Name}}" {
+  function_name      = {{.FunctionRef}}
+  authorization_type = "{{.AuthType}}"
+  invoke_mode        = "{{.InvokeMode}}"
+{{- with .CORS}}
+
+  cors {
+    allow_origins     = {{list .AllowOrigins}}
+{{- if .AllowMethods}}
+    allow_methods     = {{list .AllowMethods}}
+{{- end}}
+{{- if .AllowHeaders}}
+    allow_headers     = {{list .AllowHeaders}}
+{{- end}}
+{{- if .ExposeHeaders}}
+    expose_headers    = {{list .ExposeHeaders}}
+{{- end}}
+    allow_credentials = {{.AllowCredentials}}
+{{- if .MaxAge}}
+    max_age           = {{.MaxAge}}
+{{- end}}
+  }
+{{- end}}
+}
+{{- if .CloudFront}}
+{{- if .IAM}}
+
+resource "aws_cloudfront_origin_access_control" "{{.Name}}" {
+  name                              = "{{.Name}}-function-url"
+  origin_access_control_origin_type = "lambda"
+  signing_behavior                  = "always"
+  signing_protocol                  = "sigv4"
+}
+
+resource "aws_lambda_permission" "{{.Name}}_cloudfront" {
+  statement_id           = "AllowCloudFrontInvokeFunctionUrl"
+  action                 = "lambda:InvokeFunctionUrl"
+  function_name          = {{.FunctionRef}}
+  principal              = "cloudfront.amazonaws.com"
+  source_arn             = aws_cloudfront_distribution.{{.Name}}.arn
+  function_url_auth_type = "AWS_IAM"
+}
+{{- end}}
+
+resource "aws_cloudfront_distribution" "{{.Name}}" {
+  enabled = true
+
+  origin {
+    domain_name = split("/", aws_lambda_function_url.{{.Name}}.function_url)[2]
+    origin_id   = "function-url"
+{{- if .IAM}}
+
+    origin_access_control_id = aws_cloudfront_origin_access_control.{{.Name}}.id
+{{- end}}
+
+    custom_origin_config {
+      http_port              = 80
+      https_port             = 443
+      origin_protocol_policy = "https-only"
+      origin_ssl_protocols   = ["TLSv1.2"]
+    }
+  }
+
+  default_cache_behavior {
+    target_origin_id         = "function-url"
+    viewer_protocol_policy   = "redirect-to-https"
+    allowed_methods          = ["DELETE", "GET", "HEAD", "OPTIONS", "PATCH", "POST", "PUT"]
+    cached_methods           = ["GET", "HEAD"]
+    cache_policy_id          = "4135ea2d-6df8-44a3-9df3-4b5a84be39ad" # Managed-CachingDisabled
+    origin_request_policy_id = "b689b0a8-53d0-40ab-baf2-68738e2966ac" # Managed-AllViewerExceptHostHeader
+  }
+
+  restrictions {
+    geo_restriction {
+      restriction_type = "none"
+    }
+  }
+
+  viewer_certificate {
+    cloudfront_default_certificate = true
+  }
+}
+{{- end}}
+
+output "{{.Name}}_url" {
+  value = {{if .CloudFront}}"https://${aws_cloudfront_distribution.{{.Name}}.domain_name}/"{{else}}aws_lambda_function_url.{{.Name}}.function_url{{end}}
+}
+`))
+
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// Terraform renders an aws_lambda_function_url for fn, with CORS settings
+// from r.CORS(), and optionally a CloudFront distribution in front of it.
+// The aws_lambda_function itself is expected to be defined elsewhere.
+func Terraform(r *router.Router, fn Function, opts TerraformOptions) ([]byte, error) {
+	fn = fn.withDefaults()
+	name := strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(fn.Name), "_"), "_")
+	data := struct {
+		Name        string
+		FunctionRef string
+		AuthType    string
+		InvokeMode  string
+		CORS        *router.CORSConfig
+		CloudFront  bool
+		IAM         bool
+	}{
+		Name:        name,
+		FunctionRef: orDefault(opts.FunctionRef, "aws_lambda_function."+name+".function_name"),
+		AuthType:    fn.AuthType,
+		InvokeMode:  fn.InvokeMode,
+		CORS:        urlCORS(r),
+		CloudFront:  opts.CloudFront,
+		IAM:         fn.AuthType == "AWS_IAM",
+	}
+	var buf bytes.Buffer
+	if err := terraformTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func hclList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,244 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const JSONAPIMediaType = "application/vnd.api+json"
+
+type JSONAPIDocument struct {
+	Data     interface{}            `json:"data"`
+	Included []*JSONAPIResource     `json:"included,omitempty"`
+	Meta     map[string]interface{} `json:"meta,omitempty"`
+}
+
+type JSONAPIResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id"`
+	Attributes    map[string]interface{}         `json:"attributes,omitempty"`
+	Relationships map[string]JSONAPIRelationship `json:"relationships,omitempty"`
+}
+
+type JSONAPIIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type JSONAPIRelationship struct {
+	Data interface{} `json:"data"`
+}
+
+type JSONAPIError struct {
+	ID     string              `json:"id,omitempty"`
+	Status string              `json:"status,omitempty"`
+	Code   string              `json:"code,omitempty"`
+	Title  string              `json:"title,omitempty"`
+	Detail string              `json:"detail,omitempty"`
+	Source *JSONAPIErrorSource `json:"source,omitempty"`
+}
+
+type JSONAPIErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+}
+
+type jsonapiErrorDocument struct {
+	Errors []JSONAPIError `json:"errors"`
+}
+
+// MarshalJSONAPI builds a JSON:API document from a struct pointer or a slice
+// of them. Fields are described with `jsonapi` tags:
+//
+//	ID     string  `jsonapi:"primary,articles"`
+//	Title  string  `jsonapi:"attr,title,omitempty"`
+//	Author *Person `jsonapi:"relation,author"`
+//
+// Related resources are added to the included section once each.
+func MarshalJSONAPI(v interface{}) (*JSONAPIDocument, error) {
+	doc := &JSONAPIDocument{}
+	included := newJSONAPIIncluded()
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice {
+		data := make([]*JSONAPIResource, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			res, err := jsonapiResource(rv.Index(i), included)
+			if err != nil {
+				return nil, err
+			}
+			if res != nil {
+				data = append(data, res)
+			}
+		}
+		doc.Data = data
+	} else {
+		res, err := jsonapiResource(rv, included)
+		if err != nil {
+			return nil, err
+		}
+		if res != nil {
+			doc.Data = res
+		}
+	}
+
+	for _, res := range included.resources {
+		if !included.isPrimary(res, doc.Data) {
+			doc.Included = append(doc.Included, res)
+		}
+	}
+	return doc, nil
+}
+
+func JSONAPI(status int, v interface{}) Response {
+	doc, err := MarshalJSONAPI(v)
+	if err != nil {
+		return JSONAPIErrors(http.StatusInternalServerError, JSONAPIError{
+			Status: "500",
+			Title:  "Internal Server Error",
+		})
+	}
+	return Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": JSONAPIMediaType},
+		Body:       doc,
+	}
+}
+
+func JSONAPIErrors(status int, errs ...JSONAPIError) Response {
+	for i := range errs {
+		if errs[i].Status == "" {
+			errs[i].Status = fmt.Sprint(status)
+		}
+	}
+	return Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": JSONAPIMediaType},
+		Body:       jsonapiErrorDocument{Errors: errs},
+	}
+}
+
+type jsonapiIncluded struct {
+	seen      map[JSONAPIIdentifier]bool
+	resources []*JSONAPIResource
+}
+
+func newJSONAPIIncluded() *jsonapiIncluded {
+	return &jsonapiIncluded{seen: make(map[JSONAPIIdentifier]bool)}
+}
+
+func (inc *jsonapiIncluded) add(res *JSONAPIResource) {
+	key := JSONAPIIdentifier{Type: res.Type, ID: res.ID}
+	if inc.seen[key] {
+		return
+	}
+	inc.seen[key] = true
+	inc.resources = append(inc.resources, res)
+}
+
+func (inc *jsonapiIncluded) isPrimary(res *JSONAPIResource, data interface{}) bool {
+	switch d := data.(type) {
+	case *JSONAPIResource:
+		return d.Type == res.Type && d.ID == res.ID
+	case []*JSONAPIResource:
+		for _, p := range d {
+			if p.Type == res.Type && p.ID == res.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jsonapiResource(rv reflect.Value, included *jsonapiIncluded) (*JSONAPIResource, error) {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("jsonapi: cannot marshal %s", rv.Kind())
+	}
+
+	res := &JSONAPIResource{}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := field.Tag.Get("jsonapi")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		fv := rv.Field(i)
+		omitEmpty := len(parts) > 2 && parts[2] == "omitempty"
+
+		switch parts[0] {
+		case "primary":
+			if len(parts) < 2 {
+				return nil, errors.New("jsonapi: primary tag needs a type")
+			}
+			res.Type = parts[1]
+			res.ID = fmt.Sprint(fv.Interface())
+		case "attr":
+			if len(parts) < 2 {
+				return nil, errors.New("jsonapi: attr tag needs a name")
+			}
+			if omitEmpty && fv.IsZero() {
+				continue
+			}
+			if res.Attributes == nil {
+				res.Attributes = make(map[string]interface{})
+			}
+			res.Attributes[parts[1]] = fv.Interface()
+		case "relation":
+			if len(parts) < 2 {
+				return nil, errors.New("jsonapi: relation tag needs a name")
+			}
+			if omitEmpty && fv.IsZero() {
+				continue
+			}
+			rel, err := jsonapiRelationship(fv, included)
+			if err != nil {
+				return nil, err
+			}
+			if res.Relationships == nil {
+				res.Relationships = make(map[string]JSONAPIRelationship)
+			}
+			res.Relationships[parts[1]] = rel
+		default:
+			return nil, fmt.Errorf("jsonapi: unknown tag %q on %s", parts[0], field.Name)
+		}
+	}
+	if res.Type == "" {
+		return nil, fmt.Errorf("jsonapi: %s has no primary field", rt.Name())
+	}
+	return res, nil
+}
+
+func jsonapiRelationship(fv reflect.Value, included *jsonapiIncluded) (JSONAPIRelationship, error) {
+	if fv.Kind() == reflect.Slice {
+		ids := make([]JSONAPIIdentifier, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			res, err := jsonapiResource(fv.Index(i), included)
+			if err != nil {
+				return JSONAPIRelationship{}, err
+			}
+			if res != nil {
+				included.add(res)
+				ids = append(ids, JSONAPIIdentifier{Type: res.Type, ID: res.ID})
+			}
+		}
+		return JSONAPIRelationship{Data: ids}, nil
+	}
+
+	res, err := jsonapiResource(fv, included)
+	if err != nil || res == nil {
+		return JSONAPIRelationship{}, err
+	}
+	included.add(res)
+	return JSONAPIRelationship{Data: JSONAPIIdentifier{Type: res.Type, ID: res.ID}}, nil
+}
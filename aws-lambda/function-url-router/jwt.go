@@ -0,0 +1,302 @@
+package router
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrUnknownKey   = errors.New("unknown signing key")
+)
+
+// JWTKeySet resolves the RSA public key for a token's kid header.
+type JWTKeySet interface {
+	PublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+type JWTConfig struct {
+	// Issuer and Audience must match the iss and aud claims when set,
+	// whichever key set verified the token.
+	Issuer   string
+	Audience string
+	Keys     JWTKeySet
+	// DevKeys is consulted only in dev mode, before Keys, so tokens from a
+	// local issuer such as devauth.Issuer are accepted without Cognito.
+	// Configure that issuer with the same iss and aud claims.
+	DevKeys JWTKeySet
+	// Leeway allows for clock skew when checking exp and nbf.
+	Leeway time.Duration
+	// AllowMissingExp accepts tokens without an exp claim, which are
+	// otherwise rejected since they would never expire.
+	AllowMissingExp bool
+}
+
+// JWT verifies RS256 bearer tokens from the Authorization header. Requests
+// without a valid token get 401; verified claims are returned by Authorizer,
+// so RequireAuthorizer can apply policies on top.
+func JWT(config JWTConfig) MiddlewareFunc {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			token, ok := strings.CutPrefix(req.Headers["authorization"], "Bearer ")
+			if !ok {
+				return unauthorized()
+			}
+			claims, err := config.verify(ctx, token)
+			if err != nil {
+				if r := routerFromContext(ctx); r != nil {
+					r.logError(ctx, "Rejected token", err)
+				}
+				return unauthorized()
+			}
+			ctx = context.WithValue(ctx, jwtClaimsKey, AuthorizerContext(claims))
+			return next.ServeHTTP(ctx, req)
+		})
+	}
+}
+
+func unauthorized() Response {
+	return Response{
+		StatusCode: http.StatusUnauthorized,
+		Headers:    map[string]string{"Content-Type": "application/json", "WWW-Authenticate": "Bearer"},
+		Body:       map[string]string{"error": "Unauthorized"},
+	}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func (c JWTConfig) verify(ctx context.Context, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var key *rsa.PublicKey
+	if c.DevKeys != nil && IsDevMode(ctx) {
+		key, _ = c.DevKeys.PublicKey(ctx, header.Kid)
+	}
+	if key == nil {
+		if c.Keys == nil {
+			return nil, ErrUnknownKey
+		}
+		if key, err = c.Keys.PublicKey(ctx, header.Kid); err != nil {
+			return nil, err
+		}
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok && !c.AllowMissingExp {
+		return nil, fmt.Errorf("%w: no exp claim", ErrInvalidToken)
+	}
+	if ok && now.After(time.Unix(int64(exp), 0).Add(c.Leeway)) {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(c.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("%w: not yet valid", ErrInvalidToken)
+	}
+	if c.Issuer != "" && claims["iss"] != c.Issuer {
+		return nil, fmt.Errorf("%w: issuer %v", ErrInvalidToken, claims["iss"])
+	}
+	if c.Audience != "" && !hasAudience(claims, c.Audience) {
+		return nil, fmt.Errorf("%w: audience", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// hasAudience accepts aud as a string or a list. Cognito access tokens carry
+// client_id instead, which is checked as well.
+func hasAudience(claims map[string]interface{}, audience string) bool {
+	switch aud := claims["aud"].(type) {
+	case string:
+		if aud == audience {
+			return true
+		}
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return claims["client_id"] == audience
+}
+
+// JWK is a JSON Web Key. Only RSA keys are used.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK encodes key for a JWKS document.
+func NewJWK(kid string, key *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func (k JWK) PublicKey() (*rsa.PublicKey, error) {
+	if k.Kty != "RSA" {
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+}
+
+type remoteJWKS struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+	// fetching is closed when the fetch in progress, if any, is done.
+	// The mutex isn't held during the fetch, so a slow JWKS endpoint
+	// doesn't hold up tokens signed with keys already cached.
+	fetching chan struct{}
+}
+
+// NewJWKS returns a key set backed by the JWKS document at url, such as
+// https://cognito-idp.<region>.amazonaws.com/<pool>/.well-known/jwks.json.
+// Keys are cached and refetched at most once a minute when an unknown kid
+// shows up.
+func NewJWKS(url string) JWTKeySet {
+	return &remoteJWKS{url: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (s *remoteJWKS) PublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	if key, ok := s.keys[kid]; ok {
+		s.mu.Unlock()
+		return key, nil
+	}
+	wait := s.fetching
+	if wait == nil {
+		if time.Since(s.fetched) < time.Minute {
+			s.mu.Unlock()
+			return nil, ErrUnknownKey
+		}
+		s.fetched = time.Now()
+		done := make(chan struct{})
+		s.fetching = done
+		s.mu.Unlock()
+
+		keys, err := s.fetch(ctx)
+		s.mu.Lock()
+		if err == nil {
+			s.keys = keys
+		}
+		s.fetching = nil
+		close(done)
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		s.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (s *remoteJWKS) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: %s", s.url, resp.Status)
+	}
+	var set JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if key, err := k.PublicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
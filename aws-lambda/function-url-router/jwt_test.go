@@ -0,0 +1,138 @@
+package router
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type staticKeys map[string]*rsa.PublicKey
+
+func (k staticKeys) PublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if key, ok := k[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func signJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(jwtHeader{Alg: "RS256", Kid: kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTClaims(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := staticKeys{"k": &key.PublicKey}
+	exp := float64(time.Now().Add(time.Hour).Unix())
+	base := JWTConfig{Issuer: "https://issuer", Audience: "client"}
+
+	cases := []struct {
+		name   string
+		config func(JWTConfig) JWTConfig
+		dev    bool
+		claims map[string]interface{}
+		ok     bool
+	}{
+		{"valid", nil, false, map[string]interface{}{"iss": "https://issuer", "aud": "client", "exp": exp}, true},
+		{"no exp", nil, false, map[string]interface{}{"iss": "https://issuer", "aud": "client"}, false},
+		{"no exp allowed", func(c JWTConfig) JWTConfig { c.AllowMissingExp = true; return c }, false, map[string]interface{}{"iss": "https://issuer", "aud": "client"}, true},
+		{"expired", nil, false, map[string]interface{}{"iss": "https://issuer", "aud": "client", "exp": float64(time.Now().Add(-time.Hour).Unix())}, false},
+		{"dev key", nil, true, map[string]interface{}{"iss": "https://issuer", "aud": "client", "exp": exp}, true},
+		{"dev key wrong issuer", nil, true, map[string]interface{}{"iss": "http://localhost/devauth", "aud": "client", "exp": exp}, false},
+		{"dev key wrong audience", nil, true, map[string]interface{}{"iss": "https://issuer", "aud": "other", "exp": exp}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := base
+			if c.dev {
+				config.DevKeys = keys
+			} else {
+				config.Keys = keys
+			}
+			if c.config != nil {
+				config = c.config(config)
+			}
+			r := newTestRouter()
+			r.SetDevMode(c.dev)
+			r.UsePre(JWT(config), MiddlewareConfig{})
+			r.AddRoute(http.MethodPost, "/me", textHandler("ok"))
+
+			req := benchRequest(http.MethodPost, "/me")
+			req.Headers = map[string]string{"authorization": "Bearer " + signJWT(t, key, "k", c.claims)}
+			resp := r.HandleRequest(context.Background(), req)
+			if got := resp.StatusCode == http.StatusOK; got != c.ok {
+				t.Errorf("status = %d, want accepted %v", resp.StatusCode, c.ok)
+			}
+		})
+	}
+}
+
+// TestJWKSFetchDoesNotBlockCachedKeys checks that a slow JWKS fetch for an
+// unknown kid doesn't hold up lookups of cached keys.
+func TestJWKSFetchDoesNotBlockCachedKeys(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		json.NewEncoder(w).Encode(JWKSet{})
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cached := &rsa.PublicKey{}
+	s := NewJWKS(srv.URL).(*remoteJWKS)
+	s.keys = map[string]*rsa.PublicKey{"cached": cached}
+
+	fetched := make(chan error)
+	go func() {
+		_, err := s.PublicKey(context.Background(), "new")
+		fetched <- err
+	}()
+	for {
+		s.mu.Lock()
+		started := s.fetching != nil
+		s.mu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	lookup := make(chan *rsa.PublicKey)
+	go func() {
+		key, _ := s.PublicKey(context.Background(), "cached")
+		lookup <- key
+	}()
+	select {
+	case key := <-lookup:
+		if key != cached {
+			t.Error("cached key lookup returned another key")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cached key lookup blocked on the JWKS fetch")
+	}
+
+	release <- struct{}{}
+	if err := <-fetched; !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("unknown kid = %v, want ErrUnknownKey", err)
+	}
+}
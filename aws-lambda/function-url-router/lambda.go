@@ -0,0 +1,96 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// LambdaHandler returns a handler for lambda.Start that speaks the Function
+// URL response contract: the body is serialized to a string (JSON for
+// structured values, passthrough for strings and bytes) and Set-Cookie
+// headers are moved to Cookies.
+func (r *Router) LambdaHandler() func(context.Context, events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+	return func(ctx context.Context, req events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+		return r.toLambdaResponse(r.HandleRequest(ctx, req)), nil
+	}
+}
+
+func (r *Router) toLambdaResponse(resp Response) events.LambdaFunctionURLResponse {
+	resp = stripBodyIfNotAllowed(resp)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := writeBody(buf, r.outputCodec(), resp.Body); err != nil {
+		r.logError(context.Background(), "Error encoding response body", err)
+		return events.LambdaFunctionURLResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       `{"error":"Internal Server Error"}`,
+		}
+	}
+
+	headers, cookies := lambdaHeaders(resp)
+	out := events.LambdaFunctionURLResponse{
+		StatusCode:      resp.StatusCode,
+		Headers:         headers,
+		Body:            buf.String(),
+		IsBase64Encoded: resp.IsBase64Encoded,
+		Cookies:         cookies,
+	}
+	if _, ok := resp.Body.(string); !ok && buf.Len() > 0 && len(resp.HeaderValues("Content-Type")) == 0 {
+		out.Headers["Content-Type"] = "application/json"
+	}
+	return out
+}
+
+// multiValueHeaders returns the headers of out, converted from resp by
+// toLambdaResponse, as lists for events that support repeated headers. Values
+// lambdaHeaders joined with ", " are taken from resp unjoined, and cookies
+// are sent as Set-Cookie headers.
+func multiValueHeaders(resp Response, out events.LambdaFunctionURLResponse) map[string][]string {
+	headers := make(map[string][]string, len(out.Headers)+1)
+	for k, v := range out.Headers {
+		headers[k] = []string{v}
+	}
+	for k, values := range resp.MultiValueHeaders {
+		if strings.EqualFold(k, "Set-Cookie") {
+			continue
+		}
+		var merged []string
+		if v, ok := resp.Headers[k]; ok {
+			merged = append(merged, v)
+		}
+		headers[k] = append(merged, values...)
+	}
+	if len(out.Cookies) > 0 {
+		headers["Set-Cookie"] = out.Cookies
+	}
+	return headers
+}
+
+// lambdaHeaders folds Headers and MultiValueHeaders into the single-value
+// map Function URLs expect and pulls Set-Cookie values out into cookies.
+func lambdaHeaders(resp Response) (map[string]string, []string) {
+	headers := getHeaders()
+	var cookies []string
+	for k, v := range resp.Headers {
+		if strings.EqualFold(k, "Set-Cookie") {
+			cookies = append(cookies, v)
+			continue
+		}
+		headers[k] = v
+	}
+	for k, values := range resp.MultiValueHeaders {
+		if strings.EqualFold(k, "Set-Cookie") {
+			cookies = append(cookies, values...)
+			continue
+		}
+		if existing, ok := headers[k]; ok {
+			values = append([]string{existing}, values...)
+		}
+		headers[k] = strings.Join(values, ", ")
+	}
+	return headers, cookies
+}
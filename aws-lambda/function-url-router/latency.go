@@ -0,0 +1,151 @@
+package router
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Latency buckets grow by 10% from 100µs, so percentiles are within 10% of
+// the true value, up to 15 minutes, Lambda's maximum timeout.
+const (
+	latencyBase   = 100 * time.Microsecond
+	latencyGrowth = 1.1
+)
+
+var latencyBuckets = func() int {
+	return int(math.Ceil(math.Log(float64(15*time.Minute)/float64(latencyBase))/math.Log(latencyGrowth))) + 1
+}()
+
+type LatencyConfig struct {
+	// Interval between reports. Defaults to one minute.
+	Interval time.Duration
+	// Report receives the latency of every route that served a request
+	// since the previous report, e.g. emf.LatencyReporter or
+	// otelmetrics.LatencyReporter. It runs at the end of the first request
+	// after each interval, since a frozen Lambda environment can't run a
+	// timer.
+	Report func(context.Context, []RouteLatency)
+}
+
+// RouteLatency summarizes a route's latency over one reporting interval.
+// Requests that matched no route are reported with Route "unmatched".
+type RouteLatency struct {
+	Method string
+	Route  string
+	Count  int
+	P50    time.Duration
+	P95    time.Duration
+	P99    time.Duration
+	Max    time.Duration
+}
+
+// SetLatencyReporting keeps a latency histogram per route in memory and
+// reports percentiles from it periodically, so slow routes show up without
+// querying raw access logs.
+func (r *Router) SetLatencyReporting(config LatencyConfig) {
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	r.latency = &latencyTracker{
+		config: config,
+		routes: make(map[routeSeries]*latencyHistogram),
+		since:  time.Now(),
+	}
+}
+
+type latencyHistogram struct {
+	counts []uint32
+	count  int
+	max    time.Duration
+}
+
+type latencyTracker struct {
+	config LatencyConfig
+	mu     sync.Mutex
+	routes map[routeSeries]*latencyHistogram
+	since  time.Time
+}
+
+func latencyBucket(d time.Duration) int {
+	if d <= latencyBase {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(float64(d)/float64(latencyBase)) / math.Log(latencyGrowth)))
+	if i >= latencyBuckets {
+		return latencyBuckets - 1
+	}
+	return i
+}
+
+func latencyBucketBound(i int) time.Duration {
+	return time.Duration(float64(latencyBase) * math.Pow(latencyGrowth, float64(i)))
+}
+
+func (t *latencyTracker) observe(ctx context.Context, method, route string, d time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	t.mu.Lock()
+	series := routeSeries{method: method, route: route}
+	h, ok := t.routes[series]
+	if !ok {
+		h = &latencyHistogram{counts: make([]uint32, latencyBuckets)}
+		t.routes[series] = h
+	}
+	h.counts[latencyBucket(d)]++
+	h.count++
+	if d > h.max {
+		h.max = d
+	}
+
+	var report []RouteLatency
+	if time.Since(t.since) >= t.config.Interval {
+		report = t.snapshot()
+		t.routes = make(map[routeSeries]*latencyHistogram)
+		t.since = time.Now()
+	}
+	t.mu.Unlock()
+
+	if report != nil && t.config.Report != nil {
+		t.config.Report(ctx, report)
+	}
+}
+
+func (t *latencyTracker) snapshot() []RouteLatency {
+	report := make([]RouteLatency, 0, len(t.routes))
+	for series, h := range t.routes {
+		report = append(report, RouteLatency{
+			Method: series.method,
+			Route:  series.route,
+			Count:  h.count,
+			P50:    h.percentile(0.50),
+			P95:    h.percentile(0.95),
+			P99:    h.percentile(0.99),
+			Max:    h.max,
+		})
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return routeSeries{report[i].Method, report[i].Route}.less(routeSeries{report[j].Method, report[j].Route})
+	})
+	return report
+}
+
+// percentile returns the upper bound of the bucket holding the p-th
+// percentile, capped at the largest value seen.
+func (h *latencyHistogram) percentile(p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(h.count)))
+	seen := 0
+	for i, c := range h.counts {
+		seen += int(c)
+		if seen >= rank {
+			if bound := latencyBucketBound(i); bound < h.max {
+				return bound
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
@@ -0,0 +1,231 @@
+package router
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const defaultShutdownTimeout = 10 * time.Second
+
+type localServerConfig struct {
+	certFile        string
+	keyFile         string
+	selfSigned      bool
+	listener        net.Listener
+	shutdownTimeout time.Duration
+	configure       []func(*http.Server)
+	requestContext  LocalRequestContext
+	fallback        Handler
+	chaos           []ChaosConfig
+	concurrency     int
+	throttleStatus  int
+	coldStart       *ColdStartConfig
+}
+
+type LocalServerOption func(*localServerConfig)
+
+// WithTLS serves HTTPS using the given certificate and key files.
+func WithTLS(certFile, keyFile string) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.certFile = certFile
+		c.keyFile = keyFile
+	}
+}
+
+// WithSelfSignedTLS serves HTTPS with a certificate for localhost generated
+// at startup. Browsers will warn about it once.
+func WithSelfSignedTLS() LocalServerOption {
+	return func(c *localServerConfig) {
+		c.selfSigned = true
+	}
+}
+
+// WithTimeouts sets the read, write and idle timeouts of the http.Server.
+func WithTimeouts(read, write, idle time.Duration) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.configure = append(c.configure, func(s *http.Server) {
+			s.ReadTimeout = read
+			s.WriteTimeout = write
+			s.IdleTimeout = idle
+		})
+	}
+}
+
+func WithMaxHeaderBytes(n int) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.configure = append(c.configure, func(s *http.Server) {
+			s.MaxHeaderBytes = n
+		})
+	}
+}
+
+// WithServerConfig gives direct access to the http.Server before it starts.
+func WithServerConfig(fn func(*http.Server)) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.configure = append(c.configure, fn)
+	}
+}
+
+// WithShutdownTimeout bounds how long in-flight requests may take to drain
+// once the server is asked to stop.
+func WithShutdownTimeout(d time.Duration) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.shutdownTimeout = d
+	}
+}
+
+// WithListener serves on ln instead of listening on addr, e.g. a
+// 127.0.0.1:0 listener in tests.
+func WithListener(ln net.Listener) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.listener = ln
+	}
+}
+
+// WithRequestContext sets the account, API, domain, source IP and authorizer
+// values injected into local requests.
+func WithRequestContext(rc LocalRequestContext) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.requestContext = rc
+	}
+}
+
+// WithFallback serves requests that match no route and method with h instead
+// of the router's not found and method not allowed handlers, for example an
+// openapi.Mock for endpoints that are specified but not implemented yet.
+func WithFallback(h Handler) LocalServerOption {
+	return func(c *localServerConfig) {
+		c.fallback = h
+	}
+}
+
+// RunLocalServer serves router over HTTP until SIGINT or SIGTERM, then drains
+// in-flight requests and returns nil.
+func RunLocalServer(router *Router, addr string, logger *log.Logger, opts ...LocalServerOption) error {
+	return RunLocalServerContext(context.Background(), router, addr, logger, opts...)
+}
+
+// RunLocalServerContext is RunLocalServer that also shuts down when ctx is
+// done.
+func RunLocalServerContext(ctx context.Context, router *Router, addr string, logger *log.Logger, opts ...LocalServerOption) error {
+	if logger == nil {
+		logger = log.New(os.Stdout, "SERVER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	config := localServerConfig{shutdownTimeout: defaultShutdownTimeout}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	build := func(r *Router) http.Handler {
+		adapter := NewLambdaAdapter(r, logger)
+		adapter.SetRequestContext(config.requestContext)
+		adapter.SetFallback(config.fallback)
+		return newHTTPHandler(adapter)
+	}
+	handler := build(router)
+	if config.coldStart != nil {
+		handler = newColdStartHandler(*config.coldStart, build, handler, logger)
+	}
+	if len(config.chaos) > 0 {
+		handler = chaosHandler(config.chaos, handler, logger)
+	}
+	if config.concurrency > 0 {
+		handler = concurrencyHandler(config.concurrency, config.throttleStatus, handler, logger)
+	}
+	server := &http.Server{Addr: addr, Handler: handler}
+	for _, fn := range config.configure {
+		fn(server)
+	}
+	if config.selfSigned {
+		cert, err := selfSignedCertificate()
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	ln := config.listener
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", server.Addr); err != nil {
+			return err
+		}
+	}
+
+	router.PrintRoutes(logger.Writer())
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		if config.tls() {
+			logger.Printf("Starting local TLS server on %s", ln.Addr())
+			errc <- server.ServeTLS(ln, config.certFile, config.keyFile)
+		} else {
+			logger.Printf("Starting local server on %s", ln.Addr())
+			errc <- server.Serve(ln)
+		}
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Printf("Shutting down local server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (c *localServerConfig) tls() bool {
+	return c.selfSigned || c.certFile != ""
+}
+
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"function-url-router local server"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
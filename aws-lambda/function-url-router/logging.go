@@ -0,0 +1,125 @@
+package router
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+// Logger is what the router, the local adapter and the built-in middleware
+// log through. *slog.Logger implements it; the zapadapter and
+// zerologadapter packages wrap zap and zerolog loggers.
+type Logger interface {
+	LogAttrs(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr)
+}
+
+// SetLogger sends the router's logs to logger as structured records instead
+// of formatted lines. Request completions carry method, path, status,
+// duration_ms, request_id and cold_start attributes, which CloudWatch Logs
+// Insights discovers automatically when the handler writes JSON.
+func (r *Router) SetLogger(logger Logger) {
+	r.slogger = logger
+}
+
+// logCompletion logs a finished request. resp is a pointer because the
+// access log encodes a structured body to record its size, and the router
+// then sends that encoding instead of marshalling the body again.
+func (r *Router) logCompletion(ctx context.Context, req events.LambdaFunctionURLRequest, resp *Response, duration time.Duration, err error) {
+	coldStart := IsColdStart(ctx)
+	if r.accessLog != nil && r.accessLog.skip(req.RequestContext.HTTP.Path) {
+		return
+	}
+	if r.routing().healthPaths[MatchedRoute(ctx)] && (r.accessLog == nil || !r.accessLog.HealthChecks) {
+		return
+	}
+	level := completionLevel(resp.StatusCode, err)
+	if r.logLevel != nil && !r.logLevel.logCompletionAt(req.RequestContext.HTTP.Path, resp.StatusCode, level) {
+		return
+	}
+
+	requestID := requestID(ctx, req)
+	if r.accessLog != nil && (r.accessLog.Format != AccessLogDefault || r.accessLog.Template != nil) {
+		r.encodeForLog(resp)
+		r.writeAccessLog(ctx, r.accessLogEntry(ctx, req, *resp, duration, level, requestID, coldStart, err))
+		return
+	}
+	if r.slogger == nil {
+		r.logRequestCompletion(ctx, req, *resp, duration, err)
+		return
+	}
+
+	// Sized for every attribute below, so appending never reallocates.
+	attrs := make([]slog.Attr, 0, 13)
+	attrs = append(attrs,
+		slog.String("method", req.RequestContext.HTTP.Method),
+		slog.String("path", req.RequestContext.HTTP.Path),
+		slog.Int("status", resp.StatusCode),
+		slog.Float64("duration_ms", float64(duration)/float64(time.Millisecond)),
+		slog.String("request_id", requestID),
+		slog.Bool("cold_start", coldStart),
+	)
+	if coldStart {
+		attrs = append(attrs, slog.Float64("init_duration_ms", float64(InitDuration())/float64(time.Millisecond)))
+	}
+	if trace, ok := Trace(ctx); ok {
+		attrs = append(attrs, slog.String("trace_id", trace.TraceID))
+	}
+	if diag := Diagnostics(ctx); diag.FunctionName != "" || diag.Remaining != 0 {
+		attrs = append(attrs, slog.Float64("remaining_ms", float64(diag.Remaining)/float64(time.Millisecond)))
+		if diag.FunctionName != "" {
+			attrs = append(attrs,
+				slog.String("function_version", diag.FunctionVersion),
+				slog.Int("memory_limit_mb", diag.MemoryLimitMB),
+				slog.String("log_stream", diag.LogStream),
+			)
+		}
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	r.slogger.LogAttrs(ctx, level, "Request completed", attrs...)
+}
+
+// LogError logs msg and err through the router handling ctx, so packages
+// that provide middleware report errors the way the built-in ones do.
+func LogError(ctx context.Context, msg string, err error) {
+	if r := routerFromContext(ctx); r != nil {
+		r.logError(ctx, msg, err)
+		return
+	}
+	log.Printf("%s: %v", msg, err)
+}
+
+// logError logs msg and err through the structured logger when one is set.
+func (r *Router) logError(ctx context.Context, msg string, err error) {
+	if r.logLevel != nil && slog.LevelError < r.logLevel.Level {
+		return
+	}
+	trace, traced := Trace(ctx)
+	if r.slogger != nil {
+		attrs := []slog.Attr{slog.String("error", err.Error())}
+		if traced {
+			attrs = append(attrs, slog.String("trace_id", trace.TraceID))
+		}
+		r.slogger.LogAttrs(ctx, slog.LevelError, msg, attrs...)
+		return
+	}
+	if traced {
+		r.logger.Printf("%s: %v trace_id=%s", msg, err, trace.TraceID)
+		return
+	}
+	r.logger.Printf("%s: %v", msg, err)
+}
+
+// requestID prefers the Lambda invocation's request ID, which is what the
+// runtime's own log lines carry.
+func requestID(ctx context.Context, req events.LambdaFunctionURLRequest) string {
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		return lc.AwsRequestID
+	}
+	return req.RequestContext.RequestID
+}
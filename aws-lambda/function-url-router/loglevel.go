@@ -0,0 +1,86 @@
+package router
+
+import (
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"path"
+)
+
+// LogLevelConfig filters and samples the router's logs. Request completions
+// are logged at Info, Warn for 4xx responses and Error for 5xx responses and
+// panics; errors reported by the router and its middleware are logged at
+// Error.
+type LogLevelConfig struct {
+	// Level is the minimum level logged.
+	Level slog.Level
+	// SampleRates maps a status class ("2xx", "3xx", "4xx" or "5xx") to the
+	// fraction of completions logged, between 0 and 1. Classes without an
+	// entry are always logged, so {"2xx": 0.01} keeps 1% of successes and
+	// every failure.
+	SampleRates map[string]float64
+	// Routes overrides Level and SampleRates for some paths. The first
+	// entry whose Routes match the request path replaces both.
+	Routes []RouteLogLevel
+}
+
+type RouteLogLevel struct {
+	// Routes are path.Match patterns.
+	Routes      []string
+	Level       slog.Level
+	SampleRates map[string]float64
+}
+
+func (r *Router) SetLogLevel(config LogLevelConfig) {
+	r.logLevel = &config
+}
+
+func completionLevel(status int, err error) slog.Level {
+	switch {
+	case err != nil || status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// logCompletionAt reports whether a completion for p with status, logged at
+// level, passes the level filter and sampling.
+func (c *LogLevelConfig) logCompletionAt(p string, status int, level slog.Level) bool {
+	minLevel, rates := c.Level, c.SampleRates
+	for _, route := range c.Routes {
+		if matchesAny(route.Routes, p) {
+			minLevel, rates = route.Level, route.SampleRates
+			break
+		}
+	}
+	if level < minLevel {
+		return false
+	}
+	rate, ok := rates[statusClass(status)]
+	return !ok || rand.Float64() < rate
+}
+
+func statusClass(status int) string {
+	switch {
+	case status >= 500:
+		return "5xx"
+	case status >= 400:
+		return "4xx"
+	case status >= 300:
+		return "3xx"
+	default:
+		return "2xx"
+	}
+}
+
+func matchesAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, p); ok {
+			return true
+		}
+	}
+	return false
+}
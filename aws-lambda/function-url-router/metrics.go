@@ -0,0 +1,196 @@
+package router
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prometheus' default latency buckets, in seconds.
+var defaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type MetricsConfig struct {
+	// Path serves the metrics and defaults to "/metrics".
+	Path string
+	// Namespace prefixes metric names, so "orders" gives
+	// orders_http_requests_total.
+	Namespace string
+	// Buckets are the latency histogram's upper bounds in seconds.
+	Buckets []float64
+}
+
+// SetMetrics counts requests and records their latency per route when the
+// router runs as an http.Handler, through HTTPHandler or the local server,
+// and serves them at config.Path in the Prometheus text format. Requests
+// that match no route are reported with the route "unmatched".
+func (r *Router) SetMetrics(config MetricsConfig) {
+	if config.Path == "" {
+		config.Path = "/metrics"
+	}
+	if len(config.Buckets) == 0 {
+		config.Buckets = defaultMetricsBuckets
+	}
+	if config.Namespace != "" && !strings.HasSuffix(config.Namespace, "_") {
+		config.Namespace += "_"
+	}
+	config.Buckets = append([]float64(nil), config.Buckets...)
+	sort.Float64s(config.Buckets)
+	r.metrics = &httpMetrics{
+		config:    config,
+		requests:  make(map[requestSeries]uint64),
+		durations: make(map[routeSeries]*histogram),
+	}
+}
+
+type routeSeries struct {
+	method, route string
+}
+
+type requestSeries struct {
+	routeSeries
+	status int
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type httpMetrics struct {
+	config    MetricsConfig
+	mu        sync.Mutex
+	requests  map[requestSeries]uint64
+	durations map[routeSeries]*histogram
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(p)
+}
+
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (m *httpMetrics) handler(router *Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == m.config.Path {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+			m.write(w)
+			return
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		series := routeSeries{method: r.Method, route: "unmatched"}
+		if router.hasHandler(r.Method, r.URL.Path) {
+			series.route = r.URL.Path
+			if router.stripTrailingSlash {
+				series.route = strings.TrimRight(series.route, "/")
+			}
+		}
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.observe(series, status, time.Since(start))
+	})
+}
+
+func (m *httpMetrics) observe(series routeSeries, status int, d time.Duration) {
+	seconds := d.Seconds()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestSeries{series, status}]++
+	h, ok := m.durations[series]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.config.Buckets))}
+		m.durations[series] = h
+	}
+	for i, le := range m.config.Buckets {
+		if seconds <= le {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (m *httpMetrics) write(w http.ResponseWriter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	requests := m.config.Namespace + "http_requests_total"
+	fmt.Fprintf(out, "# HELP %s Requests handled, by method, route and status.\n# TYPE %s counter\n", requests, requests)
+	requestKeys := make([]requestSeries, 0, len(m.requests))
+	for k := range m.requests {
+		requestKeys = append(requestKeys, k)
+	}
+	sort.Slice(requestKeys, func(i, j int) bool {
+		a, b := requestKeys[i], requestKeys[j]
+		if a.routeSeries != b.routeSeries {
+			return a.routeSeries.less(b.routeSeries)
+		}
+		return a.status < b.status
+	})
+	for _, k := range requestKeys {
+		fmt.Fprintf(out, "%s{method=%s,route=%s,status=\"%d\"} %d\n", requests, labelValue(k.method), labelValue(k.route), k.status, m.requests[k])
+	}
+
+	durations := m.config.Namespace + "http_request_duration_seconds"
+	fmt.Fprintf(out, "# HELP %s Request latency, by method and route.\n# TYPE %s histogram\n", durations, durations)
+	durationKeys := make([]routeSeries, 0, len(m.durations))
+	for k := range m.durations {
+		durationKeys = append(durationKeys, k)
+	}
+	sort.Slice(durationKeys, func(i, j int) bool { return durationKeys[i].less(durationKeys[j]) })
+	for _, k := range durationKeys {
+		h := m.durations[k]
+		labels := "method=" + labelValue(k.method) + ",route=" + labelValue(k.route)
+		for i, le := range m.config.Buckets {
+			fmt.Fprintf(out, "%s_bucket{%s,le=\"%s\"} %d\n", durations, labels, strconv.FormatFloat(le, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(out, "%s_bucket{%s,le=\"+Inf\"} %d\n", durations, labels, h.count)
+		fmt.Fprintf(out, "%s_sum{%s} %s\n", durations, labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(out, "%s_count{%s} %d\n", durations, labels, h.count)
+	}
+}
+
+func (s routeSeries) less(o routeSeries) bool {
+	if s.route != o.route {
+		return s.route < o.route
+	}
+	return s.method < o.method
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelValue(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
@@ -0,0 +1,139 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// recordingMiddleware appends name to *ran when it runs.
+func recordingMiddleware(name string, ran *[]string) MiddlewareFunc {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			*ran = append(*ran, name)
+			return next.ServeHTTP(ctx, req)
+		})
+	}
+}
+
+func exclusionRouter(ran *[]string) *Router {
+	r := newTestRouter()
+	r.UsePre(recordingMiddleware("all", ran), MiddlewareConfig{})
+	r.UsePre(recordingMiddleware("routes", ran), MiddlewareConfig{ExcludedRoutes: []string{"/public", "/Query/*"}})
+	r.UsePre(recordingMiddleware("methods", ran), MiddlewareConfig{ExcludedMethods: []string{"get"}})
+	r.UsePost(recordingMiddleware("headers", ran), MiddlewareConfig{ExcludedHeaders: map[string]string{"X-Internal": "1"}})
+	return r
+}
+
+func TestMiddlewareExclusions(t *testing.T) {
+	var ran []string
+	r := exclusionRouter(&ran)
+	for _, path := range []string{"/public", "/private"} {
+		r.AddRoute(http.MethodGet, path, textHandler("ok"))
+		r.AddRoute(http.MethodPost, path, textHandler("ok"))
+	}
+
+	cases := []struct {
+		method, path string
+		headers      map[string]string
+		want         []string
+	}{
+		{http.MethodPost, "/private", nil, []string{"all", "routes", "methods", "headers"}},
+		{http.MethodGet, "/private", nil, []string{"all", "routes", "headers"}},
+		{http.MethodPost, "/public", nil, []string{"all", "methods", "headers"}},
+		{http.MethodGet, "/public", nil, []string{"all", "headers"}},
+		{http.MethodPost, "/private", map[string]string{"x-internal": "1"}, []string{"all", "routes", "methods"}},
+		{http.MethodPost, "/private", map[string]string{"x-internal": "0"}, []string{"all", "routes", "methods", "headers"}},
+		{http.MethodGet, "/public", map[string]string{"x-internal": "1"}, []string{"all"}},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s %s %v", c.method, c.path, c.headers), func(t *testing.T) {
+			ran = nil
+			req := benchRequest(c.method, c.path)
+			req.Headers = c.headers
+			if resp := r.HandleRequest(context.Background(), req); resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want 200", resp.StatusCode)
+			}
+			if fmt.Sprint(ran) != fmt.Sprint(c.want) {
+				t.Errorf("middleware ran = %v, want %v", ran, c.want)
+			}
+		})
+	}
+}
+
+// TestAppSyncMiddlewareExclusions checks that resolvers run the middleware
+// a POST to /<type>/<field> would.
+func TestAppSyncMiddlewareExclusions(t *testing.T) {
+	var ran []string
+	a := NewAppSyncResolver(exclusionRouter(&ran))
+	ok := func(ctx context.Context, event AppSyncEvent) (interface{}, error) { return "ok", nil }
+	a.Resolve("Query", "user", ok)
+	a.Resolve("Mutation", "user", ok)
+
+	cases := []struct {
+		typeName string
+		headers  map[string]string
+		want     []string
+	}{
+		{"Mutation", nil, []string{"all", "routes", "methods", "headers"}},
+		{"Query", nil, []string{"all", "methods", "headers"}},
+		{"Mutation", map[string]string{"X-Internal": "1"}, []string{"all", "routes", "methods"}},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s %v", c.typeName, c.headers), func(t *testing.T) {
+			ran = nil
+			payload, _ := json.Marshal(AppSyncEvent{
+				Request: AppSyncRequest{Headers: c.headers},
+				Info:    AppSyncInfo{ParentTypeName: c.typeName, FieldName: "user"},
+			})
+			if _, err := a.LambdaHandler()(context.Background(), payload); err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(ran) != fmt.Sprint(c.want) {
+				t.Errorf("middleware ran = %v, want %v", ran, c.want)
+			}
+		})
+	}
+}
+
+// TestAppSyncChainCompiledOnce checks that a resolver's chain is built once
+// per route table rather than on every invocation.
+func TestAppSyncChainCompiledOnce(t *testing.T) {
+	r := newTestRouter()
+	var builds int
+	r.UsePre(func(next Handler) Handler {
+		builds++
+		return next
+	}, MiddlewareConfig{})
+	a := NewAppSyncResolver(r)
+	a.Resolve("Query", "user", func(ctx context.Context, event AppSyncEvent) (interface{}, error) {
+		if _, ok := AppSyncEventFromContext(ctx); !ok {
+			t.Error("resolver context has no event")
+		}
+		return "ok", nil
+	})
+	payload, _ := json.Marshal(AppSyncEvent{Info: AppSyncInfo{ParentTypeName: "Query", FieldName: "user"}})
+	invoke := func() {
+		t.Helper()
+		if data, err := a.LambdaHandler()(context.Background(), payload); err != nil || data != "ok" {
+			t.Fatalf("invoke = %v, %v", data, err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		invoke()
+	}
+	if builds != 1 {
+		t.Errorf("chain built %d times for one table, want 1", builds)
+	}
+	r.Update(func(u *Router) {})
+	invoke()
+	invoke()
+	if builds != 2 {
+		t.Errorf("chain built %d times across two tables, want 2", builds)
+	}
+}
@@ -0,0 +1,58 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func multiValueRouter() *Router {
+	r := newTestRouter()
+	r.AddRoute(http.MethodGet, "/tags", HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		resp := Response{StatusCode: http.StatusOK, Headers: map[string]string{"X-Tag": "a"}, Body: "ok"}
+		resp.AddHeader("X-Tag", "b")
+		resp.AddHeader("Set-Cookie", "session=1")
+		resp.AddHeader("Set-Cookie", "theme=dark")
+		return resp
+	}))
+	return r
+}
+
+func checkMultiValueHeaders(t *testing.T, headers map[string][]string) {
+	t.Helper()
+	if got := fmt.Sprint(headers["X-Tag"]); got != "[a b]" {
+		t.Errorf("X-Tag = %s, want [a b]", got)
+	}
+	if got := fmt.Sprint(headers["Set-Cookie"]); got != "[session=1 theme=dark]" {
+		t.Errorf("Set-Cookie = %s, want both cookies", got)
+	}
+}
+
+func TestALBMultiValueHeaders(t *testing.T) {
+	resp, err := multiValueRouter().ALBHandler(ALBOptions{})(context.Background(), events.ALBTargetGroupRequest{
+		HTTPMethod:        http.MethodGet,
+		Path:              "/tags",
+		MultiValueHeaders: map[string][]string{"accept": {"*/*"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Headers != nil {
+		t.Errorf("Headers = %v, want only multi-value headers", resp.Headers)
+	}
+	checkMultiValueHeaders(t, resp.MultiValueHeaders)
+}
+
+func TestAPIGatewayProxyMultiValueHeaders(t *testing.T) {
+	resp, err := multiValueRouter().APIGatewayProxyHandler()(context.Background(), events.APIGatewayProxyRequest{
+		HTTPMethod: http.MethodGet,
+		Path:       "/tags",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMultiValueHeaders(t, resp.MultiValueHeaders)
+}
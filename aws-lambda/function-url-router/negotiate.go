@@ -0,0 +1,146 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var defaultOffers = []string{"application/json", "application/xml", "text/html", "text/plain"}
+
+type Template struct {
+	Renderer *HTMLRenderer
+	Name     string
+	Data     interface{}
+}
+
+// Negotiate picks the best of offers for the request's Accept header and
+// stores it in the context for Render. Without explicit offers, JSON, XML,
+// HTML, plain text and every codec registered on the router are offered,
+// codecs in registration order.
+// Requests that accept none of the offers are rejected with 406.
+func Negotiate(offers ...string) MiddlewareFunc {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			available := offers
+			if len(available) == 0 {
+				available = defaultOffers
+				if r := routerFromContext(ctx); r != nil {
+					available = append(available[:len(available):len(available)], r.codecOrder...)
+				}
+			}
+			mediaType := negotiateContentType(req.Headers["accept"], available)
+			if mediaType == "" {
+				return Response{
+					StatusCode: http.StatusNotAcceptable,
+					Headers:    map[string]string{"Content-Type": "application/json"},
+					Body:       map[string]string{"error": "Not Acceptable"},
+				}
+			}
+			ctx = context.WithValue(ctx, negotiatedTypeKey, mediaType)
+			return next.ServeHTTP(ctx, req)
+		})
+	}
+}
+
+func NegotiatedType(ctx context.Context) string {
+	mediaType, _ := ctx.Value(negotiatedTypeKey).(string)
+	return mediaType
+}
+
+// Render encodes v in the media type chosen by Negotiate, defaulting to JSON.
+// HTML is only produced for Template values; other values fall back to JSON.
+func Render(ctx context.Context, status int, v interface{}) Response {
+	data := v
+	tmpl, isTemplate := v.(Template)
+	if isTemplate {
+		data = tmpl.Data
+	}
+
+	mediaType := NegotiatedType(ctx)
+	if c, ok := codecFromContext(ctx, mediaType); ok {
+		return EncodeWith(c, status, data)
+	}
+
+	switch mediaType {
+	case "application/xml", "text/xml":
+		return XML(status, data)
+	case "text/html":
+		if isTemplate {
+			return tmpl.Renderer.HTML(status, tmpl.Name, tmpl.Data)
+		}
+	case "text/plain":
+		return Response{
+			StatusCode: status,
+			Headers:    map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+			Body:       fmt.Sprint(data),
+		}
+	}
+	return Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       data,
+	}
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if mediaType == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.TrimSpace(k) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
+
+func negotiateContentType(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	for _, r := range parseAccept(accept) {
+		if r.q <= 0 {
+			continue
+		}
+		for _, offer := range offers {
+			if mediaTypeMatches(r.mediaType, offer) {
+				return offer
+			}
+		}
+	}
+	return ""
+}
+
+func mediaTypeMatches(pattern, mediaType string) bool {
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type namedCodec struct {
+	jsonCodec
+	contentType string
+}
+
+func (c namedCodec) ContentType() string {
+	return c.contentType
+}
+
+// TestNegotiateCodecOrder checks that a wildcard Accept picks the codec
+// registered first, every time.
+func TestNegotiateCodecOrder(t *testing.T) {
+	r := newTestRouter()
+	for _, contentType := range []string{"vnd/e", "vnd/b", "vnd/d", "vnd/a", "vnd/c"} {
+		r.RegisterCodec(namedCodec{contentType: contentType})
+	}
+	r.UsePre(Negotiate(), MiddlewareConfig{})
+	r.AddRoute(http.MethodPost, "/negotiate", HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		return Response{StatusCode: http.StatusOK, Body: NegotiatedType(ctx)}
+	}))
+
+	for i := 0; i < 20; i++ {
+		req := benchRequest(http.MethodPost, "/negotiate")
+		req.Headers = map[string]string{"accept": "vnd/*"}
+		if resp := r.HandleRequest(context.Background(), req); resp.Body != "vnd/e" {
+			t.Fatalf("negotiated %v, want vnd/e", resp.Body)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultDocsPath = "/_docs"
+
+type DocsOptions struct {
+	// Path defaults to /_docs. The document is served at Path/openapi.json.
+	Path string
+	// Info describes the generated document.
+	Info Info
+	// Document is served instead of the generated one, for example the
+	// spec given to LoadMock. JSON or YAML.
+	Document []byte
+	// UI is "swagger" (default) or "redoc". Both load their assets from a
+	// public CDN.
+	UI string
+}
+
+var docsPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+{{- if eq .UI "redoc"}}
+<script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</head>
+<body>
+<redoc spec-url="{{.SpecURL}}"></redoc>
+</body>
+{{- else}}
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>SwaggerUIBundle({url: "{{.SpecURL}}", dom_id: "#swagger-ui"});</script>
+</body>
+{{- end}}
+</html>
+`))
+
+// Docs registers a Swagger UI or Redoc page and the OpenAPI document for r.
+// Both answer 404 unless the router is in dev mode, so mounting them
+// unconditionally never exposes the docs in production. The generated
+// document reflects the routes registered at request time.
+func Docs(r *router.Router, opts DocsOptions) error {
+	base := strings.TrimRight(opts.Path, "/")
+	if base == "" {
+		base = defaultDocsPath
+	}
+	specPath := base + "/openapi.json"
+
+	var static map[string]interface{}
+	if opts.Document != nil {
+		var raw interface{}
+		if err := yaml.Unmarshal(opts.Document, &raw); err != nil {
+			return fmt.Errorf("openapi: parse document: %w", err)
+		}
+		doc, ok := normalize(raw).(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("openapi: document is not an object")
+		}
+		static = doc
+	}
+
+	if err := r.AddRoute(http.MethodGet, specPath, devOnly(func(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+		doc := static
+		if doc == nil {
+			doc = Generate(r, opts.Info)
+			if paths, ok := doc["paths"].(map[string]interface{}); ok {
+				delete(paths, base)
+				delete(paths, specPath)
+			}
+		}
+		return router.Response{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       doc,
+		}
+	})); err != nil {
+		return err
+	}
+
+	title := opts.Info.Title
+	if title == "" {
+		title = "API docs"
+	}
+	return r.AddRoute(http.MethodGet, base, devOnly(func(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+		var page strings.Builder
+		data := struct{ Title, UI, SpecURL string }{title, opts.UI, specPath}
+		if err := docsPage.Execute(&page, data); err != nil {
+			return router.Error(ctx, http.StatusInternalServerError, err)
+		}
+		return router.Response{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "text/html; charset=utf-8"},
+			Body:       page.String(),
+		}
+	}))
+}
+
+func devOnly(fn router.HandlerFunc) router.Handler {
+	return router.HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+		if !router.IsDevMode(ctx) {
+			return router.Response{
+				StatusCode: http.StatusNotFound,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       map[string]string{"error": "Not Found"},
+			}
+		}
+		return fn(ctx, req)
+	})
+}
@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// Generate builds an OpenAPI 3.0 document with an operation for every route
+// registered on r. Operations only carry a default response; hand-written
+// documents can describe schemas in more detail.
+func Generate(r *router.Router, info Info) map[string]interface{} {
+	if info.Title == "" {
+		info.Title = "API"
+	}
+	if info.Version == "" {
+		info.Version = "0.0.0"
+	}
+	docInfo := map[string]interface{}{"title": info.Title, "version": info.Version}
+	if info.Description != "" {
+		docInfo["description"] = info.Description
+	}
+
+	paths := make(map[string]interface{})
+	for _, route := range r.Routes() {
+		item, ok := paths[route.Path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[route.Path] = item
+		}
+		item[strings.ToLower(route.Method)] = map[string]interface{}{
+			"operationId": operationID(route),
+			"responses": map[string]interface{}{
+				"default": map[string]interface{}{"description": http.StatusText(http.StatusOK)},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    docInfo,
+		"paths":   paths,
+	}
+}
+
+// operationID builds an ID such as getUsersOrders from GET /users/orders.
+func operationID(route router.Route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(route.Method))
+	for _, part := range strings.FieldsFunc(route.Path, func(c rune) bool {
+		return !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9')
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,309 @@
+package openapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+	"gopkg.in/yaml.v3"
+)
+
+const maxSchemaDepth = 8
+
+// Mock serves example responses from an OpenAPI 3 document. Pass it to
+// router.WithFallback so routes that are documented but not implemented yet
+// still answer locally.
+type Mock struct {
+	root       map[string]interface{}
+	operations []mockOperation
+}
+
+type mockOperation struct {
+	method    string
+	segments  []string
+	responses map[string]interface{}
+}
+
+func LoadMock(path string) (*Mock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewMock(data)
+}
+
+// NewMock parses a JSON or YAML OpenAPI document.
+func NewMock(doc []byte) (*Mock, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(doc, &raw); err != nil {
+		return nil, fmt.Errorf("openapi: parse document: %w", err)
+	}
+	root, ok := normalize(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("openapi: document is not an object")
+	}
+
+	m := &Mock{root: root}
+	paths, _ := root["paths"].(map[string]interface{})
+	for path, item := range paths {
+		methods, _ := item.(map[string]interface{})
+		for method, op := range methods {
+			op, ok := op.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			responses, _ := op["responses"].(map[string]interface{})
+			m.operations = append(m.operations, mockOperation{
+				method:    strings.ToUpper(method),
+				segments:  strings.Split(strings.Trim(path, "/"), "/"),
+				responses: responses,
+			})
+		}
+	}
+	return m, nil
+}
+
+func (m *Mock) ServeHTTP(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+	op := m.match(req.RequestContext.HTTP.Method, req.RequestContext.HTTP.Path)
+	if op == nil {
+		return router.Response{
+			StatusCode: http.StatusNotFound,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       map[string]string{"error": "Not Found"},
+		}
+	}
+
+	status, raw := pickResponse(op.responses)
+	resp := router.Response{
+		StatusCode: status,
+		Headers:    map[string]string{"X-Mock-Response": "openapi"},
+	}
+	response, _ := m.resolve(raw).(map[string]interface{})
+	content, _ := response["content"].(map[string]interface{})
+	contentType, media := pickContent(content)
+	if media == nil {
+		return resp
+	}
+	resp.Headers["Content-Type"] = contentType
+	resp.Body = m.example(media)
+	if s, ok := resp.Body.(string); ok && strings.Contains(contentType, "json") {
+		resp.Body = strconv.Quote(s)
+	}
+	return resp
+}
+
+// match prefers the documented path with the most literal segments, so
+// /users/me wins over /users/{id}.
+func (m *Mock) match(method, path string) *mockOperation {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var best *mockOperation
+	bestScore := -1
+	for i := range m.operations {
+		op := &m.operations[i]
+		if op.method != method || len(op.segments) != len(segments) {
+			continue
+		}
+		score := 0
+		matched := true
+		for j, s := range op.segments {
+			switch {
+			case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+			case s == segments[j]:
+				score++
+			default:
+				matched = false
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched && score > bestScore {
+			best, bestScore = op, score
+		}
+	}
+	return best
+}
+
+func pickResponse(responses map[string]interface{}) (int, interface{}) {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if status, err := strconv.Atoi(code); err == nil && status >= 200 && status < 300 {
+			return status, responses[code]
+		}
+	}
+	if r, ok := responses["default"]; ok {
+		return http.StatusOK, r
+	}
+	for _, code := range codes {
+		if status, err := strconv.Atoi(code); err == nil {
+			return status, responses[code]
+		}
+	}
+	return http.StatusOK, nil
+}
+
+func pickContent(content map[string]interface{}) (string, map[string]interface{}) {
+	if media, ok := content["application/json"].(map[string]interface{}); ok {
+		return "application/json", media
+	}
+	types := make([]string, 0, len(content))
+	for ct := range content {
+		types = append(types, ct)
+	}
+	sort.Strings(types)
+	for _, ct := range types {
+		if media, ok := content[ct].(map[string]interface{}); ok {
+			return ct, media
+		}
+	}
+	return "", nil
+}
+
+func (m *Mock) example(media map[string]interface{}) interface{} {
+	if ex, ok := media["example"]; ok {
+		return ex
+	}
+	if examples, ok := media["examples"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(examples))
+		for name := range examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if ex, ok := m.resolve(examples[name]).(map[string]interface{}); ok {
+				if v, ok := ex["value"]; ok {
+					return v
+				}
+			}
+		}
+	}
+	return m.generate(media["schema"], 0)
+}
+
+// generate builds a value that satisfies schema from its examples, defaults
+// and types.
+func (m *Mock) generate(schema interface{}, depth int) interface{} {
+	s, ok := m.resolve(schema).(map[string]interface{})
+	if !ok || depth > maxSchemaDepth {
+		return nil
+	}
+	if v, ok := s["example"]; ok {
+		return v
+	}
+	if v, ok := s["default"]; ok {
+		return v
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if all, ok := s["allOf"].([]interface{}); ok {
+		merged := make(map[string]interface{})
+		for _, sub := range all {
+			if obj, ok := m.generate(sub, depth+1).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options, ok := s[key].([]interface{}); ok && len(options) > 0 {
+			return m.generate(options[0], depth+1)
+		}
+	}
+
+	typ, _ := s["type"].(string)
+	if props, ok := s["properties"].(map[string]interface{}); ok || typ == "object" {
+		obj := make(map[string]interface{}, len(props))
+		for name, prop := range props {
+			obj[name] = m.generate(prop, depth+1)
+		}
+		return obj
+	}
+	switch typ {
+	case "array":
+		if item := m.generate(s["items"], depth+1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return true
+	case "string":
+		switch s["format"] {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "date":
+			return "2024-01-01"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		return "string"
+	}
+	return nil
+}
+
+// resolve follows local $ref pointers such as #/components/schemas/User.
+func (m *Mock) resolve(v interface{}) interface{} {
+	for i := 0; i < maxSchemaDepth; i++ {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return v
+		}
+		var node interface{} = m.root
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+			parent, ok := node.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			node = parent[part]
+		}
+		v = node
+	}
+	return v
+}
+
+// normalize turns the map[interface{}]interface{} values yaml produces for
+// non-string keys, such as unquoted status codes, into string-keyed maps.
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			t[k] = normalize(val)
+		}
+		return t
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			out[fmt.Sprint(k)] = normalize(val)
+		}
+		return out
+	case []interface{}:
+		for i, val := range t {
+			t[i] = normalize(val)
+		}
+		return t
+	}
+	return v
+}
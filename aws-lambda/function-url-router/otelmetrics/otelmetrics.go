@@ -0,0 +1,144 @@
+package otelmetrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const scope = "github.com/rthing31/go/aws-lambda/function-url-router/otelmetrics"
+
+type Config struct {
+	// MeterProvider defaults to the global provider from otel.
+	MeterProvider metric.MeterProvider
+}
+
+type flusher interface {
+	ForceFlush(context.Context) error
+}
+
+// Middleware records, for every request:
+//
+//   - http.server.request.duration, a histogram in seconds
+//   - http.server.active_requests, the number of requests in progress
+//   - http.server.errors, a count of 5xx responses
+//
+// with http.request.method and http.route attributes, plus
+// http.response.status_code and faas.coldstart on the duration and error
+// count. In Lambda, providers with a ForceFlush method, such as the SDK's,
+// are flushed after every request because the environment may be frozen
+// before a periodic export runs.
+func Middleware(config Config) (router.MiddlewareFunc, error) {
+	provider := config.MeterProvider
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	meter := provider.Meter(scope)
+
+	duration, err := meter.Float64Histogram("http.server.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP server requests."),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10))
+	if err != nil {
+		return nil, err
+	}
+	active, err := meter.Int64UpDownCounter("http.server.active_requests",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of active HTTP server requests."))
+	if err != nil {
+		return nil, err
+	}
+	errors, err := meter.Int64Counter("http.server.errors",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of HTTP server requests answered with a 5xx status."))
+	if err != nil {
+		return nil, err
+	}
+	flush, _ := provider.(flusher)
+
+	return func(next router.Handler) router.Handler {
+		return router.HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+			route := attribute.NewSet(
+				attribute.String("http.request.method", req.RequestContext.HTTP.Method),
+				attribute.String("http.route", router.MatchedRoute(ctx)),
+			)
+			active.Add(ctx, 1, metric.WithAttributeSet(route))
+			start := time.Now()
+
+			resp := next.ServeHTTP(ctx, req)
+
+			elapsed := time.Since(start).Seconds()
+			active.Add(ctx, -1, metric.WithAttributeSet(route))
+			status := metric.WithAttributes(append(route.ToSlice(),
+				attribute.Int("http.response.status_code", resp.StatusCode),
+				attribute.Bool("faas.coldstart", router.IsColdStart(ctx)))...)
+			duration.Record(ctx, elapsed, status)
+			if resp.StatusCode >= 500 {
+				errors.Add(ctx, 1, status)
+			}
+			if flush != nil && router.IsLambda() {
+				if err := flush.ForceFlush(ctx); err != nil {
+					router.LogError(ctx, "Error flushing OpenTelemetry metrics", err)
+				}
+			}
+			return resp
+		})
+	}, nil
+}
+
+// NewOTLPProvider returns a MeterProvider that exports over OTLP/HTTP,
+// configured by opts or the standard OTEL_EXPORTER_OTLP_* variables, e.g.
+// to the ADOT collector extension on localhost:4318. Call Shutdown on it
+// before the process exits.
+func NewOTLPProvider(ctx context.Context, opts ...otlpmetrichttp.Option) (*sdkmetric.MeterProvider, error) {
+	exporter, err := otlpmetrichttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter))), nil
+}
+
+// LatencyReporter returns a router.LatencyConfig.Report function that
+// records each route's percentiles, in seconds, on the
+// http.server.request.duration.quantile gauge with http.request.method,
+// http.route and quantile ("0.5", "0.95", "0.99" or "1" for the maximum)
+// attributes.
+func LatencyReporter(config Config) (func(context.Context, []router.RouteLatency), error) {
+	provider := config.MeterProvider
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	gauge, err := provider.Meter(scope).Float64Gauge("http.server.request.duration.quantile",
+		metric.WithUnit("s"),
+		metric.WithDescription("Percentiles of HTTP server request duration over the last reporting interval."))
+	if err != nil {
+		return nil, err
+	}
+	flush, _ := provider.(flusher)
+
+	return func(ctx context.Context, routes []router.RouteLatency) {
+		for _, route := range routes {
+			for _, q := range []struct {
+				quantile string
+				value    time.Duration
+			}{{"0.5", route.P50}, {"0.95", route.P95}, {"0.99", route.P99}, {"1", route.Max}} {
+				gauge.Record(ctx, q.value.Seconds(), metric.WithAttributes(
+					attribute.String("http.request.method", route.Method),
+					attribute.String("http.route", route.Route),
+					attribute.String("quantile", q.quantile)))
+			}
+		}
+		if flush != nil && router.IsLambda() {
+			if err := flush.ForceFlush(ctx); err != nil {
+				router.LogError(ctx, "Error flushing OpenTelemetry metrics", err)
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Page is a single page of results. Cursor identifies the next page and is
+// empty on the last one; a negative Total means the total is unknown.
+type Page struct {
+	Items  interface{}
+	Cursor string
+	Total  int
+}
+
+type PaginationOptions struct {
+	CursorParam string
+	Envelope    bool
+}
+
+type pageEnvelope struct {
+	Data       interface{}      `json:"data"`
+	Pagination pageEnvelopeMeta `json:"pagination"`
+}
+
+type pageEnvelopeMeta struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+	Total      *int   `json:"total,omitempty"`
+}
+
+func Paginate(req events.LambdaFunctionURLRequest, status int, page Page, opts PaginationOptions) Response {
+	if opts.CursorParam == "" {
+		opts.CursorParam = "cursor"
+	}
+
+	resp := Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       page.Items,
+	}
+
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(req, opts.CursorParam, ""))}
+	if page.Cursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(req, opts.CursorParam, page.Cursor)))
+	}
+	resp.Headers["Link"] = strings.Join(links, ", ")
+
+	var total *int
+	if page.Total >= 0 {
+		total = &page.Total
+		resp.Headers["X-Total-Count"] = strconv.Itoa(page.Total)
+	}
+
+	if opts.Envelope {
+		resp.Body = pageEnvelope{
+			Data:       page.Items,
+			Pagination: pageEnvelopeMeta{NextCursor: page.Cursor, Total: total},
+		}
+	}
+	return resp
+}
+
+func pageURL(req events.LambdaFunctionURLRequest, cursorParam, cursor string) string {
+	query, _ := url.ParseQuery(req.RawQueryString)
+	if query == nil {
+		query = url.Values{}
+	}
+	if cursor == "" {
+		query.Del(cursorParam)
+	} else {
+		query.Set(cursorParam, cursor)
+	}
+
+	u := url.URL{
+		Scheme:   "https",
+		Host:     req.RequestContext.DomainName,
+		Path:     req.RequestContext.HTTP.Path,
+		RawQuery: query.Encode(),
+	}
+	if host := req.Headers["host"]; host != "" {
+		u.Host = host
+	}
+	if proto := req.Headers["x-forwarded-proto"]; proto == "http" {
+		u.Scheme = proto
+	}
+	if u.Host == "" {
+		u.Scheme = ""
+	}
+	return u.String()
+}
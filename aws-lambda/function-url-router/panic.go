@@ -0,0 +1,100 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// PanicReport describes a panic recovered while handling a request.
+type PanicReport struct {
+	Value interface{}
+	Stack []byte
+	// Goroutines holds the stacks of every goroutine when
+	// PanicConfig.DumpGoroutines is set.
+	Goroutines []byte
+	Method     string
+	Path       string
+	Route      string
+	RequestID  string
+}
+
+type PanicConfig struct {
+	// DumpGoroutines adds every goroutine's stack to panic reports, which
+	// helps with deadlocks and leaked goroutines but can be large.
+	DumpGoroutines bool
+}
+
+func (r *Router) SetPanicConfig(config PanicConfig) {
+	r.panicConfig = config
+}
+
+// Recovered returns the panic being handled, for use in a handler set with
+// SetPanicHandler.
+func Recovered(ctx context.Context) (PanicReport, bool) {
+	report, ok := ctx.Value(panicKey).(*PanicReport)
+	if !ok {
+		return PanicReport{}, false
+	}
+	return *report, true
+}
+
+// recoverPanic builds the report for value; it must be called from the
+// deferred function that recovered it so the stack includes the panic site.
+func (r *Router) recoverPanic(ctx context.Context, req events.LambdaFunctionURLRequest, value interface{}) *PanicReport {
+	report := &PanicReport{
+		Value:     value,
+		Stack:     debug.Stack(),
+		Method:    req.RequestContext.HTTP.Method,
+		Path:      req.RequestContext.HTTP.Path,
+		Route:     MatchedRoute(ctx),
+		RequestID: requestID(ctx, req),
+	}
+	if r.panicConfig.DumpGoroutines {
+		report.Goroutines = allStacks()
+	}
+	r.logPanic(ctx, report)
+	return report
+}
+
+func allStacks() []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func (r *Router) logPanic(ctx context.Context, report *PanicReport) {
+	if r.slogger != nil {
+		attrs := []slog.Attr{
+			slog.String("panic", fmt.Sprint(report.Value)),
+			slog.String("method", report.Method),
+			slog.String("path", report.Path),
+			slog.String("route", report.Route),
+			slog.String("request_id", report.RequestID),
+			slog.String("stack", string(report.Stack)),
+		}
+		if report.Goroutines != nil {
+			attrs = append(attrs, slog.String("goroutines", string(report.Goroutines)))
+		}
+		if trace, ok := Trace(ctx); ok {
+			attrs = append(attrs, slog.String("trace_id", trace.TraceID))
+		}
+		r.slogger.LogAttrs(ctx, slog.LevelError, "Panic recovered", attrs...)
+		return
+	}
+
+	r.logger.Printf("Panic recovered: %v method=%s path=%s route=%s request_id=%s\n%s",
+		report.Value, report.Method, report.Path, report.Route, report.RequestID, report.Stack)
+	if report.Goroutines != nil {
+		r.logger.Printf("Goroutines at panic:\n%s", report.Goroutines)
+	}
+}
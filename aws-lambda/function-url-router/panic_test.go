@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestPanicResponse(t *testing.T) {
+	boom := HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		panic("boom")
+	})
+	passThrough := func(next Handler) Handler { return next }
+
+	for _, c := range []struct {
+		name       string
+		method     string
+		middleware bool
+		dev        bool
+	}{
+		{"direct GET", http.MethodGet, false, false},
+		{"direct GET dev", http.MethodGet, false, true},
+		{"POST", http.MethodPost, false, false},
+		{"POST dev", http.MethodPost, false, true},
+		{"GET with middleware dev", http.MethodGet, true, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			r := NewRouter(log.New(io.Discard, "", 0))
+			r.SetDevMode(c.dev)
+			if c.middleware {
+				r.UsePre(passThrough, MiddlewareConfig{})
+			}
+			r.AddRoute(c.method, "/boom", boom)
+
+			resp := r.HandleRequest(context.Background(), benchRequest(c.method, "/boom"))
+			if resp.StatusCode != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want 500", resp.StatusCode)
+			}
+			if !c.dev {
+				body, ok := resp.Body.(map[string]string)
+				if !ok || body["error"] != "Internal Server Error" || len(body) != 1 {
+					t.Errorf("body = %#v, want only the error", resp.Body)
+				}
+				return
+			}
+			body, ok := resp.Body.(map[string]interface{})
+			if !ok {
+				t.Fatalf("body = %#v, want the dev-mode report", resp.Body)
+			}
+			if body["error"] != "Internal Server Error" || body["panic"] != "boom" {
+				t.Errorf("body = %#v", body)
+			}
+			if stack, _ := body["stack"].([]string); len(stack) == 0 {
+				t.Error("dev-mode body has no stack")
+			}
+		})
+	}
+}
+
+func TestCustomPanicHandler(t *testing.T) {
+	r := NewRouter(log.New(io.Discard, "", 0))
+	r.SetPanicHandler(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		report, ok := Recovered(ctx)
+		if !ok {
+			t.Error("Recovered found no report")
+		}
+		return Response{StatusCode: http.StatusServiceUnavailable, Body: report.Value}
+	})
+	r.AddRoute(http.MethodPut, "/boom", HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		panic("custom")
+	}))
+
+	resp := r.HandleRequest(context.Background(), benchRequest(http.MethodPut, "/boom"))
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Body != "custom" {
+		t.Errorf("response = %d %#v, want the panic handler's", resp.StatusCode, resp.Body)
+	}
+}
+
+func TestPanicResponseCORS(t *testing.T) {
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		t.Run(method, func(t *testing.T) {
+			r := NewRouter(log.New(io.Discard, "", 0))
+			r.SetCORS(CORSConfig{AllowOrigins: []string{"https://app.example.com"}})
+			r.AddRoute(method, "/boom", HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+				panic("boom")
+			}))
+
+			req := benchRequest(method, "/boom")
+			req.Headers = map[string]string{"origin": "https://app.example.com"}
+			resp := r.HandleRequest(context.Background(), req)
+			if resp.StatusCode != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want 500", resp.StatusCode)
+			}
+			if got := resp.HeaderValues("Access-Control-Allow-Origin"); len(got) != 1 || got[0] != "https://app.example.com" {
+				t.Errorf("Access-Control-Allow-Origin = %v, want the request origin", got)
+			}
+		})
+	}
+}
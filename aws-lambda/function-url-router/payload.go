@@ -0,0 +1,167 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// DefaultMaxPayloadBytes is the buffered response limit of Lambda Function
+// URLs.
+const DefaultMaxPayloadBytes = 6 * 1024 * 1024
+
+type OversizeAction int
+
+const (
+	OversizeFail OversizeAction = iota
+	OversizeOffload
+	// OversizeStream sends the body as a stream, which only avoids the
+	// buffered limit when the function is served through
+	// StreamingLambdaHandler with the RESPONSE_STREAM invoke mode.
+	OversizeStream
+)
+
+type Uploader interface {
+	Upload(ctx context.Context, key, contentType string, body []byte) error
+}
+
+type PayloadLimitConfig struct {
+	MaxBytes  int
+	Action    OversizeAction
+	Uploader  Uploader
+	Presigner Presigner
+	Expiry    time.Duration
+	KeyFunc   func(context.Context, events.LambdaFunctionURLRequest) string
+}
+
+// PayloadLimit guards against responses that exceed the Function URL payload
+// limit. Wrap individual handlers with it to configure the limit per route.
+func PayloadLimit(config PayloadLimitConfig) MiddlewareFunc {
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = DefaultMaxPayloadBytes
+	}
+	if config.Expiry <= 0 {
+		config.Expiry = 15 * time.Minute
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = offloadKey
+	}
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			resp := next.ServeHTTP(ctx, req)
+			if _, ok := resp.Body.(io.Reader); ok {
+				return resp
+			}
+
+			// The body is measured with the codec the router writes with,
+			// and a structured body that fits is passed on encoded so the
+			// router doesn't marshal it again.
+			body, err := encodeBody(outputCodecFromContext(ctx), resp.Body)
+			if err != nil {
+				return resp
+			}
+			if payloadSize(resp, body) <= config.MaxBytes {
+				return encodedResponse(resp, body)
+			}
+
+			if config.Action == OversizeStream {
+				if streamed, err := streamOversize(resp, body); err == nil {
+					return streamed
+				}
+			}
+			if config.Action == OversizeOffload && config.Uploader != nil && config.Presigner != nil {
+				if offloaded, err := offload(ctx, req, resp, body, config); err == nil {
+					return offloaded
+				}
+			}
+			return Response{
+				StatusCode: http.StatusBadGateway,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       map[string]string{"error": "Response payload too large"},
+			}
+		})
+	}
+}
+
+// encodedResponse replaces a structured body with its encoding, keeping the
+// JSON Content-Type the router would have added.
+func encodedResponse(resp Response, body []byte) Response {
+	switch resp.Body.(type) {
+	case nil, string, []byte:
+		return resp
+	}
+	if len(resp.HeaderValues("Content-Type")) == 0 {
+		resp.AddHeader("Content-Type", "application/json")
+	}
+	resp.Body = body
+	return resp
+}
+
+func payloadSize(resp Response, body []byte) int {
+	size := len(body)
+	for k, v := range resp.Headers {
+		size += len(k) + len(v)
+	}
+	for k, values := range resp.MultiValueHeaders {
+		for _, v := range values {
+			size += len(k) + len(v)
+		}
+	}
+	return size
+}
+
+// streamOversize turns resp into a streamed response carrying the encoded
+// body, so toStreamingResponse sends it as it is.
+func streamOversize(resp Response, body []byte) (Response, error) {
+	if resp.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			return Response{}, err
+		}
+		body = decoded
+	}
+	if _, ok := resp.Body.(string); !ok && len(resp.HeaderValues("Content-Type")) == 0 {
+		resp.AddHeader("Content-Type", "application/json")
+	}
+	resp.Body = bytes.NewReader(body)
+	resp.IsBase64Encoded = false
+	return resp, nil
+}
+
+func offload(ctx context.Context, req events.LambdaFunctionURLRequest, resp Response, body []byte, config PayloadLimitConfig) (Response, error) {
+	if resp.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			return Response{}, err
+		}
+		body = decoded
+	}
+
+	contentType := "application/octet-stream"
+	if values := resp.HeaderValues("Content-Type"); len(values) > 0 {
+		contentType = values[0]
+	}
+
+	key := config.KeyFunc(ctx, req)
+	if err := config.Uploader.Upload(ctx, key, contentType, body); err != nil {
+		return Response{}, err
+	}
+	url, err := config.Presigner.Presign(ctx, http.MethodGet, key, config.Expiry)
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{
+		StatusCode: http.StatusFound,
+		Headers:    map[string]string{"Location": url, "Cache-Control": "no-store"},
+	}, nil
+}
+
+func offloadKey(ctx context.Context, req events.LambdaFunctionURLRequest) string {
+	return fmt.Sprintf("responses/%s/%d", req.RequestContext.RequestID, time.Now().UnixNano())
+}
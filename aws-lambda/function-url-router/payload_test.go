@@ -0,0 +1,64 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestPayloadLimitStream(t *testing.T) {
+	r := newTestRouter()
+	r.AddRoute(http.MethodGet, "/big", PayloadLimit(PayloadLimitConfig{MaxBytes: 32, Action: OversizeStream})(
+		HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			return Response{StatusCode: http.StatusOK, Body: map[string]string{"data": "0123456789012345678901234567890123456789"}}
+		}),
+	))
+
+	resp, err := r.StreamingLambdaHandler()(context.Background(), benchRequest(http.MethodGet, "/big"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Headers["Content-Type"] != "application/json" {
+		t.Errorf("response = %d %v, want 200 JSON", resp.StatusCode, resp.Headers)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if want := `{"data":"0123456789012345678901234567890123456789"}`; string(body) != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+}
+
+type countingCodec struct {
+	jsonCodec
+	marshals *int
+}
+
+func (c countingCodec) Marshal(v interface{}) ([]byte, error) {
+	*c.marshals++
+	return json.Marshal(v)
+}
+
+// TestPayloadLimitEncodesOnce checks that a body under the limit is
+// measured with the router's codec and not marshalled again when sent.
+func TestPayloadLimitEncodesOnce(t *testing.T) {
+	var marshals int
+	r := newTestRouter()
+	r.SetJSONCodec(countingCodec{marshals: &marshals})
+	r.SetDevMode(true)
+	r.AddRoute(http.MethodGet, "/small", PayloadLimit(PayloadLimitConfig{})(
+		HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			return Response{StatusCode: http.StatusOK, Body: map[string]string{"a": "b"}}
+		}),
+	))
+
+	resp, _ := r.LambdaHandler()(context.Background(), benchRequest(http.MethodGet, "/small"))
+	if marshals != 1 {
+		t.Errorf("body marshalled %d times, want 1", marshals)
+	}
+	if resp.Headers["Content-Type"] != "application/json" || resp.Body != "{\n  \"a\": \"b\"\n}" {
+		t.Errorf("response = %v %q, want indented JSON", resp.Headers, resp.Body)
+	}
+}
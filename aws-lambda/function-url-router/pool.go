@@ -0,0 +1,71 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Encoding buffers and response header maps are reused across the requests
+// a warm environment handles. Whatever is put back must not be referenced
+// afterwards: bodies are copied out of buffers before they're released, and
+// header maps are only released once the response has been written or
+// serialized, never when they're handed to the Lambda runtime.
+
+// maxPooledBuffer keeps one large response from pinning its buffer.
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+var headerPool = sync.Pool{New: func() interface{} { return make(map[string]string, 8) }}
+
+func getHeaders() map[string]string {
+	return headerPool.Get().(map[string]string)
+}
+
+func putHeaders(headers map[string]string) {
+	clear(headers)
+	headerPool.Put(headers)
+}
+
+// writeBody is encodeBody into buf, which saves copying the encoded body
+// when it's about to be copied again into a string or a ResponseWriter.
+func writeBody(buf *bytes.Buffer, c Codec, body interface{}) error {
+	switch b := body.(type) {
+	case nil:
+		return nil
+	case string:
+		buf.WriteString(b)
+	case []byte:
+		buf.Write(b)
+	case io.Reader:
+		defer closeBody(b)
+		_, err := buf.ReadFrom(b)
+		return err
+	default:
+		if _, ok := c.(jsonCodec); !ok {
+			out, err := c.Marshal(b)
+			buf.Write(out)
+			return err
+		}
+		if err := json.NewEncoder(buf).Encode(b); err != nil {
+			return err
+		}
+		// Match json.Marshal, which doesn't end with a newline.
+		buf.Truncate(buf.Len() - 1)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var errPresignPrefixRequired = errors.New("router: PresignHandler needs KeyFunc or Prefix")
+
+type Presigner interface {
+	Presign(ctx context.Context, method, key string, expiry time.Duration) (string, error)
+}
+
+type PresignOptions struct {
+	Method string
+	Expiry time.Duration
+	// KeyFunc chooses the object key. Without it the "key" query parameter
+	// is used, which requires Prefix so clients can't sign any object in
+	// the bucket.
+	KeyFunc func(context.Context, events.LambdaFunctionURLRequest) (string, error)
+	// Prefix, when set, is required of every key.
+	Prefix   string
+	Redirect bool
+}
+
+type presignResponse struct {
+	URL       string    `json:"url"`
+	Method    string    `json:"method"`
+	Key       string    `json:"key"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// PresignHandler returns a handler that presigns the object key chosen by
+// KeyFunc (the "key" query parameter by default) and either redirects to the
+// URL or returns it as JSON. Empty keys, keys containing ".." and keys
+// outside Prefix are rejected with 400; with neither KeyFunc nor Prefix set,
+// every key is.
+func PresignHandler(p Presigner, opts PresignOptions) Handler {
+	if opts.Method == "" {
+		opts.Method = http.MethodGet
+	}
+	if opts.Expiry <= 0 {
+		opts.Expiry = 15 * time.Minute
+	}
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = keyFromQuery
+		if opts.Prefix == "" {
+			opts.KeyFunc = func(context.Context, events.LambdaFunctionURLRequest) (string, error) {
+				return "", errPresignPrefixRequired
+			}
+		}
+	}
+	return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		key, err := opts.KeyFunc(ctx, req)
+		if err != nil || !validObjectKey(key, opts.Prefix) {
+			return Response{
+				StatusCode: http.StatusBadRequest,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       map[string]string{"error": "Invalid object key"},
+			}
+		}
+
+		url, err := p.Presign(ctx, opts.Method, key, opts.Expiry)
+		if err != nil {
+			return Response{
+				StatusCode: http.StatusInternalServerError,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       map[string]string{"error": "Internal Server Error"},
+			}
+		}
+
+		if opts.Redirect {
+			return Response{
+				StatusCode: http.StatusFound,
+				Headers:    map[string]string{"Location": url, "Cache-Control": "no-store"},
+			}
+		}
+		return Response{
+			StatusCode: http.StatusOK,
+			Headers:    map[string]string{"Content-Type": "application/json", "Cache-Control": "no-store"},
+			Body: presignResponse{
+				URL:       url,
+				Method:    opts.Method,
+				Key:       key,
+				ExpiresAt: time.Now().Add(opts.Expiry).UTC(),
+			},
+		}
+	})
+}
+
+func keyFromQuery(ctx context.Context, req events.LambdaFunctionURLRequest) (string, error) {
+	return req.QueryStringParameters["key"], nil
+}
+
+func validObjectKey(key, prefix string) bool {
+	return key != "" && !strings.Contains(key, "..") && strings.HasPrefix(key, prefix)
+}
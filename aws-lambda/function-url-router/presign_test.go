@@ -0,0 +1,38 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type fakePresigner struct{}
+
+func (fakePresigner) Presign(ctx context.Context, method, key string, expiry time.Duration) (string, error) {
+	return "https://bucket.example.com/" + key, nil
+}
+
+func TestPresignHandlerKeys(t *testing.T) {
+	cases := []struct {
+		name string
+		opts PresignOptions
+		key  string
+		want int
+	}{
+		{"prefixed key", PresignOptions{Prefix: "uploads/"}, "uploads/a.png", http.StatusOK},
+		{"outside prefix", PresignOptions{Prefix: "uploads/"}, "secrets/a.png", http.StatusBadRequest},
+		{"dot dot", PresignOptions{Prefix: "uploads/"}, "uploads/../secrets/a.png", http.StatusBadRequest},
+		{"empty", PresignOptions{Prefix: "uploads/"}, "", http.StatusBadRequest},
+		{"no prefix or KeyFunc", PresignOptions{}, "uploads/a.png", http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := benchRequest(http.MethodGet, "/presign")
+			req.QueryStringParameters = map[string]string{"key": c.key}
+			if resp := PresignHandler(fakePresigner{}, c.opts).ServeHTTP(context.Background(), req); resp.StatusCode != c.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, c.want)
+			}
+		})
+	}
+}
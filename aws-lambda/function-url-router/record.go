@@ -0,0 +1,125 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// EventRecorder stores incoming events so they can be replayed later.
+type EventRecorder interface {
+	RecordEvent(ctx context.Context, name string, event []byte) error
+}
+
+type dirRecorder string
+
+// DirRecorder writes each event as a JSON file in dir.
+func DirRecorder(dir string) EventRecorder {
+	return dirRecorder(dir)
+}
+
+func (d dirRecorder) RecordEvent(ctx context.Context, name string, event []byte) error {
+	if err := os.MkdirAll(string(d), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(string(d), name), event, 0o644)
+}
+
+type uploadRecorder struct {
+	uploader Uploader
+	prefix   string
+}
+
+// UploadRecorder stores events through an Uploader, e.g. s3store.Uploader,
+// under prefix.
+func UploadRecorder(uploader Uploader, prefix string) EventRecorder {
+	return uploadRecorder{uploader: uploader, prefix: prefix}
+}
+
+func (u uploadRecorder) RecordEvent(ctx context.Context, name string, event []byte) error {
+	return u.uploader.Upload(ctx, u.prefix+name, "application/json", event)
+}
+
+type RecordConfig struct {
+	Recorder EventRecorder
+	// RedactHeaders are replaced with "REDACTED" before recording. Defaults
+	// to the authorization, cookie and x-api-key headers. The router's
+	// RedactionConfig, if set, is applied as well.
+	RedactHeaders []string
+	// Filter limits recording to the requests it accepts.
+	Filter func(events.LambdaFunctionURLRequest) bool
+}
+
+// Record saves every request it sees, before the handler runs, so failing
+// requests can be reproduced with LoadEvent or furl replay. Recording errors
+// are logged and never fail the request.
+func Record(config RecordConfig) MiddlewareFunc {
+	if config.RedactHeaders == nil {
+		config.RedactHeaders = []string{"authorization", "cookie", "x-api-key"}
+	}
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			if config.Filter == nil || config.Filter(req) {
+				if err := recordEvent(ctx, config, req); err != nil {
+					if r := routerFromContext(ctx); r != nil {
+						r.logError(ctx, "Error recording request", err)
+					}
+				}
+			}
+			return next.ServeHTTP(ctx, req)
+		})
+	}
+}
+
+func recordEvent(ctx context.Context, config RecordConfig, req events.LambdaFunctionURLRequest) error {
+	headers := make(map[string]string, len(req.Headers))
+	for k, v := range req.Headers {
+		headers[k] = v
+	}
+	for _, name := range config.RedactHeaders {
+		if _, ok := headers[strings.ToLower(name)]; ok {
+			headers[strings.ToLower(name)] = "REDACTED"
+		}
+	}
+	req.Headers = headers
+	if len(req.Cookies) > 0 && containsFold(config.RedactHeaders, "cookie") {
+		req.Cookies = []string{"REDACTED"}
+	}
+	if r := routerFromContext(ctx); r != nil && r.redaction != nil {
+		req = r.redaction.redactRequest(req)
+	}
+
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.json", time.Now().UTC().Format("20060102T150405.000000000"), req.RequestContext.RequestID)
+	return config.Recorder.RecordEvent(ctx, name, data)
+}
+
+// LoadEvent reads an event written by Record, or any Function URL event
+// saved as JSON, for replay through Router.HandleRequest.
+func LoadEvent(path string) (events.LambdaFunctionURLRequest, error) {
+	var req events.LambdaFunctionURLRequest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return req, err
+	}
+	err = json.Unmarshal(data, &req)
+	return req, err
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type ResponseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func NewResponseRecorder() *ResponseRecorder {
+	return &ResponseRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (rec *ResponseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *ResponseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = status
+	rec.wroteHeader = true
+}
+
+func (rec *ResponseRecorder) Write(p []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(p)
+}
+
+func (rec *ResponseRecorder) WriteString(s string) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.WriteString(s)
+}
+
+func (rec *ResponseRecorder) Flush() {}
+
+// Response converts everything written so far into a Response. Headers with
+// a single value go to Headers, repeated ones to MultiValueHeaders, and
+// non-text bodies are base64-encoded.
+func (rec *ResponseRecorder) Response() Response {
+	resp := Response{
+		StatusCode: rec.status,
+		Headers:    make(map[string]string, len(rec.header)),
+	}
+	if rec.body.Len() > 0 && rec.header.Get("Content-Type") == "" {
+		rec.header.Set("Content-Type", http.DetectContentType(rec.body.Bytes()))
+	}
+	for k, values := range rec.header {
+		switch len(values) {
+		case 0:
+		case 1:
+			resp.Headers[k] = values[0]
+		default:
+			for _, v := range values {
+				resp.AddHeader(k, v)
+			}
+		}
+	}
+
+	if rec.body.Len() == 0 {
+		return resp
+	}
+	if rec.header.Get("Content-Encoding") == "" && isTextContentType(rec.header.Get("Content-Type")) {
+		resp.Body = rec.body.String()
+	} else {
+		resp.Body = base64.StdEncoding.EncodeToString(rec.body.Bytes())
+		resp.IsBase64Encoded = true
+	}
+	return resp
+}
+
+// WriterHandlerFunc adapts net/http style handler code, which writes to an
+// http.ResponseWriter, to the Handler interface.
+type WriterHandlerFunc func(context.Context, http.ResponseWriter, events.LambdaFunctionURLRequest)
+
+func (f WriterHandlerFunc) ServeHTTP(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+	rec := NewResponseRecorder()
+	f(ctx, rec, req)
+	return rec.Response()
+}
+
+// FromHTTPHandler runs a standard http.Handler, such as a chi, gin or echo
+// app, against each Function URL event and converts the recorded output back
+// into a Response.
+func FromHTTPHandler(h http.Handler) Handler {
+	return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		httpReq, err := NewHTTPRequest(ctx, req)
+		if err != nil {
+			return Response{
+				StatusCode: http.StatusBadRequest,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       map[string]string{"error": "Invalid request"},
+			}
+		}
+		defer httpReq.Body.Close()
+		rec := NewResponseRecorder()
+		h.ServeHTTP(rec, httpReq)
+		return rec.Response()
+	})
+}
+
+// NewHTTPRequest converts a Function URL event into the equivalent server-side
+// *http.Request.
+func NewHTTPRequest(ctx context.Context, req events.LambdaFunctionURLRequest) (*http.Request, error) {
+	body, size, err := bodyReader(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	path := req.RawPath
+	if path == "" {
+		path = req.RequestContext.HTTP.Path
+	}
+	target := path
+	if req.RawQueryString != "" {
+		target += "?" + req.RawQueryString
+	}
+
+	method := req.RequestContext.HTTP.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		if c, ok := body.(io.Closer); ok {
+			c.Close()
+		}
+		return nil, err
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	if len(req.Cookies) > 0 {
+		httpReq.Header.Set("Cookie", strings.Join(req.Cookies, "; "))
+	}
+
+	httpReq.Host = req.Headers["host"]
+	if httpReq.Host == "" {
+		httpReq.Host = req.RequestContext.DomainName
+	}
+	httpReq.URL.Host = httpReq.Host
+	if proto := req.Headers["x-forwarded-proto"]; proto != "" {
+		httpReq.URL.Scheme = proto
+	} else {
+		httpReq.URL.Scheme = "https"
+	}
+	if req.RequestContext.HTTP.Protocol != "" {
+		if major, minor, ok := http.ParseHTTPVersion(req.RequestContext.HTTP.Protocol); ok {
+			httpReq.Proto, httpReq.ProtoMajor, httpReq.ProtoMinor = req.RequestContext.HTTP.Protocol, major, minor
+		}
+	}
+	httpReq.RemoteAddr = req.RequestContext.HTTP.SourceIP
+	httpReq.RequestURI = target
+	httpReq.ContentLength = size
+	return httpReq, nil
+}
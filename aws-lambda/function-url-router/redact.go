@@ -0,0 +1,156 @@
+package router
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// RedactionConfig lists values that are replaced before requests reach the
+// access log or Record, so tokens and personal data stay out of CloudWatch
+// and recorded events.
+type RedactionConfig struct {
+	// Headers are matched case-insensitively. "cookie" also covers the
+	// request's Cookies. Defaults to authorization, cookie and x-api-key.
+	Headers []string
+	// QueryParams are matched by exact name.
+	QueryParams []string
+	// JSONPaths are dot-separated paths into JSON request bodies, such as
+	// "password" or "cards.*.number"; "*" matches any key or array element
+	// and numbers select a single element.
+	JSONPaths []string
+	// Replacement defaults to "REDACTED".
+	Replacement string
+}
+
+func (r *Router) SetRedaction(config RedactionConfig) {
+	if config.Headers == nil {
+		config.Headers = []string{"authorization", "cookie", "x-api-key"}
+	}
+	if config.Replacement == "" {
+		config.Replacement = "REDACTED"
+	}
+	r.redaction = &config
+}
+
+// redactRequest returns a copy of req with the configured values replaced.
+// Bodies that are not JSON objects or arrays are left alone.
+func (c *RedactionConfig) redactRequest(req events.LambdaFunctionURLRequest) events.LambdaFunctionURLRequest {
+	if len(req.Headers) > 0 {
+		headers := make(map[string]string, len(req.Headers))
+		for k, v := range req.Headers {
+			if containsFold(c.Headers, k) {
+				v = c.Replacement
+			}
+			headers[k] = v
+		}
+		req.Headers = headers
+	}
+	if len(req.Cookies) > 0 && containsFold(c.Headers, "cookie") {
+		req.Cookies = []string{c.Replacement}
+	}
+	if len(c.QueryParams) > 0 {
+		req.RawQueryString = c.redactQuery(req.RawQueryString)
+		if len(req.QueryStringParameters) > 0 {
+			params := make(map[string]string, len(req.QueryStringParameters))
+			for k, v := range req.QueryStringParameters {
+				if c.redactsParam(k) {
+					v = c.Replacement
+				}
+				params[k] = v
+			}
+			req.QueryStringParameters = params
+		}
+	}
+	if len(c.JSONPaths) > 0 && req.Body != "" {
+		req.Body, req.IsBase64Encoded = c.redactBody(req.Body, req.IsBase64Encoded)
+	}
+	return req
+}
+
+func (c *RedactionConfig) redactsParam(name string) bool {
+	for _, p := range c.QueryParams {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// redactQuery replaces values in a raw query string without reordering it.
+func (c *RedactionConfig) redactQuery(query string) string {
+	if len(c.QueryParams) == 0 || query == "" {
+		return query
+	}
+	parts := strings.Split(query, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(key); err == nil && c.redactsParam(name) {
+			parts[i] = key + "=" + url.QueryEscape(c.Replacement)
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func (c *RedactionConfig) redactBody(body string, isBase64 bool) (string, bool) {
+	data := []byte(body)
+	if isBase64 {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return body, isBase64
+		}
+		data = decoded
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return body, isBase64
+	}
+	switch doc.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return body, isBase64
+	}
+	for _, p := range c.JSONPaths {
+		c.redactPath(doc, strings.Split(strings.TrimPrefix(p, "$."), "."))
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return body, isBase64
+	}
+	return string(out), false
+}
+
+func (c *RedactionConfig) redactPath(node interface{}, segments []string) {
+	if len(segments) == 0 {
+		return
+	}
+	seg, rest := segments[0], segments[1:]
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for k := range v {
+			if seg != "*" && seg != k {
+				continue
+			}
+			if len(rest) == 0 {
+				v[k] = c.Replacement
+			} else {
+				c.redactPath(v[k], rest)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			if seg != "*" && seg != strconv.Itoa(i) {
+				continue
+			}
+			if len(rest) == 0 {
+				v[i] = c.Replacement
+			} else {
+				c.redactPath(v[i], rest)
+			}
+		}
+	}
+}
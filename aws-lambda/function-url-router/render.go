@@ -0,0 +1,88 @@
+package router
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func XML(status int, v interface{}) Response {
+	out, err := xml.Marshal(v)
+	if err != nil {
+		return Response{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       map[string]string{"error": "Internal Server Error"},
+		}
+	}
+	return Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/xml; charset=utf-8"},
+		Body:       xml.Header + string(out),
+	}
+}
+
+func BindXML(ctx context.Context, req events.LambdaFunctionURLRequest, v interface{}) error {
+	body, err := bodyBytes(ctx, req)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(body, v)
+}
+
+func requestBody(req events.LambdaFunctionURLRequest) ([]byte, error) {
+	if req.IsBase64Encoded {
+		return base64.StdEncoding.DecodeString(req.Body)
+	}
+	return []byte(req.Body), nil
+}
+
+// stripBodyIfNotAllowed removes the body and entity headers from responses
+// whose status forbids a body (1xx, 204 and 304).
+func stripBodyIfNotAllowed(resp Response) Response {
+	if resp.StatusCode >= 200 && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotModified {
+		return resp
+	}
+	headers := make(map[string]string, len(resp.Headers))
+	for k, v := range resp.Headers {
+		if !strings.EqualFold(k, "Content-Type") && !strings.EqualFold(k, "Content-Length") {
+			headers[k] = v
+		}
+	}
+	resp.Headers = headers
+	if resp.MultiValueHeaders != nil {
+		multi := make(map[string][]string, len(resp.MultiValueHeaders))
+		for k, v := range resp.MultiValueHeaders {
+			if !strings.EqualFold(k, "Content-Type") && !strings.EqualFold(k, "Content-Length") {
+				multi[k] = v
+			}
+		}
+		resp.MultiValueHeaders = multi
+	}
+	resp.Body = nil
+	resp.IsBase64Encoded = false
+	return resp
+}
+
+// encodeBody serializes a response body for the wire. Strings and byte
+// slices are treated as already encoded; everything else is JSON.
+func encodeBody(c Codec, body interface{}) ([]byte, error) {
+	switch b := body.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(b), nil
+	case []byte:
+		return b, nil
+	case io.Reader:
+		defer closeBody(b)
+		return io.ReadAll(b)
+	default:
+		return c.Marshal(b)
+	}
+}
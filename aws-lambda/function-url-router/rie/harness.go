@@ -0,0 +1,250 @@
+package rie
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+const (
+	defaultImage   = "public.ecr.aws/lambda/provided:al2023"
+	invocationPath = "/2015-03-31/functions/function/invocations"
+	startTimeout   = 30 * time.Second
+)
+
+var ErrNoDocker = errors.New("rie: docker not found in PATH")
+
+type Config struct {
+	// Package is the main package to build, as passed to go build.
+	Package string
+	// Dir is the working directory for go build. Defaults to the current
+	// directory.
+	Dir string
+	// Image defaults to public.ecr.aws/lambda/provided:al2023.
+	Image string
+	// Env is passed to the function.
+	Env map[string]string
+}
+
+// Harness runs a function inside the Lambda Runtime Interface Emulator
+// container so tests exercise the same JSON serialization as a deployed
+// function. It implements http.RoundTripper, converting each request into a
+// Function URL event.
+type Harness struct {
+	endpoint  string
+	container string
+	buildDir  string
+	client    *http.Client
+}
+
+// New starts a harness for the duration of a test. It skips the test when
+// docker is not installed and stops the container on cleanup.
+func New(t testing.TB, config Config) *Harness {
+	t.Helper()
+	h, err := Start(context.Background(), config)
+	if errors.Is(err, ErrNoDocker) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+// Start cross-compiles config.Package for Linux, mounts it as the bootstrap
+// of the emulator image and waits for the container to accept connections.
+func Start(ctx context.Context, config Config) (*Harness, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil, ErrNoDocker
+	}
+	if config.Image == "" {
+		config.Image = defaultImage
+	}
+
+	buildDir, err := os.MkdirTemp("", "rie-")
+	if err != nil {
+		return nil, err
+	}
+	h := &Harness{buildDir: buildDir, client: &http.Client{}}
+	bootstrap := filepath.Join(buildDir, "bootstrap")
+	build := exec.CommandContext(ctx, "go", "build", "-tags", "lambda.norpc", "-o", bootstrap, config.Package)
+	build.Dir = config.Dir
+	build.Env = append(os.Environ(), "GOOS=linux", "GOARCH="+runtime.GOARCH, "CGO_ENABLED=0")
+	if out, err := build.CombinedOutput(); err != nil {
+		h.Close()
+		return nil, fmt.Errorf("rie: build %s: %v\n%s", config.Package, err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		h.Close()
+		return nil, err
+	}
+	args := []string{"run", "-d", "--rm",
+		"-p", "127.0.0.1:" + strconv.Itoa(port) + ":8080",
+		"-v", bootstrap + ":/var/runtime/bootstrap:ro",
+	}
+	for k, v := range config.Env {
+		args = append(args, "-e", k+"="+v)
+	}
+	args = append(args, config.Image, "bootstrap")
+	out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("rie: docker run: %v\n%s", err, out)
+	}
+	h.container = strings.TrimSpace(string(out))
+	h.endpoint = "http://127.0.0.1:" + strconv.Itoa(port) + invocationPath
+
+	if err := waitForPort(ctx, port); err != nil {
+		h.Close()
+		return nil, err
+	}
+	return h, nil
+}
+
+// Client returns an http.Client whose requests are served by the function.
+// Request URLs only need a path, such as http://function/users/42.
+func (h *Harness) Client() *http.Client {
+	return &http.Client{Transport: h}
+}
+
+func (h *Harness) RoundTrip(req *http.Request) (*http.Response, error) {
+	event, err := router.NewFunctionURLRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.Invoke(req.Context(), event)
+	if err != nil {
+		return nil, err
+	}
+
+	body := []byte(resp.Body)
+	if resp.IsBase64Encoded {
+		if body, err = base64.StdEncoding.DecodeString(resp.Body); err != nil {
+			return nil, fmt.Errorf("rie: decode body: %w", err)
+		}
+	}
+	header := make(http.Header, len(resp.Headers))
+	for k, v := range resp.Headers {
+		header.Set(k, v)
+	}
+	for _, cookie := range resp.Cookies {
+		header.Add("Set-Cookie", cookie)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Invoke sends event to the emulator and decodes the Function URL response.
+// A function error is returned as an error.
+func (h *Harness) Invoke(ctx context.Context, event events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+	var resp events.LambdaFunctionURLResponse
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return resp, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return resp, err
+	}
+	httpResp, err := h.client.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	defer httpResp.Body.Close()
+	out, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return resp, err
+	}
+
+	var fnErr struct {
+		ErrorMessage string `json:"errorMessage"`
+		ErrorType    string `json:"errorType"`
+	}
+	if json.Unmarshal(out, &fnErr) == nil && fnErr.ErrorType != "" {
+		return resp, fmt.Errorf("rie: function error %s: %s", fnErr.ErrorType, fnErr.ErrorMessage)
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return resp, fmt.Errorf("rie: decode response: %w: %s", err, out)
+	}
+	return resp, nil
+}
+
+// Logs returns the container's output so far, useful in failure messages.
+func (h *Harness) Logs() string {
+	if h.container == "" {
+		return ""
+	}
+	out, _ := exec.Command("docker", "logs", h.container).CombinedOutput()
+	return string(out)
+}
+
+// Close stops the container and removes the build directory.
+func (h *Harness) Close() error {
+	var err error
+	if h.container != "" {
+		if out, stopErr := exec.Command("docker", "stop", h.container).CombinedOutput(); stopErr != nil {
+			err = fmt.Errorf("rie: docker stop: %v\n%s", stopErr, out)
+		}
+		h.container = ""
+	}
+	os.RemoveAll(h.buildDir)
+	return err
+}
+
+func freePort() (int, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port, nil
+}
+
+func waitForPort(ctx context.Context, port int) error {
+	deadline := time.Now().Add(startTimeout)
+	addr := "127.0.0.1:" + strconv.Itoa(port)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("rie: emulator did not start on %s: %w", addr, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
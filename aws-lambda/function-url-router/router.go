@@ -6,7 +6,10 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -23,9 +26,38 @@ func (f HandlerFunc) ServeHTTP(ctx context.Context, req events.LambdaFunctionURL
 }
 
 type Response struct {
-	StatusCode int               `json:"statusCode"`
-	Headers    map[string]string `json:"headers"`
-	Body       interface{}       `json:"body"`
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Body              interface{}         `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded,omitempty"`
+}
+
+// AddHeader appends a value for key without replacing earlier ones. Values
+// added this way are emitted after any value set in Headers.
+func (r *Response) AddHeader(key, value string) {
+	if r.MultiValueHeaders == nil {
+		r.MultiValueHeaders = make(map[string][]string)
+	}
+	key = http.CanonicalHeaderKey(key)
+	r.MultiValueHeaders[key] = append(r.MultiValueHeaders[key], value)
+}
+
+// HeaderValues returns every value of key across Headers and
+// MultiValueHeaders.
+func (r Response) HeaderValues(key string) []string {
+	var values []string
+	for k, v := range r.Headers {
+		if strings.EqualFold(k, key) {
+			values = append(values, v)
+		}
+	}
+	for k, v := range r.MultiValueHeaders {
+		if strings.EqualFold(k, key) {
+			values = append(values, v...)
+		}
+	}
+	return values
 }
 
 type MiddlewareFunc func(Handler) Handler
@@ -45,11 +77,31 @@ type Router struct {
 	routes                  map[string]map[string]Handler
 	preMiddleware           []Middleware
 	postMiddleware          []Middleware
+	table                   atomic.Pointer[routeTable]
+	updateMu                sync.Mutex
 	notFoundHandler         Handler
 	methodNotAllowedHandler Handler
 	panicHandler            func(context.Context, events.LambdaFunctionURLRequest) Response
 	stripTrailingSlash      bool
+	panicConfig             PanicConfig
+	codecs                  map[string]Codec
+	codecOrder              []string
+	jsonCodec               Codec
+	devMode                 bool
+	compression             *CompressionConfig
+	cors                    *CORSConfig
+	metadata                map[string]map[string]string
+	accessLog               *AccessLogConfig
+	logLevel                *LogLevelConfig
+	redaction               *RedactionConfig
+	metrics                 *httpMetrics
+	healthPaths             map[string]bool
+	errorSamples            *errorSamples
+	latency                 *latencyTracker
+	alerts                  *alertTracker
+	bodyConfig              BodyConfig
 	logger                  *log.Logger
+	slogger                 Logger
 }
 
 func NewRouter(logger *log.Logger) *Router {
@@ -59,6 +111,8 @@ func NewRouter(logger *log.Logger) *Router {
 	r := &Router{
 		routes:             make(map[string]map[string]Handler),
 		stripTrailingSlash: true,
+		codecs:             make(map[string]Codec),
+		jsonCodec:          jsonCodec{},
 		logger:             logger,
 	}
 	r.notFoundHandler = HandlerFunc(defaultNotFoundHandler)
@@ -67,19 +121,33 @@ func NewRouter(logger *log.Logger) *Router {
 	return r
 }
 
-func (r *Router) AddRoute(method, path string, handler Handler) {
+// AddRoute, UsePre and UsePost return ErrFrozen once the router is
+// frozen; use Update instead.
+func (r *Router) AddRoute(method, path string, handler Handler) error {
+	if r.table.Load() != nil {
+		return ErrFrozen
+	}
 	if r.routes[path] == nil {
 		r.routes[path] = make(map[string]Handler)
 	}
 	r.routes[path][method] = handler
+	return nil
 }
 
-func (r *Router) UsePre(mw MiddlewareFunc, config MiddlewareConfig) {
+func (r *Router) UsePre(mw MiddlewareFunc, config MiddlewareConfig) error {
+	if r.table.Load() != nil {
+		return ErrFrozen
+	}
 	r.preMiddleware = append(r.preMiddleware, Middleware{Func: mw, Config: config})
+	return nil
 }
 
-func (r *Router) UsePost(mw MiddlewareFunc, config MiddlewareConfig) {
+func (r *Router) UsePost(mw MiddlewareFunc, config MiddlewareConfig) error {
+	if r.table.Load() != nil {
+		return ErrFrozen
+	}
 	r.postMiddleware = append(r.postMiddleware, Middleware{Func: mw, Config: config})
+	return nil
 }
 
 func (r *Router) SetNotFoundHandler(handler Handler) {
@@ -98,75 +166,175 @@ func (r *Router) SetStripTrailingSlash(strip bool) {
 	r.stripTrailingSlash = strip
 }
 
-func (r *Router) HandleRequest(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+// HandleRequest routes req and returns the response. resp is named so the
+// deferred recovery can replace it with the panic handler's response, which
+// gets CORS headers like any other so browsers can read the error.
+func (r *Router) HandleRequest(ctx context.Context, req events.LambdaFunctionURLRequest) (resp Response) {
 	startTime := time.Now()
-	var resp Response
-	var err error
+	path := req.RequestContext.HTTP.Path
+	method := req.RequestContext.HTTP.Method
+	if r.stripTrailingSlash {
+		path = strings.TrimRight(path, "/")
+	}
+	route, matched := r.routing().chains[routeKey{method: method, path: path}]
+	if route.direct {
+		return r.serveDirect(ctx, req, route.handler, path, startTime)
+	}
 
+	var err error
 	defer func() {
 		duration := time.Since(startTime)
 		if e := recover(); e != nil {
 			err = fmt.Errorf("panic: %v", e)
-			resp = r.panicHandler(ctx, req)
+			ctx = context.WithValue(ctx, panicKey, r.recoverPanic(ctx, req, e))
+			resp = r.corsResponse(req, r.panicHandler(ctx, req))
 		}
-		r.logRequestCompletion(req, resp, duration, err)
+		resp = r.finishRequest(ctx, req, resp, duration, err)
 	}()
 
-	path := req.RequestContext.HTTP.Path
-	method := req.RequestContext.HTTP.Method
+	rc := &requestContext{Context: ctx, router: r, coldStart: beginInvocation(startTime)}
+	rc.trace, rc.traced = requestTrace(ctx, req)
+	rc.correlationID = requestCorrelationID(ctx, req)
+	ctx = rc
+
+	if r.isPreflight(req, path) {
+		resp = r.preflightResponse(req, path)
+	} else if matched {
+		rc.route = path
+		resp = route.handler.ServeHTTP(ctx, req)
+	} else if r.hasRoute(path) {
+		resp = r.methodNotAllowedHandler.ServeHTTP(ctx, req)
+	} else {
+		resp = r.notFoundHandler.ServeHTTP(ctx, req)
+	}
+	resp = r.corsResponse(req, resp)
+	resp = r.compressResponse(req, resp)
+	return resp
+}
 
-	if r.stripTrailingSlash {
-		path = strings.TrimRight(path, "/")
+// serveDirect is HandleRequest for a GET or HEAD route without middleware,
+// such as a health check. With no chain to run it calls the handler
+// directly, and only that call is deferred to recover panics; preflight and
+// fallback handling don't apply to these routes.
+func (r *Router) serveDirect(ctx context.Context, req events.LambdaFunctionURLRequest, handler Handler, path string, startTime time.Time) Response {
+	rc := &requestContext{Context: ctx, router: r, coldStart: beginInvocation(startTime), route: path}
+	rc.trace, rc.traced = requestTrace(ctx, req)
+	rc.correlationID = requestCorrelationID(ctx, req)
+	ctx = rc
+
+	resp, panicCtx, err := r.callHandler(ctx, req, handler)
+	resp = r.corsResponse(req, resp)
+	if panicCtx != nil {
+		ctx = panicCtx
+	} else {
+		resp = r.compressResponse(req, resp)
 	}
+	return r.finishRequest(ctx, req, resp, time.Since(startTime), err)
+}
 
-	if handlers, ok := r.routes[path]; ok {
-		if handler, ok := handlers[method]; ok {
-			handler = r.applyMiddleware(handler)
-			resp = handler.ServeHTTP(ctx, req)
-			return resp
+// callHandler recovers a panic in handler as HandleRequest does, returning
+// the panic handler's response and the context carrying the report.
+func (r *Router) callHandler(ctx context.Context, req events.LambdaFunctionURLRequest, handler Handler) (resp Response, panicCtx context.Context, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+			panicCtx = context.WithValue(ctx, panicKey, r.recoverPanic(ctx, req, e))
+			resp = r.panicHandler(panicCtx, req)
 		}
-		resp = r.methodNotAllowedHandler.ServeHTTP(ctx, req)
-		return resp
+	}()
+	return handler.ServeHTTP(ctx, req), nil, nil
+}
+
+// finishRequest logs and records a request once its response is known,
+// returning the response with the body the access log encoded, if any.
+func (r *Router) finishRequest(ctx context.Context, req events.LambdaFunctionURLRequest, resp Response, duration time.Duration, err error) Response {
+	r.logCompletion(ctx, req, &resp, duration, err)
+	r.sampleError(ctx, req, resp, err)
+	if r.latency != nil {
+		r.latency.observe(ctx, req.RequestContext.HTTP.Method, MatchedRoute(ctx), duration)
 	}
-	resp = r.notFoundHandler.ServeHTTP(ctx, req)
+	r.checkAlerts(ctx, req, resp, duration, err)
 	return resp
 }
 
-func (r *Router) applyMiddleware(handler Handler) Handler {
-	for i := len(r.postMiddleware) - 1; i >= 0; i-- {
-		mw := r.postMiddleware[i]
-		handler = mw.Func(handler)
+func (r *Router) hasRoute(path string) bool {
+	if r.stripTrailingSlash {
+		path = strings.TrimRight(path, "/")
 	}
+	_, ok := r.routing().routes[path]
+	return ok
+}
 
-	for i := len(r.preMiddleware) - 1; i >= 0; i-- {
-		mw := r.preMiddleware[i]
-		handler = mw.Func(handler)
+func (r *Router) hasHandler(method, path string) bool {
+	if r.stripTrailingSlash {
+		path = strings.TrimRight(path, "/")
 	}
-
-	return handler
+	_, ok := r.routing().routes[path][method]
+	return ok
 }
 
-func (r *Router) logRequestCompletion(req events.LambdaFunctionURLRequest, resp Response, duration time.Duration, err error) {
-	logEntry := fmt.Sprintf(
-		"Request completed: method=%s path=%s status=%d duration=%v",
-		req.RequestContext.HTTP.Method,
-		req.RequestContext.HTTP.Path,
-		resp.StatusCode,
-		duration,
-	)
+// logRequestCompletion writes the default completion line. It's appended to
+// a pooled buffer instead of formatted with fmt, which avoids fmt's
+// per-argument allocations; the line is still copied into a string for the
+// logger.
+func (r *Router) logRequestCompletion(ctx context.Context, req events.LambdaFunctionURLRequest, resp Response, duration time.Duration, err error) {
+	numeric := r.accessLog != nil && r.accessLog.NumericDurations
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	buf.WriteString("Request completed: method=")
+	buf.WriteString(req.RequestContext.HTTP.Method)
+	buf.WriteString(" path=")
+	buf.WriteString(req.RequestContext.HTTP.Path)
+	buf.WriteString(" status=")
+	buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(resp.StatusCode), 10))
+	if numeric {
+		buf.WriteString(" duration_ms=")
+		buf.Write(appendMillis(buf.AvailableBuffer(), duration))
+	} else {
+		buf.WriteString(" duration=")
+		buf.WriteString(duration.String())
+	}
 
+	if trace, ok := Trace(ctx); ok {
+		buf.WriteString(" trace_id=")
+		buf.WriteString(trace.TraceID)
+	}
+	if diag := Diagnostics(ctx); diag.Remaining != 0 {
+		if numeric {
+			buf.WriteString(" remaining_ms=")
+			buf.Write(appendMillis(buf.AvailableBuffer(), diag.Remaining))
+		} else {
+			buf.WriteString(" remaining=")
+			buf.WriteString(diag.Remaining.Round(time.Millisecond).String())
+		}
+	}
 	if err != nil {
-		logEntry += fmt.Sprintf(" error=%v", err)
+		buf.WriteString(" error=")
+		buf.WriteString(err.Error())
 	}
 
-	r.logger.Println(logEntry)
+	r.logger.Output(1, buf.String())
 }
 
+func appendMillis(b []byte, d time.Duration) []byte {
+	return strconv.AppendFloat(b, float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// The fallback bodies are encoded JSON strings, which can't be modified in
+// place, so every 404 and 405 shares them and allocates only its headers.
+// The header maps stay per response since middleware and corsResponse add
+// to them.
+const (
+	notFoundBody         = `{"error":"Not Found"}`
+	methodNotAllowedBody = `{"error":"Method Not Allowed"}`
+)
+
 func defaultNotFoundHandler(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
 	return Response{
 		StatusCode: http.StatusNotFound,
 		Headers:    map[string]string{"Content-Type": "application/json"},
-		Body:       map[string]string{"error": "Not Found"},
+		Body:       notFoundBody,
 	}
 }
 
@@ -174,11 +342,18 @@ func defaultMethodNotAllowedHandler(ctx context.Context, req events.LambdaFuncti
 	return Response{
 		StatusCode: http.StatusMethodNotAllowed,
 		Headers:    map[string]string{"Content-Type": "application/json"},
-		Body:       map[string]string{"error": "Method Not Allowed"},
+		Body:       methodNotAllowedBody,
 	}
 }
 
 func defaultPanicHandler(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+	if report, ok := Recovered(ctx); ok && IsDevMode(ctx) {
+		return Response{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       devPanicBody(report),
+		}
+	}
 	return Response{
 		StatusCode: http.StatusInternalServerError,
 		Headers:    map[string]string{"Content-Type": "application/json"},
@@ -0,0 +1,140 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Run with furl bench to compare against testdata/bench.json.
+
+func benchRouter(routes int) *Router {
+	r := NewRouter(log.New(io.Discard, "", 0))
+	r.SetAccessLog(AccessLogConfig{Format: AccessLogOff})
+	ok := HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		return Response{StatusCode: http.StatusOK}
+	})
+	for i := 0; i < routes; i++ {
+		r.AddRoute(http.MethodGet, fmt.Sprintf("/resource%d/items", i), ok)
+	}
+	return r
+}
+
+func benchRequest(method, path string) events.LambdaFunctionURLRequest {
+	var req events.LambdaFunctionURLRequest
+	req.RequestContext.HTTP.Method = method
+	req.RequestContext.HTTP.Path = path
+	return req
+}
+
+func passThrough(next Handler) Handler {
+	return HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+		return next.ServeHTTP(ctx, req)
+	})
+}
+
+func BenchmarkRouteLookup(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		r := benchRouter(size)
+		cases := []struct {
+			name string
+			req  events.LambdaFunctionURLRequest
+		}{
+			{"static", benchRequest(http.MethodGet, fmt.Sprintf("/resource%d/items", size/2))},
+			{"trailing-slash", benchRequest(http.MethodGet, fmt.Sprintf("/resource%d/items/", size/2))},
+			{"not-found", benchRequest(http.MethodGet, "/missing")},
+			{"method-not-allowed", benchRequest(http.MethodPost, "/resource0/items")},
+		}
+		for _, c := range cases {
+			b.Run(fmt.Sprintf("%s/routes=%d", c.name, size), func(b *testing.B) {
+				ctx := context.Background()
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					r.HandleRequest(ctx, c.req)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkMiddlewareChain(b *testing.B) {
+	for _, depth := range []int{0, 1, 5, 10} {
+		r := benchRouter(10)
+		for i := 0; i < depth; i++ {
+			r.UsePre(passThrough, MiddlewareConfig{})
+		}
+		req := benchRequest(http.MethodGet, "/resource5/items")
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			ctx := context.Background()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.HandleRequest(ctx, req)
+			}
+		})
+	}
+}
+
+type benchItem struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Price float64  `json:"price"`
+}
+
+func BenchmarkRenderJSON(b *testing.B) {
+	for _, n := range []int{1, 100} {
+		items := make([]benchItem, n)
+		for i := range items {
+			items[i] = benchItem{ID: i, Name: fmt.Sprintf("item %d", i), Tags: []string{"a", "b"}, Price: 9.99}
+		}
+		r := NewRouter(log.New(io.Discard, "", 0))
+		r.SetAccessLog(AccessLogConfig{Format: AccessLogOff})
+		r.AddRoute(http.MethodGet, "/items", HandlerFunc(func(ctx context.Context, req events.LambdaFunctionURLRequest) Response {
+			return Response{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+				Body:       items,
+			}
+		}))
+		handler := r.LambdaHandler()
+		req := benchRequest(http.MethodGet, "/items")
+		b.Run(fmt.Sprintf("items=%d", n), func(b *testing.B) {
+			ctx := context.Background()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := handler(ctx, req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCompletionLog(b *testing.B) {
+	r := benchRouter(10)
+	r.logger = log.New(io.Discard, "", 0)
+	r.accessLog = nil
+	req := benchRequest(http.MethodGet, "/resource5/items")
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.HandleRequest(ctx, req)
+	}
+}
+
+func BenchmarkCompletionLogNumeric(b *testing.B) {
+	r := benchRouter(10)
+	r.logger = log.New(io.Discard, "", 0)
+	r.SetAccessLog(AccessLogConfig{NumericDurations: true})
+	req := benchRequest(http.MethodGet, "/resource5/items")
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.HandleRequest(ctx, req)
+	}
+}
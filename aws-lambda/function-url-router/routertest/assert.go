@@ -0,0 +1,146 @@
+package routertest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// Body returns resp's body as a client would receive it: strings and byte
+// slices as they are, base64 bodies decoded and any other value encoded as
+// JSON. A streamed body is read, so it can only be checked once.
+func Body(resp router.Response) ([]byte, error) {
+	switch b := resp.Body.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if resp.IsBase64Encoded {
+			return base64.StdEncoding.DecodeString(b)
+		}
+		return []byte(b), nil
+	case []byte:
+		return b, nil
+	case io.Reader:
+		if c, ok := b.(io.Closer); ok {
+			defer c.Close()
+		}
+		return io.ReadAll(b)
+	default:
+		return json.Marshal(b)
+	}
+}
+
+// Cookies parses the cookies resp sets.
+func Cookies(resp router.Response) []*http.Cookie {
+	header := http.Header{"Set-Cookie": resp.HeaderValues("Set-Cookie")}
+	return (&http.Response{Header: header}).Cookies()
+}
+
+func AssertStatus(t testing.TB, resp router.Response, want int) {
+	t.Helper()
+	if resp.StatusCode != want {
+		t.Errorf("status = %d %s, want %d %s", resp.StatusCode, http.StatusText(resp.StatusCode), want, http.StatusText(want))
+	}
+}
+
+// AssertHeader checks a header's value, matching its name case-insensitively.
+// Repeated values are compared joined with ", ", as Function URLs send them.
+func AssertHeader(t testing.TB, resp router.Response, key, want string) {
+	t.Helper()
+	values := resp.HeaderValues(key)
+	if len(values) == 0 {
+		t.Errorf("header %s is missing, want %q", key, want)
+		return
+	}
+	if got := strings.Join(values, ", "); got != want {
+		t.Errorf("header %s = %q, want %q", key, got, want)
+	}
+}
+
+func AssertNoHeader(t testing.TB, resp router.Response, key string) {
+	t.Helper()
+	if values := resp.HeaderValues(key); len(values) > 0 {
+		t.Errorf("header %s = %q, want none", key, strings.Join(values, ", "))
+	}
+}
+
+// AssertCookie checks that resp sets the cookie name to value.
+func AssertCookie(t testing.TB, resp router.Response, name, value string) {
+	t.Helper()
+	for _, cookie := range Cookies(resp) {
+		if cookie.Name == name {
+			if cookie.Value != value {
+				t.Errorf("cookie %s = %q, want %q", name, cookie.Value, value)
+			}
+			return
+		}
+	}
+	t.Errorf("cookie %s is not set, want %q", name, value)
+}
+
+func AssertBody(t testing.TB, resp router.Response, want string) {
+	t.Helper()
+	got, err := Body(resp)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	if string(got) == want {
+		return
+	}
+	if strings.Contains(want, "\n") || strings.Contains(string(got), "\n") {
+		t.Errorf("body differs (-want +got):\n%s", lineDiff(string(got), want))
+		return
+	}
+	t.Errorf("body = %q, want %q", got, want)
+}
+
+// AssertJSONBody checks that the body is JSON equal to want, ignoring
+// formatting and key order. want is a value to encode, or a string or byte
+// slice holding JSON.
+func AssertJSONBody(t testing.TB, resp router.Response, want interface{}) {
+	t.Helper()
+	body, err := Body(resp)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	var got interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, body)
+	}
+
+	var wantJSON []byte
+	switch w := want.(type) {
+	case string:
+		wantJSON = []byte(w)
+	case []byte:
+		wantJSON = w
+	default:
+		if wantJSON, err = json.Marshal(w); err != nil {
+			t.Fatalf("encoding want: %v", err)
+		}
+	}
+	var expected interface{}
+	if err := json.Unmarshal(wantJSON, &expected); err != nil {
+		t.Fatalf("want is not JSON: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("JSON body differs (-want +got):\n%s", lineDiff(string(indentJSON(got)), string(indentJSON(expected))))
+	}
+}
+
+func indentJSON(v interface{}) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
@@ -0,0 +1,23 @@
+package routertest
+
+import "testing"
+
+func TestLineDiff(t *testing.T) {
+	cases := []struct {
+		name, got, want, diff string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", "  a\n  b\n"},
+		{"changed line", "a\nx\nc", "a\nb\nc", "  a\n- b\n+ x\n  c\n"},
+		{"added line", "a\nb\nc", "a\nc", "  a\n+ b\n  c\n"},
+		{"removed line", "a\nc", "a\nb\nc", "  a\n- b\n  c\n"},
+		{"trailing newline ignored", "a\n", "a", "  a\n"},
+		{"all different", "x\ny", "a", "- a\n+ x\n+ y\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := lineDiff(c.got, c.want); got != c.diff {
+				t.Errorf("lineDiff(%q, %q) =\n%s\nwant\n%s", c.got, c.want, got, c.diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package routertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// Case is a request and what its response should contain. Fields left at
+// their zero value aren't checked.
+type Case struct {
+	// Name defaults to the method and path.
+	Name    string
+	Method  string
+	Path    string
+	Headers map[string]string
+	// Body is sent as is when it's a string or byte slice, with any
+	// Content-Type from Headers, and encoded as JSON otherwise.
+	Body interface{}
+
+	WantStatus  int
+	WantHeaders map[string]string
+	// WantBody is compared exactly and WantJSON as in AssertJSONBody.
+	WantBody string
+	WantJSON interface{}
+	// WantSnapshot names a golden file to compare the response with, as
+	// in AssertSnapshot.
+	WantSnapshot string
+	// Check runs after the other checks, for anything they don't cover.
+	Check func(t testing.TB, resp router.Response)
+}
+
+// Run sends each case through r.HandleRequest as a subtest, so requests go
+// through the same middleware, CORS and compression as deployed ones.
+// Failures show the case's request and a diff of the body.
+func Run(t *testing.T, r *router.Router, cases []Case) {
+	t.Helper()
+	for _, c := range cases {
+		c := c
+		name := c.Name
+		if name == "" {
+			name = c.Method + " " + c.Path
+		}
+		t.Run(name, func(t *testing.T) {
+			resp := r.HandleRequest(context.Background(), c.request())
+			if c.WantStatus != 0 {
+				AssertStatus(t, resp, c.WantStatus)
+			}
+			for k, v := range c.WantHeaders {
+				AssertHeader(t, resp, k, v)
+			}
+			if c.WantBody != "" {
+				AssertBody(t, resp, c.WantBody)
+			}
+			if c.WantJSON != nil {
+				AssertJSONBody(t, resp, c.WantJSON)
+			}
+			if c.WantSnapshot != "" {
+				AssertSnapshot(t, resp, c.WantSnapshot)
+			}
+			if c.Check != nil {
+				c.Check(t, resp)
+			}
+			if t.Failed() {
+				t.Logf("request: %s %s", c.Method, c.Path)
+			}
+		})
+	}
+}
+
+func (c Case) request() events.LambdaFunctionURLRequest {
+	b := NewRequest(c.Method, c.Path)
+	for k, v := range c.Headers {
+		b.Header(k, v)
+	}
+	switch body := c.Body.(type) {
+	case nil:
+	case string:
+		b.Body("", []byte(body))
+	case []byte:
+		b.Body("", body)
+	default:
+		b.JSON(body)
+	}
+	return b.Build()
+}
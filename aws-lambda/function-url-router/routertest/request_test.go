@@ -0,0 +1,77 @@
+package routertest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestBuildBody(t *testing.T) {
+	binary := []byte{0xff, 0x00, 0x01}
+	cases := []struct {
+		name        string
+		builder     *RequestBuilder
+		body        string
+		base64      bool
+		contentType string
+	}{
+		{"JSON", NewRequest(http.MethodPost, "/").JSON(map[string]int{"a": 1}), `{"a":1}`, false, "application/json"},
+		{"text", NewRequest(http.MethodPost, "/").Text("héllo"), "héllo", false, "text/plain; charset=utf-8"},
+		{"binary content type", NewRequest(http.MethodPost, "/").Body("application/octet-stream", []byte("abc")), base64.StdEncoding.EncodeToString([]byte("abc")), true, "application/octet-stream"},
+		{"invalid UTF-8", NewRequest(http.MethodPost, "/").Body("text/plain", binary), base64.StdEncoding.EncodeToString(binary), true, "text/plain"},
+		{"empty", NewRequest(http.MethodGet, "/"), "", false, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := c.builder.Build()
+			if req.Body != c.body || req.IsBase64Encoded != c.base64 {
+				t.Errorf("body = %q base64 %v, want %q base64 %v", req.Body, req.IsBase64Encoded, c.body, c.base64)
+			}
+			if got := req.Headers["content-type"]; got != c.contentType {
+				t.Errorf("content-type = %q, want %q", got, c.contentType)
+			}
+		})
+	}
+}
+
+func TestBuildCookies(t *testing.T) {
+	req := NewRequest(http.MethodGet, "/").
+		Cookie(&http.Cookie{Name: "session", Value: "abc"}).
+		Cookie(&http.Cookie{Name: "theme", Value: "dark"}).
+		Build()
+	if fmt.Sprint(req.Cookies) != "[session=abc theme=dark]" {
+		t.Errorf("Cookies = %q, want session and theme", req.Cookies)
+	}
+	if _, ok := req.Headers["cookie"]; ok {
+		t.Error("cookie header was left in Headers")
+	}
+}
+
+func TestBuildQuery(t *testing.T) {
+	req := NewRequest(http.MethodGet, "/users?page=2&tag=a").
+		Query("tag", "b").
+		Query("sort", "name").
+		Build()
+	if req.RawPath != "/users" {
+		t.Errorf("RawPath = %q, want /users", req.RawPath)
+	}
+	want := map[string]string{"page": "2", "tag": "a,b", "sort": "name"}
+	if fmt.Sprint(req.QueryStringParameters) != fmt.Sprint(want) {
+		t.Errorf("QueryStringParameters = %v, want %v", req.QueryStringParameters, want)
+	}
+}
+
+func TestBuildHeaders(t *testing.T) {
+	req := NewRequest(http.MethodGet, "/").
+		Header("X-Tag", "a").
+		Header("X-Tag", "b").
+		Bearer("token").
+		Build()
+	if req.Headers["x-tag"] != "a,b" {
+		t.Errorf("x-tag = %q, want a,b", req.Headers["x-tag"])
+	}
+	if req.Headers["authorization"] != "Bearer token" {
+		t.Errorf("authorization = %q", req.Headers["authorization"])
+	}
+}
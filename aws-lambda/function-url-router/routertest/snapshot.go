@@ -0,0 +1,158 @@
+package routertest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+var updateSnapshots = flag.Bool("routertest.update", false, "rewrite routertest snapshot files instead of comparing with them")
+
+// Snapshot compares responses with golden files, so changes to an API's
+// responses show up in review as changes to those files. Run the tests with
+// -routertest.update to write the files after an intended change.
+type Snapshot struct {
+	// Dir holds the files, named after the snapshot with a .golden
+	// extension. Defaults to testdata/snapshots.
+	Dir string
+	// IgnoreHeaders are left out of the snapshot, for values that change
+	// between runs such as Date.
+	IgnoreHeaders []string
+	// IgnoreFields replaces the values of JSON object keys with these
+	// names, at any depth, for generated IDs and timestamps.
+	IgnoreFields []string
+}
+
+// AssertSnapshot compares resp with the golden file name using the default
+// Snapshot.
+func AssertSnapshot(t testing.TB, resp router.Response, name string) {
+	t.Helper()
+	Snapshot{}.Assert(t, resp, name)
+}
+
+func (s Snapshot) Assert(t testing.TB, resp router.Response, name string) {
+	t.Helper()
+	got, err := s.render(resp)
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", name, err)
+	}
+	dir := s.Dir
+	if dir == "" {
+		dir = filepath.Join("testdata", "snapshots")
+	}
+	path := filepath.Join(dir, name+".golden")
+
+	if *updateSnapshots {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("snapshot %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("snapshot %s: %v", name, err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("snapshot %s doesn't exist, run with -routertest.update to create it:\n%s", path, got)
+		return
+	}
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", name, err)
+	}
+	if got != string(want) {
+		t.Errorf("response differs from %s (-want +got), run with -routertest.update to accept:\n%s", path, lineDiff(got, string(want)))
+	}
+}
+
+// render writes the status, the headers sorted by name and the body, with
+// JSON indented so diffs are line by line.
+func (s Snapshot) render(resp router.Response) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s\n", resp.StatusCode, http.StatusText(resp.StatusCode))
+
+	names := make(map[string]bool)
+	for k := range resp.Headers {
+		names[http.CanonicalHeaderKey(k)] = true
+	}
+	for k := range resp.MultiValueHeaders {
+		names[http.CanonicalHeaderKey(k)] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		if !containsFold(s.IgnoreHeaders, name) {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		values := resp.HeaderValues(name)
+		if name == "Set-Cookie" {
+			for _, v := range values {
+				fmt.Fprintf(&b, "%s: %s\n", name, v)
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, strings.Join(values, ", "))
+	}
+
+	body, err := Body(resp)
+	if err != nil {
+		return "", err
+	}
+	if len(body) == 0 {
+		return b.String(), nil
+	}
+	b.WriteString("\n")
+	var v interface{}
+	switch {
+	case json.Unmarshal(body, &v) == nil:
+		b.Write(indentJSON(s.ignoreFields(v)))
+	case utf8.Valid(body):
+		b.Write(body)
+	default:
+		b.WriteString("base64:" + base64.StdEncoding.EncodeToString(body))
+	}
+	if !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+func (s Snapshot) ignoreFields(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if containsFold(s.IgnoreFields, k) {
+				v[k] = "<ignored>"
+			} else {
+				v[k] = s.ignoreFields(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = s.ignoreFields(item)
+		}
+	}
+	return v
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package routertest
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// recordingTB collects the errors an assertion reports instead of failing
+// the test running it.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func snapshotResponse(id string) router.Response {
+	return router.Response{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"content-type": "application/json", "date": id},
+		Body:       map[string]string{"id": id, "name": "ada"},
+	}
+}
+
+func TestSnapshotUpdateAndCompare(t *testing.T) {
+	s := Snapshot{Dir: t.TempDir(), IgnoreHeaders: []string{"Date"}, IgnoreFields: []string{"id"}}
+
+	*updateSnapshots = true
+	t.Cleanup(func() { *updateSnapshots = false })
+	s.Assert(t, snapshotResponse("1"), "users/get")
+	*updateSnapshots = false
+
+	data, err := os.ReadFile(filepath.Join(s.Dir, "users", "get.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "200 OK\nContent-Type: application/json\n\n{\n  \"id\": \"<ignored>\",\n  \"name\": \"ada\"\n}\n"
+	if string(data) != want {
+		t.Errorf("snapshot file =\n%s\nwant\n%s", data, want)
+	}
+
+	// Ignored headers and fields may change without failing.
+	s.Assert(t, snapshotResponse("2"), "users/get")
+
+	changed := snapshotResponse("3")
+	changed.Body = map[string]string{"id": "3", "name": "grace"}
+	rec := &recordingTB{TB: t}
+	s.Assert(rec, changed, "users/get")
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "-   \"name\": \"ada\"\n+   \"name\": \"grace\"") {
+		t.Errorf("changed response reported %q, want a diff of the name", rec.errors)
+	}
+
+	rec = &recordingTB{TB: t}
+	s.Assert(rec, changed, "users/missing")
+	if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "doesn't exist") {
+		t.Errorf("missing snapshot reported %q", rec.errors)
+	}
+}
@@ -0,0 +1,137 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Route describes a registered route for listings and generators.
+type Route struct {
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	Handler    string            `json:"handler"`
+	Middleware []string          `json:"middleware,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+}
+
+// SetRouteMetadata attaches free-form key/value pairs, such as an owner or
+// a description, to a route. They are reported by Routes and PrintRoutes.
+func (r *Router) SetRouteMetadata(method, path string, metadata map[string]string) {
+	if r.metadata == nil {
+		r.metadata = make(map[string]map[string]string)
+	}
+	r.metadata[method+" "+path] = metadata
+}
+
+func (r *Router) middlewareNames() []string {
+	t := r.registered()
+	var names []string
+	for _, mw := range t.preMiddleware {
+		names = append(names, funcName(mw.Func))
+	}
+	for _, mw := range t.postMiddleware {
+		names = append(names, funcName(mw.Func))
+	}
+	return names
+}
+
+// MatchedRoute returns the registered path that matched the request handled
+// with ctx, for labeling metrics without the cardinality of raw paths. It
+// is empty in not found and method not allowed handlers.
+func MatchedRoute(ctx context.Context) string {
+	if c := requestFromContext(ctx); c != nil {
+		return c.route
+	}
+	return ""
+}
+
+// Routes returns the registered routes sorted by path and then method.
+// Each route lists the middleware not excluded for it by route or method.
+func (r *Router) Routes() []Route {
+	t := r.registered()
+	var routes []Route
+	for path, handlers := range t.routes {
+		for method, handler := range handlers {
+			var middleware []string
+			for _, mw := range t.middleware(method, path) {
+				middleware = append(middleware, funcName(mw.Func))
+			}
+			routes = append(routes, Route{
+				Method:     method,
+				Path:       path,
+				Handler:    handlerName(handler),
+				Middleware: middleware,
+				Metadata:   r.metadata[method+" "+path],
+			})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// PrintRoutes writes the route table as aligned text columns.
+func (r *Router) PrintRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER\tMIDDLEWARE\tMETADATA")
+	for _, route := range r.Routes() {
+		keys := make([]string, 0, len(route.Metadata))
+		for k := range route.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, k := range keys {
+			pairs[i] = k + "=" + route.Metadata[k]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Handler,
+			strings.Join(route.Middleware, ","), strings.Join(pairs, " "))
+	}
+	return tw.Flush()
+}
+
+// WriteRoutesJSON writes the route table as a JSON array, for diffing in CI.
+func (r *Router) WriteRoutesJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Routes())
+}
+
+func handlerName(h Handler) string {
+	if f, ok := h.(HandlerFunc); ok {
+		return funcName(f)
+	}
+	return fmt.Sprintf("%T", h)
+}
+
+// funcName returns a short name such as router.Record for fn, dropping the
+// import path and the suffixes Go gives closures.
+func funcName(fn interface{}) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for {
+		i := strings.LastIndex(name, ".func")
+		if i < 0 || strings.Trim(name[i+len(".func"):], "0123456789.") != "" {
+			break
+		}
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,229 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	contentTypeProto       = "application/proto"
+	contentTypeJSON        = "application/json"
+	contentTypeGRPCWeb     = "application/grpc-web"
+	contentTypeGRPCWebText = "application/grpc-web-text"
+
+	trailerFlag = 0x80
+)
+
+type method func(context.Context, func(proto.Message) error) (proto.Message, error)
+
+// Server serves unary RPCs over Function URLs using either the Connect
+// protocol (application/proto or application/json) or gRPC-Web.
+type Server struct {
+	methods map[string]method
+}
+
+func NewServer() *Server {
+	return &Server{methods: make(map[string]method)}
+}
+
+// Procedure returns the route for a method, e.g. "/pkg.Service/Method".
+func Procedure(service, name string) string {
+	return "/" + service + "/" + name
+}
+
+// Handle registers fn as the implementation of procedure.
+func Handle[Req, Res proto.Message](s *Server, procedure string, fn func(context.Context, Req) (Res, error)) {
+	s.methods[procedure] = func(ctx context.Context, unmarshal func(proto.Message) error) (proto.Message, error) {
+		var zero Req
+		in := zero.ProtoReflect().New().Interface().(Req)
+		if err := unmarshal(in); err != nil {
+			return nil, NewError(CodeInvalidArgument, err.Error())
+		}
+		out, err := fn(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+}
+
+// Mount adds a POST route on r for every procedure registered so far, so the
+// router's middleware runs in front of each call. It fails with
+// router.ErrFrozen once r is frozen.
+func (s *Server) Mount(r *router.Router) error {
+	for procedure := range s.methods {
+		if err := r.AddRoute(http.MethodPost, procedure, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) ServeHTTP(ctx context.Context, req events.LambdaFunctionURLRequest) router.Response {
+	contentType := strings.ToLower(req.Headers["content-type"])
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = strings.TrimSpace(contentType[:i])
+	}
+
+	rc, err := router.BodyReader(ctx, req)
+	if err != nil {
+		return connectError(NewError(CodeInvalidArgument, "invalid base64 body"))
+	}
+	body, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return connectError(NewError(CodeInternal, "reading body: "+err.Error()))
+	}
+
+	m, ok := s.methods[req.RequestContext.HTTP.Path]
+	switch {
+	case strings.HasPrefix(contentType, contentTypeGRPCWebText):
+		return s.serveGRPCWeb(ctx, m, ok, body, contentType, true)
+	case strings.HasPrefix(contentType, contentTypeGRPCWeb):
+		return s.serveGRPCWeb(ctx, m, ok, body, contentType, false)
+	case contentType == contentTypeProto, contentType == contentTypeJSON:
+		if !ok {
+			return connectError(NewError(CodeUnimplemented, "unknown procedure "+req.RequestContext.HTTP.Path))
+		}
+		return serveConnect(ctx, m, body, contentType)
+	default:
+		return router.Response{
+			StatusCode: http.StatusUnsupportedMediaType,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       map[string]string{"error": "Unsupported Media Type"},
+		}
+	}
+}
+
+func serveConnect(ctx context.Context, m method, body []byte, contentType string) router.Response {
+	unmarshal := func(msg proto.Message) error { return proto.Unmarshal(body, msg) }
+	marshal := proto.Marshal
+	if contentType == contentTypeJSON {
+		unmarshal = func(msg proto.Message) error {
+			if len(body) == 0 {
+				return nil
+			}
+			return protojson.Unmarshal(body, msg)
+		}
+		marshal = protojson.Marshal
+	}
+
+	out, err := m(ctx, unmarshal)
+	if err != nil {
+		return connectError(asError(err))
+	}
+	data, err := marshal(out)
+	if err != nil {
+		return connectError(NewError(CodeInternal, err.Error()))
+	}
+
+	resp := router.Response{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": contentType},
+	}
+	if contentType == contentTypeJSON {
+		resp.Body = string(data)
+	} else {
+		resp.Body = base64.StdEncoding.EncodeToString(data)
+		resp.IsBase64Encoded = true
+	}
+	return resp
+}
+
+func connectError(err *Error) router.Response {
+	return router.Response{
+		StatusCode: err.Code.HTTPStatus(),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       map[string]string{"code": err.Code.String(), "message": err.Message},
+	}
+}
+
+func (s *Server) serveGRPCWeb(ctx context.Context, m method, ok bool, body []byte, contentType string, text bool) router.Response {
+	var out bytes.Buffer
+	status := NewError(CodeOK, "")
+
+	if text {
+		decoded, err := base64.StdEncoding.DecodeString(string(body))
+		if err != nil {
+			status = NewError(CodeInvalidArgument, "invalid base64 body")
+		}
+		body = decoded
+	}
+
+	if status.Code == CodeOK && !ok {
+		status = NewError(CodeUnimplemented, "unknown procedure")
+	}
+	if status.Code == CodeOK {
+		payload, err := readFrame(body)
+		if err != nil {
+			status = NewError(CodeInvalidArgument, err.Error())
+		} else if msg, err := m(ctx, func(msg proto.Message) error { return proto.Unmarshal(payload, msg) }); err != nil {
+			status = asError(err)
+		} else if data, err := proto.Marshal(msg); err != nil {
+			status = NewError(CodeInternal, err.Error())
+		} else {
+			writeFrame(&out, 0, data)
+		}
+	}
+
+	trailer := fmt.Sprintf("grpc-status:%d\r\ngrpc-message:%s\r\n", status.Code, encodeGRPCMessage(status.Message))
+	writeFrame(&out, trailerFlag, []byte(trailer))
+
+	resp := router.Response{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": contentType},
+		Body:       base64.StdEncoding.EncodeToString(out.Bytes()),
+	}
+	// grpc-web-text bodies are base64 on the wire; binary ones are only
+	// base64 in the Lambda payload.
+	resp.IsBase64Encoded = !text
+	return resp
+}
+
+func readFrame(body []byte) ([]byte, error) {
+	if len(body) < 5 {
+		return nil, fmt.Errorf("rpc: short gRPC-Web frame")
+	}
+	if body[0]&0x01 != 0 {
+		return nil, fmt.Errorf("rpc: compressed gRPC-Web frames are not supported")
+	}
+	n := binary.BigEndian.Uint32(body[1:5])
+	if uint32(len(body)-5) < n {
+		return nil, fmt.Errorf("rpc: truncated gRPC-Web frame")
+	}
+	return body[5 : 5+n], nil
+}
+
+func writeFrame(buf *bytes.Buffer, flag byte, data []byte) {
+	var header [5]byte
+	header[0] = flag
+	binary.BigEndian.PutUint32(header[1:], uint32(len(data)))
+	buf.Write(header[:])
+	buf.Write(data)
+}
+
+// encodeGRPCMessage percent-encodes a status message as the gRPC spec
+// requires for the grpc-message trailer.
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= 0x20 && c <= 0x7e && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+const runtimeAPIVersion = "2018-06-01"
+
+var ErrNoRuntimeAPI = errors.New("router: AWS_LAMBDA_RUNTIME_API is not set")
+
+// RuntimeInvokeFunc handles one raw invocation payload.
+type RuntimeInvokeFunc func(ctx context.Context, payload []byte) ([]byte, error)
+
+// StartRuntimeAPI serves Function URL invocations by polling the Lambda
+// Runtime API directly, for provided.al2 custom runtimes and container
+// images that do not go through lambda.Start. It returns only when ctx is
+// done or the Runtime API becomes unreachable.
+func (r *Router) StartRuntimeAPI(ctx context.Context) error {
+	return ServeRuntimeAPI(ctx, os.Getenv("AWS_LAMBDA_RUNTIME_API"), func(ctx context.Context, payload []byte) ([]byte, error) {
+		var req events.LambdaFunctionURLRequest
+		if err := json.Unmarshal(payload, &req); err != nil {
+			return nil, err
+		}
+		resp := r.toLambdaResponse(r.HandleRequest(ctx, req))
+		out, err := json.Marshal(resp)
+		putHeaders(resp.Headers)
+		return out, err
+	})
+}
+
+// ServeRuntimeAPI runs the next-invocation loop against the Runtime API at
+// addr. A Dispatcher's Invoke method can be passed as fn.
+func ServeRuntimeAPI(ctx context.Context, addr string, fn RuntimeInvokeFunc) error {
+	if addr == "" {
+		return ErrNoRuntimeAPI
+	}
+	client := &runtimeClient{base: "http://" + addr + "/" + runtimeAPIVersion + "/runtime", http: &http.Client{}}
+	for {
+		inv, err := client.next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if err := client.invoke(ctx, inv, fn); err != nil {
+			return err
+		}
+	}
+}
+
+type runtimeClient struct {
+	base string
+	http *http.Client
+}
+
+type invocation struct {
+	requestID string
+	deadline  time.Time
+	lc        lambdacontext.LambdaContext
+	traceID   string
+	payload   []byte
+}
+
+func (c *runtimeClient) next(ctx context.Context) (*invocation, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+"/invocation/next", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("router: runtime API next invocation: %s", resp.Status)
+	}
+
+	inv := &invocation{
+		requestID: resp.Header.Get("Lambda-Runtime-Aws-Request-Id"),
+		traceID:   resp.Header.Get("Lambda-Runtime-Trace-Id"),
+		payload:   payload,
+	}
+	inv.lc.AwsRequestID = inv.requestID
+	inv.lc.InvokedFunctionArn = resp.Header.Get("Lambda-Runtime-Invoked-Function-Arn")
+	if ms, err := strconv.ParseInt(resp.Header.Get("Lambda-Runtime-Deadline-Ms"), 10, 64); err == nil {
+		inv.deadline = time.UnixMilli(ms)
+	}
+	if v := resp.Header.Get("Lambda-Runtime-Client-Context"); v != "" {
+		json.Unmarshal([]byte(v), &inv.lc.ClientContext)
+	}
+	if v := resp.Header.Get("Lambda-Runtime-Cognito-Identity"); v != "" {
+		json.Unmarshal([]byte(v), &inv.lc.Identity)
+	}
+	return inv, nil
+}
+
+func (c *runtimeClient) invoke(ctx context.Context, inv *invocation, fn RuntimeInvokeFunc) error {
+	invokeCtx := lambdacontext.NewContext(ctx, &inv.lc)
+	if !inv.deadline.IsZero() {
+		var cancel context.CancelFunc
+		invokeCtx, cancel = context.WithDeadline(invokeCtx, inv.deadline)
+		defer cancel()
+	}
+	if inv.traceID != "" {
+		os.Setenv("_X_AMZN_TRACE_ID", inv.traceID)
+	}
+
+	out, err := safeInvoke(invokeCtx, fn, inv.payload)
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{
+			"errorMessage": err.Error(),
+			"errorType":    fmt.Sprintf("%T", err),
+		})
+		return c.post(ctx, "/invocation/"+inv.requestID+"/error", body, "Unhandled")
+	}
+	return c.post(ctx, "/invocation/"+inv.requestID+"/response", out, "")
+}
+
+func safeInvoke(ctx context.Context, fn RuntimeInvokeFunc, payload []byte) (out []byte, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+	return fn(ctx, payload)
+}
+
+func (c *runtimeClient) post(ctx context.Context, path string, body []byte, errorType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if errorType != "" {
+		req.Header.Set("Lambda-Runtime-Function-Error-Type", errorType)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("router: runtime API %s: %s", path, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package s3store
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type Presigner struct {
+	client *s3.PresignClient
+	bucket string
+}
+
+func NewPresigner(client *s3.Client, bucket string) *Presigner {
+	return &Presigner{client: s3.NewPresignClient(client), bucket: bucket}
+}
+
+func (p *Presigner) Presign(ctx context.Context, method, key string, expiry time.Duration) (string, error) {
+	withExpiry := s3.WithPresignExpires(expiry)
+	switch method {
+	case http.MethodGet:
+		req, err := p.client.PresignGetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(p.bucket),
+			Key:    aws.String(key),
+		}, withExpiry)
+		if err != nil {
+			return "", err
+		}
+		return req.URL, nil
+	case http.MethodPut:
+		req, err := p.client.PresignPutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(p.bucket),
+			Key:    aws.String(key),
+		}, withExpiry)
+		if err != nil {
+			return "", err
+		}
+		return req.URL, nil
+	default:
+		return "", fmt.Errorf("s3store: unsupported presign method %q", method)
+	}
+}
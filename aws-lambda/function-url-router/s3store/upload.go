@@ -0,0 +1,28 @@
+package s3store
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type Uploader struct {
+	client *s3.Client
+	bucket string
+}
+
+func NewUploader(client *s3.Client, bucket string) *Uploader {
+	return &Uploader{client: client, bucket: bucket}
+}
+
+func (u *Uploader) Upload(ctx context.Context, key, contentType string, body []byte) error {
+	_, err := u.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(u.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		Body:        bytes.NewReader(body),
+	})
+	return err
+}
@@ -0,0 +1,96 @@
+package snsalert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	router "github.com/rthing31/go/aws-lambda/function-url-router"
+)
+
+// Publisher publishes alerts to an SNS topic, for email, SMS or a paging
+// integration subscribed to it.
+type Publisher struct {
+	client   *sns.Client
+	topicARN string
+}
+
+func New(client *sns.Client, topicARN string) *Publisher {
+	return &Publisher{client: client, topicARN: topicARN}
+}
+
+type message struct {
+	Kind       router.AlertKind `json:"kind"`
+	Time       time.Time        `json:"time"`
+	Function   string           `json:"function,omitempty"`
+	Method     string           `json:"method"`
+	Route      string           `json:"route"`
+	Path       string           `json:"path"`
+	Status     int              `json:"status"`
+	DurationMS float64          `json:"duration_ms"`
+	Count      int              `json:"count,omitempty"`
+	WindowS    float64          `json:"window_s,omitempty"`
+	RequestID  string           `json:"request_id,omitempty"`
+	TraceID    string           `json:"trace_id,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// Alert publishes alert as JSON, with a readable subject and a "kind"
+// message attribute so subscriptions can filter on it.
+func (p *Publisher) Alert(ctx context.Context, alert router.Alert) error {
+	body, err := json.Marshal(message{
+		Kind:       alert.Kind,
+		Time:       alert.Time,
+		Function:   lambdacontext.FunctionName,
+		Method:     alert.Method,
+		Route:      alert.Route,
+		Path:       alert.Path,
+		Status:     alert.Status,
+		DurationMS: float64(alert.Duration) / float64(time.Millisecond),
+		Count:      alert.Count,
+		WindowS:    alert.Window.Seconds(),
+		RequestID:  alert.RequestID,
+		TraceID:    alert.TraceID,
+		Error:      alert.Error,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = p.client.Publish(ctx, &sns.PublishInput{
+		TopicArn: aws.String(p.topicARN),
+		Subject:  aws.String(subject(alert)),
+		Message:  aws.String(string(body)),
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"kind": {DataType: aws.String("String"), StringValue: aws.String(string(alert.Kind))},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("snsalert: publish: %w", err)
+	}
+	return nil
+}
+
+// subject is limited to 100 characters by SNS.
+func subject(alert router.Alert) string {
+	var s string
+	switch alert.Kind {
+	case router.AlertSlowRequest:
+		s = fmt.Sprintf("Slow request: %s %s took %v", alert.Method, alert.Route, alert.Duration.Round(time.Millisecond))
+	case router.AlertServerErrors:
+		s = fmt.Sprintf("Server errors: %s %s returned %d 5xx in %v", alert.Method, alert.Route, alert.Count, alert.Window)
+	default:
+		s = fmt.Sprintf("Alert: %s %s %s", alert.Kind, alert.Method, alert.Route)
+	}
+	if fn := lambdacontext.FunctionName; fn != "" {
+		s = fn + ": " + s
+	}
+	if len(s) > 100 {
+		s = s[:97] + "..."
+	}
+	return s
+}
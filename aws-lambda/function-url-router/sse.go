@@ -0,0 +1,55 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SSEWriter writes Server-Sent Events. Each event is written in one call, so
+// the local server and RESPONSE_STREAM functions flush it immediately.
+type SSEWriter struct {
+	w io.Writer
+}
+
+// Send writes an event; an empty event name uses the default "message"
+// type. Multi-line data is split into several data fields.
+func (s *SSEWriter) Send(event, data string) error {
+	var b strings.Builder
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(s.w, b.String())
+	return err
+}
+
+func (s *SSEWriter) SendJSON(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Send(event, string(data))
+}
+
+// Comment writes a comment line, which clients ignore; use it as a
+// keep-alive on quiet streams.
+func (s *SSEWriter) Comment(text string) error {
+	_, err := fmt.Fprintf(s.w, ": %s\n\n", text)
+	return err
+}
+
+// SSE streams the events fn sends as text/event-stream. Deployed functions
+// need the RESPONSE_STREAM invoke mode and StreamingLambdaHandler.
+func SSE(fn func(*SSEWriter) error) Response {
+	resp := Stream(http.StatusOK, "text/event-stream", func(w io.Writer) error {
+		return fn(&SSEWriter{w: w})
+	})
+	resp.Headers["Cache-Control"] = "no-cache"
+	return resp
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// IsLambda reports whether the process was started by a Lambda runtime. It is
+// also true under sam local and the Runtime Interface Emulator, which set the
+// same Runtime API address.
+func IsLambda() bool {
+	return os.Getenv("AWS_LAMBDA_RUNTIME_API") != ""
+}
+
+// Start is a main entrypoint helper. Under a Lambda runtime it serves r for
+// every HTTP event shape, so the same binary answers Function URL invokes,
+// sam local start-api (API Gateway v1 or v2 events) and hand-crafted events
+// posted to the Runtime Interface Emulator. Otherwise it runs the local
+// server on $PORT, defaulting to 8080.
+func Start(r *Router) {
+	if IsLambda() {
+		d := NewDispatcher()
+		d.HandleHTTP(r)
+		lambda.Start(d)
+		return
+	}
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	if err := RunLocalServer(r, ":"+port, r.logger); err != nil {
+		r.logger.Fatalf("Server error: %v", err)
+	}
+}
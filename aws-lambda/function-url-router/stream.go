@@ -0,0 +1,122 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type streamBody struct {
+	fn   func(io.Writer) error
+	once sync.Once
+	pr   *io.PipeReader
+}
+
+// Stream returns a Response whose body is produced by fn as it is read.
+// fn starts on the first read, so a stream that is never sent never runs.
+func Stream(status int, contentType string, fn func(w io.Writer) error) Response {
+	return Response{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": contentType},
+		Body:       &streamBody{fn: fn},
+	}
+}
+
+func (s *streamBody) start() {
+	s.once.Do(func() {
+		pr, pw := io.Pipe()
+		s.pr = pr
+		go func() {
+			pw.CloseWithError(s.fn(pw))
+		}()
+	})
+}
+
+func (s *streamBody) Read(p []byte) (int, error) {
+	s.start()
+	return s.pr.Read(p)
+}
+
+func (s *streamBody) Close() error {
+	s.start()
+	return s.pr.Close()
+}
+
+// StreamingLambdaHandler returns a handler for lambda.Start or
+// lambda.StartWithOptions on functions whose URL uses the RESPONSE_STREAM
+// invoke mode. io.Reader bodies, including those from Stream, are sent as
+// they are produced; any other body is encoded and sent in one piece.
+func (r *Router) StreamingLambdaHandler() func(context.Context, events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+	return func(ctx context.Context, req events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+		return r.toStreamingResponse(r.HandleRequest(ctx, req)), nil
+	}
+}
+
+func (r *Router) toStreamingResponse(resp Response) *events.LambdaFunctionURLStreamingResponse {
+	resp = stripBodyIfNotAllowed(resp)
+	headers, cookies := lambdaHeaders(resp)
+	out := &events.LambdaFunctionURLStreamingResponse{
+		StatusCode: resp.StatusCode,
+		Headers:    headers,
+		Cookies:    cookies,
+	}
+
+	if stream, ok := resp.Body.(io.Reader); ok {
+		out.Body = stream
+		return out
+	}
+
+	body, err := encodeBody(r.outputCodec(), resp.Body)
+	if err == nil && resp.IsBase64Encoded {
+		body, err = base64.StdEncoding.DecodeString(string(body))
+	}
+	if err != nil {
+		r.logError(context.Background(), "Error encoding response body", err)
+		return &events.LambdaFunctionURLStreamingResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			Body:       bytes.NewReader([]byte(`{"error":"Internal Server Error"}`)),
+		}
+	}
+	if _, ok := resp.Body.(string); !ok && len(body) > 0 && len(resp.HeaderValues("Content-Type")) == 0 {
+		out.Headers["Content-Type"] = "application/json"
+	}
+	out.Body = bytes.NewReader(body)
+	return out
+}
+
+// copyFlush copies a streamed body to w, flushing after every write so the
+// local server sends it with chunked transfer encoding as it is produced.
+func copyFlush(w http.ResponseWriter, body io.Reader) error {
+	defer closeBody(body)
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func closeBody(body io.Reader) {
+	if c, ok := body.(io.Closer); ok {
+		c.Close()
+	}
+}
@@ -0,0 +1,334 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultName         = "function-url-router-telemetry"
+	defaultPort         = 4243
+	defaultFlushTimeout = 2 * time.Second
+	extensionAPI        = "2020-01-01"
+	telemetryAPI        = "2022-07-01"
+	schemaVersion       = "2022-12-13"
+)
+
+var ErrNotInLambda = errors.New("telemetry: AWS_LAMBDA_RUNTIME_API is not set")
+
+type Event struct {
+	Time   time.Time       `json:"time"`
+	Type   string          `json:"type"`
+	Record json.RawMessage `json:"record"`
+}
+
+type Flusher interface {
+	Flush(context.Context) error
+}
+
+type FlusherFunc func(context.Context) error
+
+func (f FlusherFunc) Flush(ctx context.Context) error {
+	return f(ctx)
+}
+
+type Buffering struct {
+	MaxItems  int `json:"maxItems"`
+	MaxBytes  int `json:"maxBytes"`
+	TimeoutMs int `json:"timeoutMs"`
+}
+
+type Config struct {
+	Name         string
+	Types        []string
+	Port         int
+	Buffering    Buffering
+	OnEvent      func(Event)
+	Flushers     []Flusher
+	FlushTimeout time.Duration
+	Logger       *log.Logger
+}
+
+type Extension struct {
+	cfg        Config
+	runtimeAPI string
+	id         string
+	client     *http.Client
+	server     *http.Server
+	done       chan string
+	stopped    chan struct{}
+	mu         sync.Mutex
+	flushers   []Flusher
+	logger     *log.Logger
+}
+
+type nextEvent struct {
+	EventType  string `json:"eventType"`
+	DeadlineMs int64  `json:"deadlineMs"`
+	RequestID  string `json:"requestId"`
+}
+
+// Start registers an internal extension, subscribes it to the Telemetry API
+// and flushes every registered Flusher once the runtime finishes each invoke
+// and again on SIGTERM. It doesn't exit the process afterwards: Lambda ends
+// the sandbox once the shutdown phase is over, and a caller that wants to
+// exit sooner can wait on Done. It must be called before lambda.Start.
+// Outside Lambda it returns ErrNotInLambda.
+func Start(ctx context.Context, cfg Config) (*Extension, error) {
+	runtimeAPI := os.Getenv("AWS_LAMBDA_RUNTIME_API")
+	if runtimeAPI == "" {
+		return nil, ErrNotInLambda
+	}
+	if cfg.Name == "" {
+		cfg.Name = defaultName
+	}
+	if len(cfg.Types) == 0 {
+		cfg.Types = []string{"platform", "function"}
+	}
+	if cfg.Port == 0 {
+		cfg.Port = defaultPort
+	}
+	if cfg.Buffering == (Buffering{}) {
+		cfg.Buffering = Buffering{MaxItems: 1000, MaxBytes: 256 * 1024, TimeoutMs: 100}
+	}
+	if cfg.FlushTimeout == 0 {
+		cfg.FlushTimeout = defaultFlushTimeout
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = log.New(os.Stdout, "TELEMETRY: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+
+	e := &Extension{
+		cfg:        cfg,
+		runtimeAPI: runtimeAPI,
+		client:     &http.Client{},
+		done:       make(chan string, 16),
+		stopped:    make(chan struct{}),
+		flushers:   append([]Flusher(nil), cfg.Flushers...),
+		logger:     cfg.Logger,
+	}
+	if err := e.register(ctx); err != nil {
+		return nil, err
+	}
+	if err := e.listen(); err != nil {
+		return nil, err
+	}
+	if err := e.subscribe(ctx); err != nil {
+		e.server.Close()
+		return nil, err
+	}
+
+	go e.run(ctx)
+	go e.handleSignals()
+	return e, nil
+}
+
+// Done is closed once the extension has flushed after SIGTERM or SIGINT.
+func (e *Extension) Done() <-chan struct{} {
+	return e.stopped
+}
+
+func (e *Extension) AddFlusher(f Flusher) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.flushers = append(e.flushers, f)
+}
+
+func (e *Extension) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	flushers := append([]Flusher(nil), e.flushers...)
+	e.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.FlushTimeout)
+	defer cancel()
+	var errs []error
+	for _, f := range flushers {
+		if err := f.Flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *Extension) Shutdown(ctx context.Context) error {
+	err := e.Flush(ctx)
+	return errors.Join(err, e.server.Shutdown(ctx))
+}
+
+func (e *Extension) register(ctx context.Context) error {
+	body, _ := json.Marshal(map[string][]string{"events": {"INVOKE"}})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url(extensionAPI, "extension/register"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Lambda-Extension-Name", e.cfg.Name)
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telemetry: register extension: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telemetry: register extension: %s", readError(resp))
+	}
+	e.id = resp.Header.Get("Lambda-Extension-Identifier")
+	return nil
+}
+
+func (e *Extension) subscribe(ctx context.Context) error {
+	body, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": schemaVersion,
+		"types":         e.cfg.Types,
+		"buffering":     e.cfg.Buffering,
+		"destination": map[string]string{
+			"protocol": "HTTP",
+			"URI":      "http://sandbox.localdomain:" + strconv.Itoa(e.cfg.Port),
+		},
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, e.url(telemetryAPI, "telemetry"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Lambda-Extension-Identifier", e.id)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telemetry: subscribe: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telemetry: subscribe: %s", readError(resp))
+	}
+	return nil
+}
+
+func (e *Extension) listen() error {
+	ln, err := net.Listen("tcp", "0.0.0.0:"+strconv.Itoa(e.cfg.Port))
+	if err != nil {
+		return fmt.Errorf("telemetry: listen: %w", err)
+	}
+	e.server = &http.Server{Handler: http.HandlerFunc(e.receive)}
+	go func() {
+		if err := e.server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			e.logger.Printf("Telemetry listener stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (e *Extension) receive(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	var batch []Event
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		e.logger.Printf("Error decoding telemetry batch: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for _, ev := range batch {
+		if e.cfg.OnEvent != nil {
+			e.cfg.OnEvent(ev)
+		}
+		if ev.Type != "platform.runtimeDone" {
+			continue
+		}
+		var record struct {
+			RequestID string `json:"requestId"`
+		}
+		if err := json.Unmarshal(ev.Record, &record); err == nil {
+			select {
+			case e.done <- record.RequestID:
+			default:
+			}
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (e *Extension) run(ctx context.Context) {
+	for {
+		ev, err := e.next(ctx)
+		if err != nil {
+			e.logger.Printf("Error waiting for next event: %v", err)
+			return
+		}
+		if ev.EventType != "INVOKE" {
+			continue
+		}
+		e.waitForRuntimeDone(ctx, ev)
+		if err := e.Flush(ctx); err != nil {
+			e.logger.Printf("Error flushing telemetry: %v", err)
+		}
+	}
+}
+
+// waitForRuntimeDone blocks until the Telemetry API reports that the runtime
+// has finished the given invoke, or until its deadline passes.
+func (e *Extension) waitForRuntimeDone(ctx context.Context, ev nextEvent) {
+	timer := time.NewTimer(time.Until(time.UnixMilli(ev.DeadlineMs)))
+	defer timer.Stop()
+	for {
+		select {
+		case id := <-e.done:
+			if id == ev.RequestID {
+				return
+			}
+		case <-timer.C:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *Extension) next(ctx context.Context) (nextEvent, error) {
+	var ev nextEvent
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.url(extensionAPI, "extension/event/next"), nil)
+	if err != nil {
+		return ev, err
+	}
+	req.Header.Set("Lambda-Extension-Identifier", e.id)
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return ev, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ev, errors.New(readError(resp))
+	}
+	err = json.NewDecoder(resp.Body).Decode(&ev)
+	return ev, err
+}
+
+func (e *Extension) handleSignals() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGTERM, syscall.SIGINT)
+	<-sigs
+	signal.Stop(sigs)
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.FlushTimeout)
+	if err := e.Shutdown(ctx); err != nil {
+		e.logger.Printf("Error flushing telemetry on shutdown: %v", err)
+	}
+	cancel()
+	close(e.stopped)
+}
+
+func (e *Extension) url(version, path string) string {
+	return "http://" + e.runtimeAPI + "/" + version + "/" + path
+}
+
+func readError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Sprintf("%s: %s", resp.Status, bytes.TrimSpace(body))
+}
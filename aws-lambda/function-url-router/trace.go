@@ -0,0 +1,96 @@
+package router
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// TraceContext is the X-Ray trace a request belongs to.
+type TraceContext struct {
+	// TraceID is the Root field, e.g. 1-5759e988-bd862e3fe1be46a994272793.
+	TraceID  string
+	ParentID string
+	// Sampled is "1", "0", "?" or empty when unknown.
+	Sampled string
+}
+
+// Header formats t as an X-Amzn-Trace-Id header value, for passing the
+// trace on to downstream calls.
+func (t TraceContext) Header() string {
+	header := "Root=" + t.TraceID
+	if t.ParentID != "" {
+		header += ";Parent=" + t.ParentID
+	}
+	if t.Sampled != "" {
+		header += ";Sampled=" + t.Sampled
+	}
+	return header
+}
+
+// Trace returns the trace of the request handled with ctx. In Lambda it
+// comes from the runtime; elsewhere from the X-Amzn-Trace-Id or W3C
+// traceparent request header.
+func Trace(ctx context.Context) (TraceContext, bool) {
+	c := requestFromContext(ctx)
+	if c == nil || !c.traced {
+		return TraceContext{}, false
+	}
+	return c.trace, true
+}
+
+// ParseTraceHeader parses an X-Amzn-Trace-Id header value.
+func ParseTraceHeader(header string) (TraceContext, bool) {
+	var t TraceContext
+	for _, field := range strings.Split(header, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "Root":
+			t.TraceID = value
+		case "Parent":
+			t.ParentID = value
+		case "Sampled":
+			t.Sampled = value
+		}
+	}
+	return t, t.TraceID != ""
+}
+
+// parseTraceparent converts a W3C traceparent header, which X-Ray accepts
+// for OpenTelemetry clients, to X-Ray's format.
+func parseTraceparent(header string) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return TraceContext{}, false
+	}
+	sampled := "0"
+	if parts[3][1]&1 == 1 {
+		sampled = "1"
+	}
+	return TraceContext{
+		TraceID:  "1-" + parts[1][:8] + "-" + parts[1][8:],
+		ParentID: parts[2],
+		Sampled:  sampled,
+	}, true
+}
+
+func requestTrace(ctx context.Context, req events.LambdaFunctionURLRequest) (TraceContext, bool) {
+	// aws-lambda-go stores the runtime's trace header under this string key.
+	if header, ok := ctx.Value("x-amzn-trace-id").(string); ok && header != "" {
+		return ParseTraceHeader(header)
+	}
+	if IsLambda() {
+		if header := os.Getenv("_X_AMZN_TRACE_ID"); header != "" {
+			return ParseTraceHeader(header)
+		}
+	}
+	if header := req.Headers["x-amzn-trace-id"]; header != "" {
+		return ParseTraceHeader(header)
+	}
+	if header := req.Headers["traceparent"]; header != "" {
+		return parseTraceparent(header)
+	}
+	return TraceContext{}, false
+}
@@ -0,0 +1,161 @@
+package iotrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoRoute = errors.New("iotrouter: no route for topic")
+	ErrNoTopic = errors.New("iotrouter: event has no topic field")
+)
+
+// Message is one IoT rule invocation. The rule must copy the MQTT topic into
+// the payload, e.g. SELECT *, topic() AS topic FROM 'devices/+/telemetry'.
+type Message struct {
+	Topic string
+	// Wildcards holds the topic levels matched by + and, last, the remainder
+	// matched by #.
+	Wildcards []string
+	Payload   json.RawMessage
+}
+
+// Bind decodes the JSON payload into v.
+func (m Message) Bind(v interface{}) error {
+	return json.Unmarshal(m.Payload, v)
+}
+
+type Handler interface {
+	HandleMessage(context.Context, Message) error
+}
+
+type HandlerFunc func(context.Context, Message) error
+
+func (f HandlerFunc) HandleMessage(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+type MiddlewareFunc func(Handler) Handler
+
+type route struct {
+	filter  []string
+	handler Handler
+}
+
+type Router struct {
+	routes         []route
+	topicField     string
+	defaultHandler Handler
+	middleware     []MiddlewareFunc
+	logger         *log.Logger
+}
+
+func NewRouter(logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.New(os.Stdout, "IOTROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	return &Router{topicField: "topic", logger: logger}
+}
+
+// On routes messages whose topic matches an MQTT topic filter, where + matches
+// one level and a trailing # matches any number of levels. Routes are tried
+// in registration order.
+func (r *Router) On(filter string, handler Handler) {
+	r.routes = append(r.routes, route{filter: strings.Split(filter, "/"), handler: handler})
+}
+
+// SetTopicField changes the payload field the topic is read from.
+func (r *Router) SetTopicField(name string) {
+	r.topicField = name
+}
+
+func (r *Router) Default(handler Handler) {
+	r.defaultHandler = handler
+}
+
+func (r *Router) Use(mw MiddlewareFunc) {
+	r.middleware = append(r.middleware, mw)
+}
+
+func (r *Router) LambdaHandler() func(context.Context, json.RawMessage) error {
+	return r.HandleEvent
+}
+
+func (r *Router) HandleEvent(ctx context.Context, payload json.RawMessage) (err error) {
+	startTime := time.Now()
+	var msg Message
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+		r.logMessageCompletion(msg, time.Since(startTime), err)
+	}()
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return fmt.Errorf("iotrouter: decode payload: %w", err)
+	}
+	if err := json.Unmarshal(fields[r.topicField], &msg.Topic); err != nil || msg.Topic == "" {
+		return ErrNoTopic
+	}
+	msg.Payload = payload
+
+	handler := r.defaultHandler
+	for _, rt := range r.routes {
+		if wildcards, ok := Match(rt.filter, msg.Topic); ok {
+			handler = rt.handler
+			msg.Wildcards = wildcards
+			break
+		}
+	}
+	if handler == nil {
+		return ErrNoRoute
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	return handler.HandleMessage(ctx, msg)
+}
+
+// Match reports whether topic matches the split topic filter and returns the
+// levels captured by its wildcards.
+func Match(filter []string, topic string) ([]string, bool) {
+	levels := strings.Split(topic, "/")
+	var wildcards []string
+	for i, f := range filter {
+		switch {
+		case f == "#":
+			return append(wildcards, strings.Join(levels[i:], "/")), true
+		case i >= len(levels):
+			return nil, false
+		case f == "+":
+			wildcards = append(wildcards, levels[i])
+		case f != levels[i]:
+			return nil, false
+		}
+	}
+	if len(filter) != len(levels) {
+		return nil, false
+	}
+	return wildcards, true
+}
+
+func (r *Router) logMessageCompletion(msg Message, duration time.Duration, err error) {
+	logEntry := fmt.Sprintf(
+		"Message completed: topic=%s duration=%v",
+		msg.Topic,
+		duration,
+	)
+
+	if err != nil {
+		logEntry += fmt.Sprintf(" error=%v", err)
+	}
+
+	r.logger.Println(logEntry)
+}
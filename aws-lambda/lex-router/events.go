@@ -0,0 +1,135 @@
+package lexrouter
+
+// Event is a Lex V2 code hook invocation. aws-lambda-go only models Lex V1.
+type Event struct {
+	MessageVersion      string                 `json:"messageVersion"`
+	InvocationSource    string                 `json:"invocationSource"`
+	InputMode           string                 `json:"inputMode"`
+	ResponseContentType string                 `json:"responseContentType"`
+	SessionID           string                 `json:"sessionId"`
+	InputTranscript     string                 `json:"inputTranscript"`
+	Bot                 Bot                    `json:"bot"`
+	Interpretations     []Interpretation       `json:"interpretations"`
+	ProposedNextState   map[string]interface{} `json:"proposedNextState,omitempty"`
+	RequestAttributes   map[string]string      `json:"requestAttributes,omitempty"`
+	SessionState        SessionState           `json:"sessionState"`
+}
+
+type Bot struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	AliasID   string `json:"aliasId"`
+	AliasName string `json:"aliasName"`
+	LocaleID  string `json:"localeId"`
+	Version   string `json:"version"`
+}
+
+type Interpretation struct {
+	Intent        Intent `json:"intent"`
+	NLUConfidence *struct {
+		Score float64 `json:"score"`
+	} `json:"nluConfidence,omitempty"`
+}
+
+type SessionState struct {
+	ActiveContexts       []map[string]interface{} `json:"activeContexts,omitempty"`
+	SessionAttributes    map[string]string        `json:"sessionAttributes,omitempty"`
+	RuntimeHints         map[string]interface{}   `json:"runtimeHints,omitempty"`
+	DialogAction         *DialogAction            `json:"dialogAction,omitempty"`
+	Intent               *Intent                  `json:"intent,omitempty"`
+	OriginatingRequestID string                   `json:"originatingRequestId,omitempty"`
+}
+
+type DialogAction struct {
+	Type         string `json:"type"`
+	SlotToElicit string `json:"slotToElicit,omitempty"`
+}
+
+type Intent struct {
+	Name              string           `json:"name"`
+	Slots             map[string]*Slot `json:"slots,omitempty"`
+	State             string           `json:"state,omitempty"`
+	ConfirmationState string           `json:"confirmationState,omitempty"`
+}
+
+type Slot struct {
+	Shape  string     `json:"shape,omitempty"`
+	Value  *SlotValue `json:"value,omitempty"`
+	Values []*Slot    `json:"values,omitempty"`
+}
+
+type SlotValue struct {
+	OriginalValue    string   `json:"originalValue"`
+	InterpretedValue string   `json:"interpretedValue"`
+	ResolvedValues   []string `json:"resolvedValues,omitempty"`
+}
+
+type Response struct {
+	SessionState      SessionState      `json:"sessionState"`
+	Messages          []Message         `json:"messages,omitempty"`
+	RequestAttributes map[string]string `json:"requestAttributes,omitempty"`
+}
+
+type Message struct {
+	ContentType       string             `json:"contentType"`
+	Content           string             `json:"content,omitempty"`
+	ImageResponseCard *ImageResponseCard `json:"imageResponseCard,omitempty"`
+}
+
+type ImageResponseCard struct {
+	Title    string   `json:"title"`
+	Subtitle string   `json:"subtitle,omitempty"`
+	ImageURL string   `json:"imageUrl,omitempty"`
+	Buttons  []Button `json:"buttons,omitempty"`
+}
+
+type Button struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// IntentName returns the name of the intent in the session state.
+func (e *Event) IntentName() string {
+	if e.SessionState.Intent == nil {
+		return ""
+	}
+	return e.SessionState.Intent.Name
+}
+
+// Slot returns the interpreted value of a scalar slot, or false when the
+// slot is not filled.
+func (e *Event) Slot(name string) (string, bool) {
+	if e.SessionState.Intent == nil {
+		return "", false
+	}
+	slot := e.SessionState.Intent.Slots[name]
+	if slot == nil || slot.Value == nil {
+		return "", false
+	}
+	return slot.Value.InterpretedValue, true
+}
+
+// SlotValues returns the interpreted values of a list slot. A filled scalar
+// slot is returned as a single value.
+func (e *Event) SlotValues(name string) []string {
+	if e.SessionState.Intent == nil {
+		return nil
+	}
+	slot := e.SessionState.Intent.Slots[name]
+	if slot == nil {
+		return nil
+	}
+	if len(slot.Values) == 0 {
+		if slot.Value != nil {
+			return []string{slot.Value.InterpretedValue}
+		}
+		return nil
+	}
+	values := make([]string, 0, len(slot.Values))
+	for _, v := range slot.Values {
+		if v != nil && v.Value != nil {
+			values = append(values, v.Value.InterpretedValue)
+		}
+	}
+	return values
+}
@@ -0,0 +1,70 @@
+package lexrouter
+
+const (
+	IntentStateFulfilled  = "Fulfilled"
+	IntentStateFailed     = "Failed"
+	IntentStateInProgress = "InProgress"
+)
+
+func PlainText(content string) Message {
+	return Message{ContentType: "PlainText", Content: content}
+}
+
+func SSML(content string) Message {
+	return Message{ContentType: "SSML", Content: content}
+}
+
+func Card(title, subtitle, imageURL string, buttons ...Button) Message {
+	return Message{
+		ContentType: "ImageResponseCard",
+		ImageResponseCard: &ImageResponseCard{
+			Title:    title,
+			Subtitle: subtitle,
+			ImageURL: imageURL,
+			Buttons:  buttons,
+		},
+	}
+}
+
+// Close ends the conversation for the current intent with the given state.
+func Close(e *Event, state string, messages ...Message) *Response {
+	return respond(e, DialogAction{Type: "Close"}, state, messages)
+}
+
+// Delegate lets Lex choose the next step.
+func Delegate(e *Event) *Response {
+	return respond(e, DialogAction{Type: "Delegate"}, "", nil)
+}
+
+func ElicitSlot(e *Event, slot string, messages ...Message) *Response {
+	return respond(e, DialogAction{Type: "ElicitSlot", SlotToElicit: slot}, "", messages)
+}
+
+func ConfirmIntent(e *Event, messages ...Message) *Response {
+	return respond(e, DialogAction{Type: "ConfirmIntent"}, "", messages)
+}
+
+func ElicitIntent(e *Event, messages ...Message) *Response {
+	resp := respond(e, DialogAction{Type: "ElicitIntent"}, "", messages)
+	resp.SessionState.Intent = nil
+	return resp
+}
+
+func respond(e *Event, action DialogAction, state string, messages []Message) *Response {
+	resp := &Response{
+		SessionState: SessionState{
+			ActiveContexts:    e.SessionState.ActiveContexts,
+			SessionAttributes: e.SessionState.SessionAttributes,
+			DialogAction:      &action,
+		},
+		Messages: messages,
+	}
+	if e.SessionState.Intent != nil {
+		intent := *e.SessionState.Intent
+		if state != "" {
+			intent.State = state
+		}
+		resp.SessionState.Intent = &intent
+	}
+	return resp
+}
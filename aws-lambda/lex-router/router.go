@@ -0,0 +1,110 @@
+package lexrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+var ErrNoRoute = errors.New("lexrouter: no route for intent")
+
+type Handler interface {
+	HandleIntent(context.Context, *Event) (*Response, error)
+}
+
+type HandlerFunc func(context.Context, *Event) (*Response, error)
+
+func (f HandlerFunc) HandleIntent(ctx context.Context, e *Event) (*Response, error) {
+	return f(ctx, e)
+}
+
+type MiddlewareFunc func(Handler) Handler
+
+type Router struct {
+	intents        map[string]Handler
+	defaultHandler Handler
+	middleware     []MiddlewareFunc
+	logger         *log.Logger
+}
+
+func NewRouter(logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.New(os.Stdout, "LEXROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	return &Router{intents: make(map[string]Handler), logger: logger}
+}
+
+// Intent routes an intent of any bot.
+func (r *Router) Intent(intent string, handler Handler) {
+	r.intents[intentKey("", intent)] = handler
+}
+
+// BotIntent routes an intent of one bot, taking precedence over Intent.
+func (r *Router) BotIntent(bot, intent string, handler Handler) {
+	r.intents[intentKey(bot, intent)] = handler
+}
+
+func (r *Router) Default(handler Handler) {
+	r.defaultHandler = handler
+}
+
+func (r *Router) Use(mw MiddlewareFunc) {
+	r.middleware = append(r.middleware, mw)
+}
+
+func (r *Router) LambdaHandler() func(context.Context, *Event) (*Response, error) {
+	return r.HandleEvent
+}
+
+func (r *Router) HandleEvent(ctx context.Context, e *Event) (resp *Response, err error) {
+	startTime := time.Now()
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+		r.logIntentCompletion(e, time.Since(startTime), err)
+	}()
+
+	handler := r.match(e)
+	if handler == nil {
+		return nil, ErrNoRoute
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	return handler.HandleIntent(ctx, e)
+}
+
+func (r *Router) match(e *Event) Handler {
+	intent := e.IntentName()
+	if h, ok := r.intents[intentKey(e.Bot.Name, intent)]; ok {
+		return h
+	}
+	if h, ok := r.intents[intentKey("", intent)]; ok {
+		return h
+	}
+	return r.defaultHandler
+}
+
+func intentKey(bot, intent string) string {
+	return bot + "/" + intent
+}
+
+func (r *Router) logIntentCompletion(e *Event, duration time.Duration, err error) {
+	logEntry := fmt.Sprintf(
+		"Intent completed: bot=%s intent=%s source=%s duration=%v",
+		e.Bot.Name,
+		e.IntentName(),
+		e.InvocationSource,
+		duration,
+	)
+
+	if err != nil {
+		logEntry += fmt.Sprintf(" error=%v", err)
+	}
+
+	r.logger.Println(logEntry)
+}
@@ -0,0 +1,140 @@
+package logsrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var ErrNoRoute = errors.New("logsrouter: no route for log group")
+
+type Processor interface {
+	ProcessLogs(context.Context, events.CloudwatchLogsData) error
+}
+
+type ProcessorFunc func(context.Context, events.CloudwatchLogsData) error
+
+func (f ProcessorFunc) ProcessLogs(ctx context.Context, data events.CloudwatchLogsData) error {
+	return f(ctx, data)
+}
+
+type MiddlewareFunc func(Processor) Processor
+
+type route struct {
+	pattern   string
+	processor Processor
+}
+
+type Router struct {
+	routes           []route
+	defaultProcessor Processor
+	middleware       []MiddlewareFunc
+	logger           *log.Logger
+}
+
+func NewRouter(logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.New(os.Stdout, "LOGSROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	return &Router{logger: logger}
+}
+
+// LogGroup routes batches from log groups matching pattern, where * matches
+// any run of characters including "/", e.g. "/aws/lambda/orders-*". Routes
+// are tried in registration order.
+func (r *Router) LogGroup(pattern string, processor Processor) {
+	r.routes = append(r.routes, route{pattern: pattern, processor: processor})
+}
+
+func (r *Router) Default(processor Processor) {
+	r.defaultProcessor = processor
+}
+
+func (r *Router) Use(mw MiddlewareFunc) {
+	r.middleware = append(r.middleware, mw)
+}
+
+func (r *Router) LambdaHandler() func(context.Context, events.CloudwatchLogsEvent) error {
+	return r.HandleEvent
+}
+
+// HandleEvent decodes the gzipped subscription payload and hands the batch to
+// the matching processor. Control messages CloudWatch Logs sends to check
+// the destination are acknowledged without routing.
+func (r *Router) HandleEvent(ctx context.Context, event events.CloudwatchLogsEvent) (err error) {
+	startTime := time.Now()
+	var data events.CloudwatchLogsData
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+		r.logBatchCompletion(data, time.Since(startTime), err)
+	}()
+
+	data, err = event.AWSLogs.Parse()
+	if err != nil {
+		return fmt.Errorf("logsrouter: decode payload: %w", err)
+	}
+	if data.MessageType == "CONTROL_MESSAGE" {
+		return nil
+	}
+
+	processor := r.match(data.LogGroup)
+	if processor == nil {
+		return ErrNoRoute
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		processor = r.middleware[i](processor)
+	}
+	return processor.ProcessLogs(ctx, data)
+}
+
+func (r *Router) match(logGroup string) Processor {
+	for _, rt := range r.routes {
+		if matchGlob(rt.pattern, logGroup) {
+			return rt.processor
+		}
+	}
+	return r.defaultProcessor
+}
+
+func matchGlob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+func (r *Router) logBatchCompletion(data events.CloudwatchLogsData, duration time.Duration, err error) {
+	logEntry := fmt.Sprintf(
+		"Batch completed: group=%s stream=%s events=%d duration=%v",
+		data.LogGroup,
+		data.LogStream,
+		len(data.LogEvents),
+		duration,
+	)
+
+	if err != nil {
+		logEntry += fmt.Sprintf(" error=%v", err)
+	}
+
+	r.logger.Println(logEntry)
+}
@@ -0,0 +1,229 @@
+package s3router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var ErrNoRoute = errors.New("s3router: no route for record")
+
+type Handler interface {
+	HandleRecord(context.Context, events.S3EventRecord) error
+}
+
+type HandlerFunc func(context.Context, events.S3EventRecord) error
+
+func (f HandlerFunc) HandleRecord(ctx context.Context, record events.S3EventRecord) error {
+	return f(ctx, record)
+}
+
+// BatchHandler receives every record of an invocation matched by its route
+// at once, split into chunks of at most the router's batch size.
+type BatchHandler interface {
+	HandleRecords(context.Context, []events.S3EventRecord) error
+}
+
+type BatchHandlerFunc func(context.Context, []events.S3EventRecord) error
+
+func (f BatchHandlerFunc) HandleRecords(ctx context.Context, records []events.S3EventRecord) error {
+	return f(ctx, records)
+}
+
+type MiddlewareFunc func(Handler) Handler
+
+// Match selects records. Empty fields match anything; EventName matches by
+// prefix, so "ObjectCreated:" covers every creation event.
+type Match struct {
+	Bucket    string
+	Prefix    string
+	Suffix    string
+	EventName string
+}
+
+func (m Match) matches(record events.S3EventRecord) bool {
+	key := Key(record)
+	return (m.Bucket == "" || record.S3.Bucket.Name == m.Bucket) &&
+		strings.HasPrefix(key, m.Prefix) &&
+		strings.HasSuffix(key, m.Suffix) &&
+		strings.HasPrefix(record.EventName, m.EventName)
+}
+
+type route struct {
+	match   Match
+	handler Handler
+	batch   BatchHandler
+}
+
+type Router struct {
+	routes      []route
+	middleware  []MiddlewareFunc
+	concurrency int
+	batchSize   int
+	logger      *log.Logger
+}
+
+func NewRouter(logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.New(os.Stdout, "S3ROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	return &Router{concurrency: 1, logger: logger}
+}
+
+// Handle routes matching records to handler one at a time. Routes are tried
+// in registration order and the first match wins.
+func (r *Router) Handle(match Match, handler Handler) {
+	r.routes = append(r.routes, route{match: match, handler: handler})
+}
+
+func (r *Router) HandleBatch(match Match, handler BatchHandler) {
+	r.routes = append(r.routes, route{match: match, batch: handler})
+}
+
+func (r *Router) Use(mw MiddlewareFunc) {
+	r.middleware = append(r.middleware, mw)
+}
+
+func (r *Router) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	r.concurrency = n
+}
+
+// SetBatchSize caps the number of records passed to a BatchHandler per call.
+// Zero, the default, passes all matching records in one call.
+func (r *Router) SetBatchSize(n int) {
+	r.batchSize = n
+}
+
+func (r *Router) LambdaHandler() func(context.Context, events.S3Event) error {
+	return r.HandleEvent
+}
+
+func (r *Router) HandleEvent(ctx context.Context, event events.S3Event) error {
+	type job struct {
+		route   *route
+		records []events.S3EventRecord
+	}
+
+	var jobs []job
+	batches := make(map[int][]events.S3EventRecord)
+	var errs []error
+	for _, record := range event.Records {
+		i := r.match(record)
+		switch {
+		case i < 0:
+			errs = append(errs, fmt.Errorf("%s/%s: %w", record.S3.Bucket.Name, Key(record), ErrNoRoute))
+		case r.routes[i].batch != nil:
+			batches[i] = append(batches[i], record)
+		default:
+			jobs = append(jobs, job{route: &r.routes[i], records: []events.S3EventRecord{record}})
+		}
+	}
+	for i, records := range batches {
+		for _, chunk := range chunkRecords(records, r.batchSize) {
+			jobs = append(jobs, job{route: &r.routes[i], records: chunk})
+		}
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	sem := make(chan struct{}, r.concurrency)
+	for _, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(j job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := r.run(ctx, j.route, j.records); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(j)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (r *Router) match(record events.S3EventRecord) int {
+	for i, route := range r.routes {
+		if route.match.matches(record) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *Router) run(ctx context.Context, route *route, records []events.S3EventRecord) (err error) {
+	startTime := time.Now()
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+		r.logCompletion(records, time.Since(startTime), err)
+	}()
+
+	if route.batch != nil {
+		return route.batch.HandleRecords(ctx, records)
+	}
+	handler := route.handler
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	if err := handler.HandleRecord(ctx, records[0]); err != nil {
+		return fmt.Errorf("%s/%s: %w", records[0].S3.Bucket.Name, Key(records[0]), err)
+	}
+	return nil
+}
+
+func (r *Router) logCompletion(records []events.S3EventRecord, duration time.Duration, err error) {
+	logEntry := fmt.Sprintf(
+		"Records completed: bucket=%s key=%s count=%d duration=%v",
+		records[0].S3.Bucket.Name,
+		Key(records[0]),
+		len(records),
+		duration,
+	)
+
+	if err != nil {
+		logEntry += fmt.Sprintf(" error=%v", err)
+	}
+
+	r.logger.Println(logEntry)
+}
+
+// Key returns the decoded object key. S3 notifications URL-encode keys, with
+// spaces as '+'.
+func Key(record events.S3EventRecord) string {
+	if record.S3.Object.URLDecodedKey != "" {
+		return record.S3.Object.URLDecodedKey
+	}
+	if key, err := url.QueryUnescape(record.S3.Object.Key); err == nil {
+		return key
+	}
+	return record.S3.Object.Key
+}
+
+func chunkRecords(records []events.S3EventRecord, size int) [][]events.S3EventRecord {
+	if size <= 0 || len(records) <= size {
+		return [][]events.S3EventRecord{records}
+	}
+	var chunks [][]events.S3EventRecord
+	for len(records) > size {
+		chunks = append(chunks, records[:size])
+		records = records[size:]
+	}
+	return append(chunks, records)
+}
@@ -0,0 +1,170 @@
+package sesrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var ErrNoRoute = errors.New("sesrouter: no route for message")
+
+// Disposition tells SES how to continue after a synchronous Lambda action.
+type Disposition string
+
+const (
+	Continue    Disposition = "CONTINUE"
+	StopRule    Disposition = "STOP_RULE"
+	StopRuleSet Disposition = "STOP_RULE_SET"
+)
+
+type Result struct {
+	Disposition Disposition `json:"disposition"`
+}
+
+type Handler interface {
+	HandleEmail(context.Context, events.SimpleEmailService) (Disposition, error)
+}
+
+type HandlerFunc func(context.Context, events.SimpleEmailService) (Disposition, error)
+
+func (f HandlerFunc) HandleEmail(ctx context.Context, msg events.SimpleEmailService) (Disposition, error) {
+	return f(ctx, msg)
+}
+
+type MiddlewareFunc func(Handler) Handler
+
+type recipientRoute struct {
+	pattern string
+	handler Handler
+}
+
+type Router struct {
+	recipients     []recipientRoute
+	spamHandler    Handler
+	virusHandler   Handler
+	defaultHandler Handler
+	middleware     []MiddlewareFunc
+	logger         *log.Logger
+}
+
+func NewRouter(logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.New(os.Stdout, "SESROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	return &Router{logger: logger}
+}
+
+// Recipient routes messages with an envelope recipient matching pattern, a
+// case-insensitive path.Match glob such as "*@support.example.com" or
+// "orders+*@example.com". Routes are tried in registration order.
+func (r *Router) Recipient(pattern string, handler Handler) {
+	r.recipients = append(r.recipients, recipientRoute{pattern: strings.ToLower(pattern), handler: handler})
+}
+
+// Spam routes messages SES marked as spam, ahead of recipient routes.
+func (r *Router) Spam(handler Handler) {
+	r.spamHandler = handler
+}
+
+// Virus routes messages SES marked as infected, ahead of every other route.
+func (r *Router) Virus(handler Handler) {
+	r.virusHandler = handler
+}
+
+func (r *Router) Default(handler Handler) {
+	r.defaultHandler = handler
+}
+
+func (r *Router) Use(mw MiddlewareFunc) {
+	r.middleware = append(r.middleware, mw)
+}
+
+func (r *Router) LambdaHandler() func(context.Context, events.SimpleEmailEvent) (*Result, error) {
+	return r.HandleEvent
+}
+
+// HandleEvent returns the most restrictive disposition of the records.
+func (r *Router) HandleEvent(ctx context.Context, event events.SimpleEmailEvent) (*Result, error) {
+	result := &Result{Disposition: Continue}
+	var errs []error
+	for _, record := range event.Records {
+		disposition, err := r.handle(ctx, record.SES)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", record.SES.Mail.MessageID, err))
+			continue
+		}
+		if rank(disposition) > rank(result.Disposition) {
+			result.Disposition = disposition
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+func (r *Router) handle(ctx context.Context, msg events.SimpleEmailService) (disposition Disposition, err error) {
+	startTime := time.Now()
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+		r.logMessageCompletion(msg, time.Since(startTime), err)
+	}()
+
+	handler := r.match(msg)
+	if handler == nil {
+		return "", ErrNoRoute
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	return handler.HandleEmail(ctx, msg)
+}
+
+func (r *Router) match(msg events.SimpleEmailService) Handler {
+	receipt := msg.Receipt
+	if r.virusHandler != nil && strings.EqualFold(receipt.VirusVerdict.Status, "FAIL") {
+		return r.virusHandler
+	}
+	if r.spamHandler != nil && strings.EqualFold(receipt.SpamVerdict.Status, "FAIL") {
+		return r.spamHandler
+	}
+	for _, route := range r.recipients {
+		for _, recipient := range receipt.Recipients {
+			if ok, _ := path.Match(route.pattern, strings.ToLower(recipient)); ok {
+				return route.handler
+			}
+		}
+	}
+	return r.defaultHandler
+}
+
+func rank(d Disposition) int {
+	switch d {
+	case StopRuleSet:
+		return 2
+	case StopRule:
+		return 1
+	}
+	return 0
+}
+
+func (r *Router) logMessageCompletion(msg events.SimpleEmailService, duration time.Duration, err error) {
+	logEntry := fmt.Sprintf(
+		"Message completed: id=%s recipients=%v duration=%v",
+		msg.Mail.MessageID,
+		msg.Receipt.Recipients,
+		duration,
+	)
+
+	if err != nil {
+		logEntry += fmt.Sprintf(" error=%v", err)
+	}
+
+	r.logger.Println(logEntry)
+}
@@ -0,0 +1,50 @@
+package sesrouter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/mail"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Store reads raw MIME messages that an earlier S3 action in the same receipt
+// rule wrote to bucket under prefix.
+type Store struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func NewStore(client *s3.Client, bucket, prefix string) *Store {
+	return &Store{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *Store) Raw(ctx context.Context, msg events.SimpleEmailService) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + msg.Mail.MessageID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+// Message fetches and parses the raw message. Use mime/multipart on its body
+// to walk attachments.
+func (s *Store) Message(ctx context.Context, msg events.SimpleEmailService) (*mail.Message, error) {
+	raw, err := s.Raw(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(raw)
+}
+
+func Parse(raw []byte) (*mail.Message, error) {
+	return mail.ReadMessage(bytes.NewReader(raw))
+}
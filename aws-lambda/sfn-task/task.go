@@ -0,0 +1,88 @@
+package sfntask
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-lambda-go/lambda/messages"
+)
+
+// Error carries a States Language error name, so that Retry and Catch
+// clauses can match on it via ErrorEquals.
+type Error struct {
+	Name  string
+	Cause error
+}
+
+func NewError(name string, cause error) *Error {
+	return &Error{Name: name, Cause: cause}
+}
+
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Name
+	}
+	return e.Cause.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+type errorMapping struct {
+	target error
+	name   string
+}
+
+// Mapper translates returned errors into States Language error names.
+type Mapper struct {
+	mappings []errorMapping
+}
+
+func NewMapper() *Mapper {
+	return &Mapper{}
+}
+
+// Map reports errors matching target (per errors.Is) under name.
+func (m *Mapper) Map(target error, name string) *Mapper {
+	m.mappings = append(m.mappings, errorMapping{target: target, name: name})
+	return m
+}
+
+// Name returns the error name for err: the name of a wrapped *Error, a
+// mapped name, or "" to keep the Go type name Lambda reports by default.
+func (m *Mapper) Name(err error) string {
+	var taskErr *Error
+	if errors.As(err, &taskErr) {
+		return taskErr.Name
+	}
+	if m != nil {
+		for _, mapping := range m.mappings {
+			if errors.Is(err, mapping.target) {
+				return mapping.name
+			}
+		}
+	}
+	return ""
+}
+
+func (m *Mapper) convert(err error) error {
+	if err == nil {
+		return nil
+	}
+	name := m.Name(err)
+	if name == "" {
+		return err
+	}
+	return messages.InvokeResponse_Error{Type: name, Message: err.Error()}
+}
+
+// Handler wraps a typed task function for lambda.Start. The state input is
+// decoded into In, Out becomes the state output, and errors are reported
+// with the names resolved by mapper, which may be nil.
+func Handler[In, Out any](mapper *Mapper, fn func(context.Context, In) (Out, error)) func(context.Context, In) (Out, error) {
+	return func(ctx context.Context, in In) (Out, error) {
+		out, err := fn(ctx, in)
+		return out, mapper.convert(err)
+	}
+}
@@ -0,0 +1,74 @@
+package sfntask
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+)
+
+// Token reports the outcome of a .waitForTaskToken task.
+type Token struct {
+	client *sfn.Client
+	token  string
+	mapper *Mapper
+}
+
+func NewToken(client *sfn.Client, token string, mapper *Mapper) *Token {
+	return &Token{client: client, token: token, mapper: mapper}
+}
+
+func (t *Token) Heartbeat(ctx context.Context) error {
+	_, err := t.client.SendTaskHeartbeat(ctx, &sfn.SendTaskHeartbeatInput{
+		TaskToken: aws.String(t.token),
+	})
+	return err
+}
+
+// KeepAlive sends a heartbeat every interval until the returned stop
+// function is called or ctx is done.
+func (t *Token) KeepAlive(ctx context.Context, interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = t.Heartbeat(ctx)
+			}
+		}
+	}()
+	return cancel
+}
+
+func (t *Token) Succeed(ctx context.Context, output interface{}) error {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+	_, err = t.client.SendTaskSuccess(ctx, &sfn.SendTaskSuccessInput{
+		TaskToken: aws.String(t.token),
+		Output:    aws.String(string(data)),
+	})
+	return err
+}
+
+// Fail reports cause with the error name resolved by the token's mapper,
+// falling back to "Error".
+func (t *Token) Fail(ctx context.Context, cause error) error {
+	name := t.mapper.Name(cause)
+	if name == "" {
+		name = "Error"
+	}
+	_, err := t.client.SendTaskFailure(ctx, &sfn.SendTaskFailureInput{
+		TaskToken: aws.String(t.token),
+		Error:     aws.String(name),
+		Cause:     aws.String(cause.Error()),
+	})
+	return err
+}
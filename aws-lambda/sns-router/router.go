@@ -0,0 +1,160 @@
+package snsrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var ErrNoRoute = errors.New("snsrouter: no route for notification")
+
+type Handler interface {
+	HandleNotification(context.Context, events.SNSEntity) error
+}
+
+type HandlerFunc func(context.Context, events.SNSEntity) error
+
+func (f HandlerFunc) HandleNotification(ctx context.Context, msg events.SNSEntity) error {
+	return f(ctx, msg)
+}
+
+type MiddlewareFunc func(Handler) Handler
+
+type attributeRoute struct {
+	name    string
+	value   string
+	handler Handler
+}
+
+type Router struct {
+	topics         map[string]Handler
+	attributes     []attributeRoute
+	s3Handler      Handler
+	alarmHandler   Handler
+	defaultHandler Handler
+	middleware     []MiddlewareFunc
+	logger         *log.Logger
+}
+
+func NewRouter(logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.New(os.Stdout, "SNSROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	return &Router{
+		topics: make(map[string]Handler),
+		logger: logger,
+	}
+}
+
+func (r *Router) Topic(topicARN string, handler Handler) {
+	r.topics[topicARN] = handler
+}
+
+// Attribute routes notifications whose String message attribute name equals
+// value.
+func (r *Router) Attribute(name, value string, handler Handler) {
+	r.attributes = append(r.attributes, attributeRoute{name: name, value: value, handler: handler})
+}
+
+// S3 routes notifications that wrap S3 event notifications, from any topic.
+func (r *Router) S3(handler S3HandlerFunc) {
+	r.s3Handler = handler
+}
+
+// Alarm routes notifications published by CloudWatch alarms, from any topic.
+func (r *Router) Alarm(handler AlarmHandlerFunc) {
+	r.alarmHandler = handler
+}
+
+func (r *Router) Default(handler Handler) {
+	r.defaultHandler = handler
+}
+
+func (r *Router) Use(mw MiddlewareFunc) {
+	r.middleware = append(r.middleware, mw)
+}
+
+func (r *Router) LambdaHandler() func(context.Context, events.SNSEvent) error {
+	return r.HandleEvent
+}
+
+func (r *Router) HandleEvent(ctx context.Context, event events.SNSEvent) error {
+	var errs []error
+	for _, record := range event.Records {
+		if err := r.handle(ctx, record.SNS); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", record.SNS.MessageID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Router) handle(ctx context.Context, msg events.SNSEntity) (err error) {
+	startTime := time.Now()
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+		r.logNotificationCompletion(msg, time.Since(startTime), err)
+	}()
+
+	handler := r.match(msg)
+	if handler == nil {
+		return ErrNoRoute
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	return handler.HandleNotification(ctx, msg)
+}
+
+func (r *Router) match(msg events.SNSEntity) Handler {
+	for _, route := range r.attributes {
+		if value, ok := StringAttribute(msg, route.name); ok && value == route.value {
+			return route.handler
+		}
+	}
+	if r.s3Handler != nil {
+		if _, ok := S3Event(msg); ok {
+			return r.s3Handler
+		}
+	}
+	if r.alarmHandler != nil {
+		if _, ok := Alarm(msg); ok {
+			return r.alarmHandler
+		}
+	}
+	if h, ok := r.topics[msg.TopicArn]; ok {
+		return h
+	}
+	return r.defaultHandler
+}
+
+// StringAttribute returns the value of a String or Number message attribute.
+func StringAttribute(msg events.SNSEntity, name string) (string, bool) {
+	attr, ok := msg.MessageAttributes[name].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	value, ok := attr["Value"].(string)
+	return value, ok
+}
+
+func (r *Router) logNotificationCompletion(msg events.SNSEntity, duration time.Duration, err error) {
+	logEntry := fmt.Sprintf(
+		"Notification completed: id=%s topic=%s duration=%v",
+		msg.MessageID,
+		msg.TopicArn,
+		duration,
+	)
+
+	if err != nil {
+		logEntry += fmt.Sprintf(" error=%v", err)
+	}
+
+	r.logger.Println(logEntry)
+}
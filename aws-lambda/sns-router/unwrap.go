@@ -0,0 +1,56 @@
+package snsrouter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// S3HandlerFunc handles notifications carrying S3 event notifications. It can
+// be registered on any route; the payload is unwrapped before it is called.
+type S3HandlerFunc func(context.Context, events.S3Event) error
+
+func (f S3HandlerFunc) HandleNotification(ctx context.Context, msg events.SNSEntity) error {
+	event, ok := S3Event(msg)
+	if !ok {
+		return fmt.Errorf("snsrouter: message %s is not an S3 event", msg.MessageID)
+	}
+	return f(ctx, event)
+}
+
+// AlarmHandlerFunc handles notifications published by CloudWatch alarms.
+type AlarmHandlerFunc func(context.Context, events.CloudWatchAlarmSNSPayload) error
+
+func (f AlarmHandlerFunc) HandleNotification(ctx context.Context, msg events.SNSEntity) error {
+	alarm, ok := Alarm(msg)
+	if !ok {
+		return fmt.Errorf("snsrouter: message %s is not a CloudWatch alarm", msg.MessageID)
+	}
+	return f(ctx, alarm)
+}
+
+// S3Event unwraps an S3 event notification delivered through SNS. S3 test
+// events, sent when a notification is configured, are not reported.
+func S3Event(msg events.SNSEntity) (events.S3Event, bool) {
+	var event events.S3Event
+	if err := json.Unmarshal([]byte(msg.Message), &event); err != nil {
+		return events.S3Event{}, false
+	}
+	if len(event.Records) == 0 || event.Records[0].EventSource != "aws:s3" {
+		return events.S3Event{}, false
+	}
+	return event, true
+}
+
+func Alarm(msg events.SNSEntity) (events.CloudWatchAlarmSNSPayload, bool) {
+	var alarm events.CloudWatchAlarmSNSPayload
+	if err := json.Unmarshal([]byte(msg.Message), &alarm); err != nil {
+		return events.CloudWatchAlarmSNSPayload{}, false
+	}
+	if alarm.AlarmName == "" || alarm.NewStateValue == "" {
+		return events.CloudWatchAlarmSNSPayload{}, false
+	}
+	return alarm, true
+}
@@ -0,0 +1,282 @@
+package sqsrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var (
+	ErrNoRoute = errors.New("sqsrouter: no route for message")
+	// ErrSkipped is recorded for FIFO messages that were not processed
+	// because an earlier message in the same group failed.
+	ErrSkipped = errors.New("sqsrouter: skipped after earlier failure in message group")
+)
+
+type Handler interface {
+	HandleMessage(context.Context, events.SQSMessage) error
+}
+
+type HandlerFunc func(context.Context, events.SQSMessage) error
+
+func (f HandlerFunc) HandleMessage(ctx context.Context, msg events.SQSMessage) error {
+	return f(ctx, msg)
+}
+
+type MiddlewareFunc func(Handler) Handler
+
+type RecordError struct {
+	MessageID string
+	Err       error
+}
+
+type BatchError struct {
+	Errors []RecordError
+}
+
+func (e *BatchError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, re := range e.Errors {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", re.MessageID, re.Err))
+	}
+	return fmt.Sprintf("sqsrouter: %d message(s) failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, re := range e.Errors {
+		errs = append(errs, re.Err)
+	}
+	return errs
+}
+
+type attributeRoute struct {
+	name    string
+	value   string
+	handler Handler
+}
+
+type Router struct {
+	queues         map[string]Handler
+	attributes     []attributeRoute
+	discriminator  string
+	bodies         map[string]Handler
+	defaultHandler Handler
+	middleware     []MiddlewareFunc
+	concurrency    int
+	logger         *log.Logger
+}
+
+func NewRouter(logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.New(os.Stdout, "SQSROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	return &Router{
+		queues:      make(map[string]Handler),
+		bodies:      make(map[string]Handler),
+		concurrency: 1,
+		logger:      logger,
+	}
+}
+
+func (r *Router) Queue(queueARN string, handler Handler) {
+	r.queues[queueARN] = handler
+}
+
+// Attribute routes messages whose string message attribute name equals value.
+func (r *Router) Attribute(name, value string, handler Handler) {
+	r.attributes = append(r.attributes, attributeRoute{name: name, value: value, handler: handler})
+}
+
+// SetDiscriminator names the top-level JSON body field used by Body routes.
+func (r *Router) SetDiscriminator(field string) {
+	r.discriminator = field
+}
+
+func (r *Router) Body(value string, handler Handler) {
+	r.bodies[value] = handler
+}
+
+func (r *Router) Default(handler Handler) {
+	r.defaultHandler = handler
+}
+
+func (r *Router) Use(mw MiddlewareFunc) {
+	r.middleware = append(r.middleware, mw)
+}
+
+// SetConcurrency limits how many messages are processed at once. Messages of
+// the same FIFO message group are always processed in order.
+func (r *Router) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	r.concurrency = n
+}
+
+// LambdaHandler reports failed records through the partial batch response
+// contract, so only they are retried. The event source mapping must have
+// ReportBatchItemFailures enabled; without it Lambda treats the returned
+// response as success and deletes the whole batch.
+func (r *Router) LambdaHandler() func(context.Context, events.SQSEvent) (events.SQSEventResponse, error) {
+	return func(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+		return r.HandleBatch(ctx, event), nil
+	}
+}
+
+// HandleBatch processes every record and lists the failed ones as
+// batchItemFailures.
+func (r *Router) HandleBatch(ctx context.Context, event events.SQSEvent) events.SQSEventResponse {
+	var resp events.SQSEventResponse
+	for _, failure := range r.process(ctx, event) {
+		resp.BatchItemFailures = append(resp.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: failure.MessageID})
+	}
+	return resp
+}
+
+// HandleEvent processes every record and returns a *BatchError listing the
+// ones that failed.
+func (r *Router) HandleEvent(ctx context.Context, event events.SQSEvent) error {
+	failures := r.process(ctx, event)
+	if len(failures) == 0 {
+		return nil
+	}
+	return &BatchError{Errors: failures}
+}
+
+func (r *Router) process(ctx context.Context, event events.SQSEvent) []RecordError {
+	var (
+		mu       sync.Mutex
+		failures []RecordError
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, r.concurrency)
+
+	for _, group := range groupMessages(event.Records) {
+		wg.Add(1)
+		go func(msgs []events.SQSMessage) {
+			defer wg.Done()
+			failed := false
+			for _, msg := range msgs {
+				var err error
+				if failed {
+					err = ErrSkipped
+				} else {
+					sem <- struct{}{}
+					err = r.handle(ctx, msg)
+					<-sem
+				}
+				if err != nil {
+					failed = isFIFO(msg)
+					mu.Lock()
+					failures = append(failures, RecordError{MessageID: msg.MessageId, Err: err})
+					mu.Unlock()
+				}
+			}
+		}(group)
+	}
+	wg.Wait()
+
+	// Report failures in batch order regardless of completion order.
+	order := make(map[string]int, len(event.Records))
+	for i, msg := range event.Records {
+		order[msg.MessageId] = i
+	}
+	sort.SliceStable(failures, func(i, j int) bool {
+		return order[failures[i].MessageID] < order[failures[j].MessageID]
+	})
+	return failures
+}
+
+func (r *Router) handle(ctx context.Context, msg events.SQSMessage) (err error) {
+	startTime := time.Now()
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+		r.logMessageCompletion(msg, time.Since(startTime), err)
+	}()
+
+	handler := r.match(msg)
+	if handler == nil {
+		return ErrNoRoute
+	}
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	return handler.HandleMessage(ctx, msg)
+}
+
+func (r *Router) match(msg events.SQSMessage) Handler {
+	for _, route := range r.attributes {
+		attr, ok := msg.MessageAttributes[route.name]
+		if ok && attr.StringValue != nil && *attr.StringValue == route.value {
+			return route.handler
+		}
+	}
+	if r.discriminator != "" && len(r.bodies) > 0 {
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(msg.Body), &body); err == nil {
+			var value string
+			if err := json.Unmarshal(body[r.discriminator], &value); err == nil {
+				if h, ok := r.bodies[value]; ok {
+					return h
+				}
+			}
+		}
+	}
+	if h, ok := r.queues[msg.EventSourceARN]; ok {
+		return h
+	}
+	return r.defaultHandler
+}
+
+func (r *Router) logMessageCompletion(msg events.SQSMessage, duration time.Duration, err error) {
+	logEntry := fmt.Sprintf(
+		"Message completed: id=%s queue=%s duration=%v",
+		msg.MessageId,
+		msg.EventSourceARN,
+		duration,
+	)
+
+	if err != nil {
+		logEntry += fmt.Sprintf(" error=%v", err)
+	}
+
+	r.logger.Println(logEntry)
+}
+
+// groupMessages splits records into independently processable groups: one
+// group per FIFO message group, and one group per message otherwise.
+func groupMessages(records []events.SQSMessage) [][]events.SQSMessage {
+	var groups [][]events.SQSMessage
+	index := make(map[string]int)
+	for _, msg := range records {
+		groupID := msg.Attributes["MessageGroupId"]
+		if !isFIFO(msg) || groupID == "" {
+			groups = append(groups, []events.SQSMessage{msg})
+			continue
+		}
+		key := msg.EventSourceARN + "/" + groupID
+		if i, ok := index[key]; ok {
+			groups[i] = append(groups[i], msg)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, []events.SQSMessage{msg})
+	}
+	return groups
+}
+
+func isFIFO(msg events.SQSMessage) bool {
+	return strings.HasSuffix(msg.EventSourceARN, ".fifo")
+}
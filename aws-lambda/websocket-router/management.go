@@ -0,0 +1,65 @@
+package wsrouter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewaymanagementapi"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewaymanagementapi/types"
+)
+
+// ErrGone is returned when posting to a connection that has already been
+// closed by the client.
+var ErrGone = errors.New("wsrouter: connection is gone")
+
+type Client struct {
+	api *apigatewaymanagementapi.Client
+}
+
+// NewClient creates a management API client for the given callback
+// endpoint, e.g. the value of Endpoint for an incoming request.
+func NewClient(cfg aws.Config, endpoint string) *Client {
+	api := apigatewaymanagementapi.NewFromConfig(cfg, func(o *apigatewaymanagementapi.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	})
+	return &Client{api: api}
+}
+
+func Endpoint(rc events.APIGatewayWebsocketProxyRequestContext) string {
+	return fmt.Sprintf("https://%s/%s", rc.DomainName, rc.Stage)
+}
+
+func (c *Client) Post(ctx context.Context, connectionID string, data []byte) error {
+	_, err := c.api.PostToConnection(ctx, &apigatewaymanagementapi.PostToConnectionInput{
+		ConnectionId: aws.String(connectionID),
+		Data:         data,
+	})
+	return mapGone(err)
+}
+
+func (c *Client) PostJSON(ctx context.Context, connectionID string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Post(ctx, connectionID, data)
+}
+
+func (c *Client) Disconnect(ctx context.Context, connectionID string) error {
+	_, err := c.api.DeleteConnection(ctx, &apigatewaymanagementapi.DeleteConnectionInput{
+		ConnectionId: aws.String(connectionID),
+	})
+	return mapGone(err)
+}
+
+func mapGone(err error) error {
+	var gone *types.GoneException
+	if errors.As(err, &gone) {
+		return ErrGone
+	}
+	return err
+}
@@ -0,0 +1,157 @@
+package wsrouter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	RouteConnect    = "$connect"
+	RouteDisconnect = "$disconnect"
+	RouteDefault    = "$default"
+)
+
+type Handler interface {
+	ServeWebSocket(context.Context, events.APIGatewayWebsocketProxyRequest) Response
+}
+
+type HandlerFunc func(context.Context, events.APIGatewayWebsocketProxyRequest) Response
+
+func (f HandlerFunc) ServeWebSocket(ctx context.Context, req events.APIGatewayWebsocketProxyRequest) Response {
+	return f(ctx, req)
+}
+
+type Response struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+}
+
+type MiddlewareFunc func(Handler) Handler
+
+type contextKey int
+
+const requestContextKey contextKey = iota
+
+type Router struct {
+	routes       map[string]Handler
+	middleware   []MiddlewareFunc
+	panicHandler func(context.Context, events.APIGatewayWebsocketProxyRequest) Response
+	logger       *log.Logger
+}
+
+func NewRouter(logger *log.Logger) *Router {
+	if logger == nil {
+		logger = log.New(os.Stdout, "WSROUTER: ", log.Ldate|log.Ltime|log.Lshortfile)
+	}
+	return &Router{
+		routes:       make(map[string]Handler),
+		panicHandler: defaultPanicHandler,
+		logger:       logger,
+	}
+}
+
+func (r *Router) Route(routeKey string, handler Handler) {
+	r.routes[routeKey] = handler
+}
+
+func (r *Router) Connect(handler Handler) {
+	r.Route(RouteConnect, handler)
+}
+
+func (r *Router) Disconnect(handler Handler) {
+	r.Route(RouteDisconnect, handler)
+}
+
+func (r *Router) Default(handler Handler) {
+	r.Route(RouteDefault, handler)
+}
+
+func (r *Router) Use(mw MiddlewareFunc) {
+	r.middleware = append(r.middleware, mw)
+}
+
+func (r *Router) SetPanicHandler(handler func(context.Context, events.APIGatewayWebsocketProxyRequest) Response) {
+	r.panicHandler = handler
+}
+
+// HandleRequest dispatches on the route key selected by API Gateway. Unknown
+// route keys fall back to the $default route; $connect and $disconnect
+// succeed when no handler is registered for them.
+func (r *Router) HandleRequest(ctx context.Context, req events.APIGatewayWebsocketProxyRequest) (resp Response) {
+	startTime := time.Now()
+	routeKey := req.RequestContext.RouteKey
+	var err error
+
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+			resp = r.panicHandler(ctx, req)
+		}
+		r.logRequestCompletion(req, resp, time.Since(startTime), err)
+	}()
+
+	ctx = context.WithValue(ctx, requestContextKey, req.RequestContext)
+
+	handler, ok := r.routes[routeKey]
+	if !ok {
+		handler, ok = r.routes[RouteDefault]
+	}
+	if !ok {
+		if routeKey == RouteConnect || routeKey == RouteDisconnect {
+			return Response{StatusCode: http.StatusOK}
+		}
+		return Response{StatusCode: http.StatusNotFound, Body: "route not found"}
+	}
+
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		handler = r.middleware[i](handler)
+	}
+	return handler.ServeWebSocket(ctx, req)
+}
+
+func (r *Router) LambdaHandler() func(context.Context, events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return func(ctx context.Context, req events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+		resp := r.HandleRequest(ctx, req)
+		return events.APIGatewayProxyResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    resp.Headers,
+			Body:       resp.Body,
+		}, nil
+	}
+}
+
+func ConnectionID(ctx context.Context) string {
+	return RequestContext(ctx).ConnectionID
+}
+
+func RequestContext(ctx context.Context) events.APIGatewayWebsocketProxyRequestContext {
+	rc, _ := ctx.Value(requestContextKey).(events.APIGatewayWebsocketProxyRequestContext)
+	return rc
+}
+
+func (r *Router) logRequestCompletion(req events.APIGatewayWebsocketProxyRequest, resp Response, duration time.Duration, err error) {
+	logEntry := fmt.Sprintf(
+		"Request completed: route=%s connection=%s status=%d duration=%v",
+		req.RequestContext.RouteKey,
+		req.RequestContext.ConnectionID,
+		resp.StatusCode,
+		duration,
+	)
+
+	if err != nil {
+		logEntry += fmt.Sprintf(" error=%v", err)
+	}
+
+	r.logger.Println(logEntry)
+}
+
+func defaultPanicHandler(ctx context.Context, req events.APIGatewayWebsocketProxyRequest) Response {
+	return Response{StatusCode: http.StatusInternalServerError, Body: "Internal Server Error"}
+}